@@ -2,73 +2,377 @@
 package aautil
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/split-cube-studios/ardent/behavior"
+	"github.com/split-cube-studios/ardent/internal/common"
+	"github.com/split-cube-studios/ardent/prefab"
 	"gopkg.in/yaml.v2"
 )
 
-func parseConfigFiles(dir string) (confs []config, err error) {
-	err = filepath.Walk(
+// Options configures how asset files are built.
+type Options struct {
+	// OutDir is the directory asset files are written to, laid
+	// out like their configs. Asset files are written next
+	// to their configs if it is empty.
+	OutDir string
+	// DryRun builds and validates assets without writing them.
+	DryRun bool
+}
+
+// ConfigError is an error in a config file.
+type ConfigError struct {
+	Path string
+	// Line is the line of the error, or zero if unknown.
+	Line int
+	Err  error
+}
+
+// Error implements error.
+func (c *ConfigError) Error() string {
+	if c.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", c.Path, c.Line, c.Err)
+	}
+
+	return fmt.Sprintf("%s: %v", c.Path, c.Err)
+}
+
+// Unwrap returns the underlying error.
+func (c *ConfigError) Unwrap() error {
+	return c.Err
+}
+
+// Errors is a list of failures, such as those
+// of every config in a directory.
+type Errors []error
+
+// Error implements error.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// add appends an error, or the errors of Errors.
+func (e *Errors) add(err error) {
+	if errs, ok := err.(Errors); ok {
+		*e = append(*e, errs...)
+		return
+	}
+
+	*e = append(*e, err)
+}
+
+// err returns the Errors, or nil if there are none.
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// skipConfig reports whether configs of a type do not produce asset files.
+// Tile definitions, prefabs and behavior trees are loaded at runtime,
+// so they are only checked.
+func skipConfig(typ string) bool {
+	switch typ {
+	case configTypeTiles, prefab.ConfigType, behavior.ConfigType:
+		return true
+	}
+
+	return false
+}
+
+// parseConfigFiles parses the asset configs in a directory, and checks
+// those loaded at runtime. Configs which fail to parse are left out,
+// and their errors returned along with those of runtime configs.
+func parseConfigFiles(dir string) (confs []config, errs Errors) {
+	err := filepath.Walk(
 		dir,
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
 
-			if filepath.Ext(path) != ".yml" {
+			if info.IsDir() || filepath.Ext(path) != ".yml" {
 				return nil
 			}
 
-			f, err := os.Open(path)
+			conf, err := parseConfigFile(path)
 			if err != nil {
-				return err
+				errs.add(err)
+				return nil
 			}
-			defer f.Close()
 
-			var conf config
-			if err := yaml.NewDecoder(f).Decode(&conf); err != nil {
-				return err
+			if skipConfig(conf.Type) {
+				errs = append(errs, conf.checkRuntime()...)
+				return nil
 			}
 
-			// NOTE: sounds handle filepaths within the configuration
-			conf.filepath = strings.Replace(path, ".yml", ".png", 1)
 			confs = append(confs, conf)
 
 			return nil
 		},
 	)
+	if err != nil {
+		errs = append(errs, err)
+	}
 
-	return //nolint:nakedret
+	return confs, errs
 }
 
-// CreateAssets takes a directory path that should contain a yml config file and uses it to produce a .asset file.
-func CreateAssets(dir string) {
-	confs, err := parseConfigFiles(dir)
+// parseConfigFile parses a config file. Asset configs are decoded
+// strictly, so that unknown fields and mistyped values are errors.
+func parseConfigFile(path string) (config, error) {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatal(err)
+		return config{}, err
 	}
 
-	for _, conf := range confs {
-		asset, err := conf.toAsset()
+	var header struct {
+		Type string `yaml:"type"`
+	}
+
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return config{}, yamlErrors(path, err)
+	}
+
+	conf := config{
+		path: path,
+		data: data,
+		Type: header.Type,
+		// NOTE: sound paths are set within the configuration
+		filepath: strings.TrimSuffix(path, ".yml") + ".png",
+	}
+
+	if skipConfig(conf.Type) {
+		return conf, nil
+	}
+
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return config{}, yamlErrors(path, err)
+	}
+
+	return conf, nil
+}
+
+// yamlErrors converts a yaml error into the ConfigErrors of each line.
+func yamlErrors(path string, err error) Errors {
+	var msgs []string
+	if terr, ok := err.(*yaml.TypeError); ok {
+		msgs = terr.Errors
+	} else {
+		msgs = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	var errs Errors
+	for _, msg := range msgs {
+		var line int
+		if _, err := fmt.Sscanf(msg, "line %d:", &line); err == nil {
+			msg = strings.TrimSpace(msg[strings.Index(msg, ":")+1:])
+		}
+
+		// field x not found in type aautil.config
+		if strings.HasPrefix(msg, "field ") {
+			if i := strings.Index(msg, " not found in type "); i >= 0 {
+				msg = "unknown field " + msg[len("field "):i]
+			}
+		}
+
+		errs = append(errs, &ConfigError{
+			Path: path,
+			Line: line,
+			Err:  fmt.Errorf("%s", msg),
+		})
+	}
+
+	return errs
+}
+
+// build builds and checks the asset of a config.
+func (c config) build() (*common.Asset, Errors) {
+	asset, err := c.toAsset()
+	if err != nil {
+		return nil, Errors{c.configError(err)}
+	}
+
+	var errs Errors
+	for _, err := range c.check(asset) {
+		errs = append(errs, c.configError(err))
+	}
+
+	return asset, errs
+}
+
+// checkRuntime checks a config loaded at runtime as it is loaded.
+// Conditions, actions, layers and images are defined by a game,
+// so behavior trees and prefabs may reference any of them.
+func (c config) checkRuntime() Errors {
+	switch c.Type {
+	case configTypeTiles:
+		if _, err := parseTileDefs(c.data); err != nil {
+			return c.errors(err)
+		}
+
+	case prefab.ConfigType:
+		f := prefab.NewFactory(nil)
+		if err := f.Parse(c.data); err != nil {
+			return c.errors(err)
+		}
+
+		var conf struct {
+			Prefabs map[string]interface{} `yaml:"prefabs"`
+		}
+
+		if err := yaml.Unmarshal(c.data, &conf); err != nil {
+			return c.errors(err)
+		}
+
+		names := make([]string, 0, len(conf.Prefabs))
+		for name := range conf.Prefabs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var errs Errors
+		for _, name := range names {
+			if err := f.Validate(name); err != nil {
+				errs = append(errs, c.configError(fieldErr(err, "prefabs", name)))
+			}
+		}
+
+		return errs
+
+	case behavior.ConfigType:
+		trees, err := behavior.Parse(c.data)
 		if err != nil {
-			log.Fatal(err)
+			return c.errors(err)
+		}
+
+		lib := behavior.NewLibrary()
+		lib.Validate = true
+
+		names := make([]string, 0, len(trees))
+		for name := range trees {
+			names = append(names, name)
 		}
+		sort.Strings(names)
+
+		var errs Errors
+		for _, name := range names {
+			if _, err := lib.Build(trees[name]); err != nil {
+				errs = append(errs, c.configError(fieldErr(err, "trees", name)))
+			}
+		}
+
+		return errs
+	}
+
+	return nil
+}
+
+// errors returns the ConfigErrors of an error loading a config.
+func (c config) errors(err error) Errors {
+	if _, ok := err.(*yaml.TypeError); ok || strings.HasPrefix(err.Error(), "yaml: ") {
+		return yamlErrors(c.path, err)
+	}
+
+	return Errors{c.configError(err)}
+}
 
-		d, err := asset.Marshal()
+// configError returns a ConfigError of a config, at
+// the line of the field the error is attributed to.
+func (c config) configError(err error) *ConfigError {
+	return &ConfigError{
+		Path: c.path,
+		Line: errorLine(c.data, err),
+		Err:  err,
+	}
+}
+
+// assetPath returns the path of the asset file of a config in dir.
+func (o Options) assetPath(dir string, c config) (string, error) {
+	path := strings.TrimSuffix(c.path, ".yml") + ".asset"
+	if o.OutDir == "" {
+		return path, nil
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(o.OutDir, rel), nil
+}
+
+// Build builds an asset file from each asset config in a directory,
+// and returns the paths of the asset files. Every config is built,
+// and the Errors of those which fail are returned.
+func Build(dir string, opts Options) ([]string, error) {
+	confs, errs := parseConfigFiles(dir)
+
+	var paths []string
+	for _, conf := range confs {
+		asset, confErrs := conf.build()
+		if len(confErrs) > 0 {
+			errs = append(errs, confErrs...)
+			continue
+		}
+
+		path, err := opts.assetPath(dir, conf)
 		if err != nil {
-			log.Fatal(err)
+			errs = append(errs, err)
+			continue
 		}
 
-		if err := ioutil.WriteFile(
-			strings.Replace(conf.filepath, ".png", ".asset", 1),
-			d,
-			0o600,
-		); err != nil {
-			log.Fatal(err)
+		if !opts.DryRun {
+			if err := writeAsset(path, asset); err != nil {
+				errs = append(errs, err)
+				continue
+			}
 		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, errs.err()
+}
+
+// Validate builds and checks the asset configs in a directory,
+// and checks its tile, prefab and behavior configs,
+// without writing asset files.
+func Validate(dir string) error {
+	_, err := Build(dir, Options{DryRun: true})
+	return err
+}
+
+// writeAsset marshals an asset to a file.
+func writeAsset(path string, asset *common.Asset) error {
+	d, err := asset.Marshal()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, d, 0o600)
+}
+
+// CreateAssets takes a directory path that should contain a yml config file and uses it to produce a .asset file.
+func CreateAssets(dir string) {
+	if _, err := Build(dir, Options{}); err != nil {
+		log.Fatal(err)
 	}
 }
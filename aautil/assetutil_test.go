@@ -0,0 +1,305 @@
+package aautil
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/split-cube-studios/ardent/internal/common"
+)
+
+func writeTestConfig(t *testing.T, path, conf string) {
+	if err := ioutil.WriteFile(path, []byte(conf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir, err := os.MkdirTemp("", "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the directory name must not affect the PNG path
+	sub := filepath.Join(dir, "sprites.yml.d")
+	if err := os.Mkdir(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestImage(t, filepath.Join(sub, "atlas.png"), 64, 32, 0, color.NRGBA{G: 255, A: 255})
+	writeTestConfig(t, filepath.Join(sub, "atlas.yml"), `type: atlas
+atlas:
+  left: {x: 0, y: 0, w: 32, h: 32}
+  right: {x: 32, y: 0, w: 32, h: 32}
+`)
+
+	writeTestImage(t, filepath.Join(dir, "anim.png"), 64, 32, 0, color.NRGBA{R: 255, A: 255})
+	writeTestConfig(t, filepath.Join(dir, "anim.yml"), `type: animation
+framewidth: 32
+frameheight: 32
+animations:
+  walk: {fps: 10, start: 0, end: 1}
+`)
+
+	out := filepath.Join(dir, "out")
+	paths, err := Build(dir, Options{OutDir: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("Expected %v got %v", 2, paths)
+	}
+
+	for _, path := range []string{"anim.asset", "sprites.yml.d/atlas.asset"} {
+		if _, err := os.Stat(filepath.Join(out, path)); err != nil {
+			t.Fatalf("Expected %v to be written got %v", path, err)
+		}
+	}
+
+	unpacked, err := Unpack(filepath.Join(out, "sprites.yml.d", "atlas.asset"), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(unpacked) != 2 || unpacked[0] != filepath.Join(out, "atlas", "left.png") {
+		t.Fatalf("Expected %v got %v", filepath.Join(out, "atlas", "left.png"), unpacked)
+	}
+
+	// region names must not escape the unpacked directory
+	asset := common.NewAsset()
+	asset.Type = common.AssetTypeAtlas
+	asset.Img.Image = image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	asset.AtlasMap["../../escape"] = common.AtlasRegion{W: 8, H: 8}
+
+	if err := writeAsset(filepath.Join(dir, "escape.asset"), asset); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Unpack(filepath.Join(dir, "escape.asset"), out); err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Fatalf("Expected region to be outside got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escape.png")); !os.IsNotExist(err) {
+		t.Fatalf("Expected %v got %v", os.ErrNotExist, err)
+	}
+}
+
+func TestSoundPaths(t *testing.T) {
+	dir, err := os.MkdirTemp("", "sound")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := ioutil.ReadFile(filepath.Join("..", "examples", "sound", "sample.ogg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sound paths are relative to the config, not the working directory
+	sub := filepath.Join(dir, "sfx")
+	if err := os.Mkdir(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(sub, "sample.ogg"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestConfig(t, filepath.Join(sub, "sample.yml"), `type: sound
+sounds:
+  sfx: ['sample.ogg']
+`)
+
+	if err := Validate(dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestImage(t, filepath.Join(dir, "atlas.png"), 64, 32, 0, color.NRGBA{G: 255, A: 255})
+	writeTestConfig(t, filepath.Join(dir, "atlas.yml"), `type: atlas
+atlas:
+  left: {x: 0, y: 0, w: 32, h: 32}
+  right: {x: 48, y: 0, w: 32, h: 32}
+`)
+
+	writeTestImage(t, filepath.Join(dir, "anim.png"), 64, 32, 0, color.NRGBA{R: 255, A: 255})
+	writeTestConfig(t, filepath.Join(dir, "anim.yml"), `type: animation
+framewidth: 32
+frameheight: 32
+animations:
+  walk:
+    fps: 10
+    start: 0
+    end: 2
+    speed: 2
+`)
+
+	err = Validate(dir)
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected %v failures got %v", 2, err)
+	}
+
+	var confErr *ConfigError
+	if !errors.As(errs[0], &confErr) || confErr.Line != 9 || !strings.Contains(confErr.Error(), "unknown field speed") {
+		t.Fatalf("Expected unknown field on line %v got %v", 9, errs[0])
+	}
+
+	if !errors.As(errs[1], &confErr) || confErr.Line != 4 || !strings.Contains(confErr.Error(), "region right") {
+		t.Fatalf("Expected region right to not fit on line %v got %v", 4, errs[1])
+	}
+
+	writeTestConfig(t, filepath.Join(dir, "anim.yml"), `type: animation
+framewidth: 32
+frameheight: 32
+animations:
+  walk: {fps: 10, start: 0, end: 2}
+`)
+
+	if err := Validate(dir); err == nil || !strings.Contains(err.Error(), "anim.yml:5: frames 0-2") {
+		t.Fatalf("Expected frames to exceed the image on line %v got %v", 5, err)
+	}
+}
+
+func TestValidateRuntimeConfigs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestConfig(t, filepath.Join(dir, "tiles.yml"), `type: tiles
+tiles:
+  0: {name: floor}
+  1:
+    name: wall
+    shape: round
+`)
+
+	writeTestConfig(t, filepath.Join(dir, "prefabs.yml"), `type: prefab
+prefabs:
+  enemy:
+    body: {shape: {circle: 4}, type: heavy}
+  boss:
+    extends: dragon
+`)
+
+	writeTestConfig(t, filepath.Join(dir, "trees.yml"), `type: behavior
+trees:
+  guard:
+    sequence:
+      - condition: seesPlayer
+      - action: moveTo
+        args: {speed: fast}
+  idle: {action: wander}
+`)
+
+	err = Validate(dir)
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("Expected %v failures got %v", 4, err)
+	}
+
+	// configs are walked in lexical order
+	for i, expected := range []string{
+		"prefabs.yml:5: unknown prefab: dragon",
+		"prefabs.yml:3: invalid body type: heavy",
+		"tiles.yml:6: invalid shape for tile 1: round",
+		"trees.yml:3: invalid moveTo arg: speed",
+	} {
+		if !strings.HasSuffix(errs[i].Error(), string(filepath.Separator)+expected) {
+			t.Fatalf("Expected %v got %v", expected, errs[i])
+		}
+	}
+}
+
+func TestKeyLine(t *testing.T) {
+	data := []byte(`type: animation
+# walk is a comment
+animations:
+  walk: {fps: 10}
+  die:
+    fps: 10
+    end: 2
+actions:
+  - name: walk
+    frames: 4
+  -
+    name: die
+    frames: 2
+`)
+
+	for _, c := range []struct {
+		path []string
+		line int
+	}{
+		{[]string{"animations", "walk", "fps"}, 4},
+		{[]string{"animations", "die", "end"}, 7},
+		{[]string{"animations", "run"}, 3},
+		{[]string{"actions", "[0]", "name"}, 9},
+		{[]string{"actions", "[0]", "frames"}, 10},
+		{[]string{"actions", "[1]", "frames"}, 13},
+		{[]string{"sounds"}, 0},
+	} {
+		if line := keyLine(data, c.path); line != c.line {
+			t.Fatalf("Expected %v got %v", c.line, line)
+		}
+	}
+}
+
+func TestAnimationFrames(t *testing.T) {
+	dir, err := os.MkdirTemp("", "frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// looping states cycle from start to end-1
+	writeTestImage(t, filepath.Join(dir, "anim.png"), 64, 32, 0, color.NRGBA{R: 255, A: 255})
+	writeTestConfig(t, filepath.Join(dir, "anim.yml"), `type: animation
+framewidth: 32
+frameheight: 32
+animations:
+  walk: {fps: 10, loop: true, start: 0, end: 2}
+  die: {fps: 10, start: 0, end: 1}
+`)
+
+	conf, err := parseConfigFile(filepath.Join(dir, "anim.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset, errs := conf.build()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	for state, frames := range map[string]int{"walk": 2, "die": 2} {
+		seq := asset.AnimationMap[state].Sequence()
+		if seq.Frames != frames {
+			t.Fatalf("Expected %v got %v", frames, seq.Frames)
+		}
+
+		// frames last 60/fps+1 ticks
+		if len(seq.Durations) != frames || seq.Durations[0] != 7 {
+			t.Fatalf("Expected %v got %v", 7, seq.Durations)
+		}
+	}
+}
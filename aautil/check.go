@@ -0,0 +1,113 @@
+package aautil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/jfreymuth/oggvorbis"
+	"github.com/split-cube-studios/ardent/internal/common"
+)
+
+// check returns the failures of an asset built from the config.
+// Atlas regions and animation frames must lie inside their
+// images, and sounds must decode as Ogg Vorbis.
+func (c config) check(asset *common.Asset) []error {
+	switch asset.Type {
+	case common.AssetTypeAtlas:
+		return checkRegions(asset)
+	case common.AssetTypeAnimation:
+		return checkFrames(asset)
+	case common.AssetTypeSound:
+		return c.checkSounds(asset)
+	}
+
+	return nil
+}
+
+// checkRegions checks that each atlas region lies inside its page.
+func checkRegions(asset *common.Asset) []error {
+	pages := append([]common.Image{asset.Img}, asset.Pages...)
+
+	var errs []error
+	for _, name := range regionNames(asset.AtlasMap) {
+		region := asset.AtlasMap[name]
+
+		if int(region.Page) >= len(pages) || pages[region.Page].Image == nil {
+			errs = append(errs, fieldErr(fmt.Errorf("region %s is on missing page %d", name, region.Page), "atlas", name))
+			continue
+		}
+
+		bounds := pages[region.Page].Bounds()
+		r := image.Rect(
+			int(region.X),
+			int(region.Y),
+			int(region.X)+int(region.W),
+			int(region.Y)+int(region.H),
+		).Add(bounds.Min)
+
+		if r.Empty() || !r.In(bounds) {
+			errs = append(errs, fieldErr(fmt.Errorf(
+				"region %s (%d,%d %dx%d) does not fit in the %dx%d image",
+				name, region.X, region.Y, region.W, region.H, bounds.Dx(), bounds.Dy(),
+			), "atlas", name))
+		}
+	}
+
+	return errs
+}
+
+// checkFrames checks that the frames of each animation state lie
+// inside the image, laid out in rows of frames of the frame size.
+func checkFrames(asset *common.Asset) []error {
+	if asset.Img.Image == nil {
+		return []error{fmt.Errorf("missing animation image")}
+	}
+
+	bounds := asset.Img.Bounds()
+	w, h := int(asset.AnimWidth), int(asset.AnimHeight)
+
+	if w <= 0 || h <= 0 || w > bounds.Dx() || h > bounds.Dy() {
+		return []error{fieldErr(fmt.Errorf(
+			"frame size %dx%d does not fit in the %dx%d image",
+			w, h, bounds.Dx(), bounds.Dy(),
+		), "framewidth")}
+	}
+
+	frames := (bounds.Dx() / w) * (bounds.Dy() / h)
+
+	states := make([]string, 0, len(asset.AnimationMap))
+	for state := range asset.AnimationMap {
+		states = append(states, state)
+	}
+
+	sort.Strings(states)
+
+	var errs []error
+	for _, state := range states {
+		anim := asset.AnimationMap[state]
+		if int(anim.Start)+anim.Frames() > frames {
+			errs = append(errs, fieldErr(fmt.Errorf(
+				"frames %d-%d of animation %s exceed the %d frames of the image",
+				anim.Start, anim.End, state, frames,
+			), "animations", state))
+		}
+	}
+
+	return errs
+}
+
+// checkSounds checks that each sound decodes as Ogg Vorbis.
+func (c config) checkSounds(asset *common.Asset) []error {
+	paths := c.Sounds[asset.Snd.Group]
+
+	var errs []error
+	for i, data := range asset.Snd.Options {
+		if _, _, err := oggvorbis.ReadAll(bytes.NewReader(data)); err != nil {
+			errs = append(errs, fieldErr(fmt.Errorf("sound %s is not Ogg Vorbis: %w", paths[i], err), "sounds", asset.Snd.Group))
+		}
+	}
+
+	return errs
+}
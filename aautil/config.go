@@ -6,7 +6,9 @@ import (
 	"image/png"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
+	"github.com/split-cube-studios/ardent/engine"
 	"github.com/split-cube-studios/ardent/internal/common"
 )
 
@@ -18,29 +20,131 @@ func (i InvalidTypeError) Error() string {
 }
 
 type config struct {
+	// path is the path of the config file, data its
+	// contents, and filepath the path of its PNG.
+	path     string
+	data     []byte
 	filepath string
 
-	Version string `yml:"version"`
-	Type    string `yml:"type"`
+	Version string `yaml:"version"`
+	Type    string `yaml:"type"`
 
 	Atlas map[string]struct {
-		X int `yml:"x"`
-		Y int `yml:"y"`
-		W int `yml:"w"`
-		H int `yml:"h"`
-	} `yml:"atlas,omitempty"`
-
-	FrameWidth  int `yml:"framewidth,omitempty"`
-	FrameHeight int `yml:"frameheight,omitempty"`
-
-	Animations map[string]struct {
-		Fps   int  `yml:"fps"`
-		Loop  bool `yml:"loop,omitempty"`
-		Start int  `yml:"start"`
-		End   int  `yml:"end"`
-	} `yml:"animations,omitempty"`
-
-	Sounds map[string][]string `yml:"sounds,omitempty"`
+		X int `yaml:"x"`
+		Y int `yaml:"y"`
+		W int `yaml:"w"`
+		H int `yaml:"h"`
+	} `yaml:"atlas,omitempty"`
+
+	// Source is an optional directory of PNGs, relative to
+	// the config, used in place of the PNG of the config.
+	// The PNGs of an atlas are packed into regions named
+	// by their paths, and those of an animation are laid
+	// out in a grid of frames in file name order.
+	Source string     `yaml:"source,omitempty"`
+	Pack   packConfig `yaml:"pack,omitempty"`
+
+	FrameWidth  int `yaml:"framewidth,omitempty"`
+	FrameHeight int `yaml:"frameheight,omitempty"`
+
+	Animations map[string]animationConfig `yaml:"animations,omitempty"`
+
+	// Directions and Actions generate a state for each action
+	// facing each direction, such as walk_ne. The frames of
+	// each action are laid out consecutively, with a block of
+	// frames for each direction in order.
+	Directions []string `yaml:"directions,omitempty"`
+	Actions    []struct {
+		Name      string           `yaml:"name"`
+		Fps       int              `yaml:"fps"`
+		Loop      bool             `yaml:"loop,omitempty"`
+		Durations []int            `yaml:"durations,omitempty"`
+		Playback  string           `yaml:"playback,omitempty"`
+		Events    map[int][]string `yaml:"events,omitempty"`
+		// Frames is the number of frames per direction.
+		Frames int `yaml:"frames"`
+		// Stride is the number of frames between the start of
+		// each direction, if greater than Frames.
+		Stride int `yaml:"stride,omitempty"`
+		// Start is the first frame of the action, if not
+		// immediately after the previous action.
+		Start *int `yaml:"start,omitempty"`
+	} `yaml:"actions,omitempty"`
+
+	// Sounds maps a sound group to the paths of its
+	// Ogg Vorbis files, relative to the config.
+	Sounds map[string][]string `yaml:"sounds,omitempty"`
+}
+
+// animationConfig is the config of an animation state.
+// Looping states cycle from Start to End-1, and
+// other states play from Start to End.
+type animationConfig struct {
+	Fps   int  `yaml:"fps"`
+	Loop  bool `yaml:"loop,omitempty"`
+	Start int  `yaml:"start"`
+	End   int  `yaml:"end"`
+
+	// Durations optionally sets the duration of each frame in
+	// ticks, from Start to End. Zero durations use the Fps.
+	Durations []int `yaml:"durations,omitempty"`
+	// Playback is forward, reverse or pingpong.
+	Playback string `yaml:"playback,omitempty"`
+	// Events maps frames, relative to Start, to the
+	// names of the events triggered when they are shown.
+	Events map[int][]string `yaml:"events,omitempty"`
+}
+
+func (a animationConfig) toAnimation(state string) (common.Animation, error) {
+	anim := common.Animation{
+		Fps:   uint16(a.Fps),
+		Loop:  a.Loop,
+		Start: uint16(a.Start),
+		End:   uint16(a.End),
+	}
+
+	if a.End < a.Start {
+		return anim, fieldErr(fmt.Errorf("invalid frame range for animation %s: %d-%d", state, a.Start, a.End), "end")
+	}
+
+	frames := anim.Frames()
+
+	switch a.Playback {
+	case "", "forward":
+		anim.Playback = byte(engine.PlaybackForward)
+	case "reverse":
+		anim.Playback = byte(engine.PlaybackReverse)
+	case "pingpong":
+		anim.Playback = byte(engine.PlaybackPingPong)
+	default:
+		return anim, fieldErr(fmt.Errorf("invalid playback for animation %s: %s", state, a.Playback), "playback")
+	}
+
+	if len(a.Durations) > frames {
+		return anim, fieldErr(fmt.Errorf("too many durations for animation %s: %d", state, len(a.Durations)), "durations")
+	}
+
+	for _, d := range a.Durations {
+		if d < 0 {
+			return anim, fieldErr(fmt.Errorf("invalid duration for animation %s: %d", state, d), "durations")
+		}
+
+		anim.Durations = append(anim.Durations, uint16(d))
+	}
+
+	if len(a.Events) > 0 {
+		anim.Events = make(map[uint16][]string, len(a.Events))
+	}
+
+	for frame, events := range a.Events {
+		if frame < 0 || frame >= frames {
+			return anim, fieldErr(fmt.Errorf("invalid event frame for animation %s: %d", state, frame), "events")
+		}
+
+		anim.Events[uint16(frame)] = events
+	}
+
+	return anim, nil
 }
 
 func (c config) toAsset() (*common.Asset, error) {
@@ -55,6 +159,20 @@ func (c config) toAsset() (*common.Asset, error) {
 
 	case "atlas":
 		asset.Type = common.AssetTypeAtlas
+
+		if c.Source != "" {
+			images, err := loadSourceImages(c.sourceDir())
+			if err != nil {
+				return nil, fieldErr(err, "source")
+			}
+
+			if err := c.Pack.pack(images, asset); err != nil {
+				return nil, fieldErr(err, "pack")
+			}
+
+			break
+		}
+
 		for k, v := range c.Atlas {
 			asset.AtlasMap[k] = common.AtlasRegion{
 				X: uint16(v.X),
@@ -67,30 +185,50 @@ func (c config) toAsset() (*common.Asset, error) {
 
 	case "animation":
 		asset.Type = common.AssetTypeAnimation
+
+		if c.Source != "" {
+			images, err := loadSourceImages(c.sourceDir())
+			if err != nil {
+				return nil, fieldErr(err, "source")
+			}
+
+			if asset.Img.Image, c.FrameWidth, c.FrameHeight, err = c.Pack.frameSheet(images); err != nil {
+				return nil, fieldErr(err, "pack")
+			}
+		}
+
 		asset.AnimWidth = uint16(c.FrameWidth)
 		asset.AnimHeight = uint16(c.FrameHeight)
 
 		for k, v := range c.Animations {
-			asset.AnimationMap[k] = common.Animation{
-				Fps:   uint16(v.Fps),
-				Loop:  v.Loop,
-				Start: uint16(v.Start),
-				End:   uint16(v.End),
+			if asset.AnimationMap[k], err = v.toAnimation(k); err != nil {
+				return nil, fieldErr(err, "animations", k)
 			}
 		}
-		asset.Img.Image, err = c.parseImage()
+
+		if err := c.directionalAnimations(asset.AnimationMap); err != nil {
+			return nil, err
+		}
+
+		if c.Source == "" {
+			asset.Img.Image, err = c.parseImage()
+		}
 
 	case "sound":
 		asset.Type = common.AssetTypeSound
 
+		if len(c.Sounds) != 1 {
+			return nil, fieldErr(fmt.Errorf("sounds require exactly one group"), "sounds")
+		}
+
 		for group, sounds := range c.Sounds {
 
 			asset.Snd.Group = group
 
 			for _, sound := range sounds {
-				data, err := ioutil.ReadFile(sound)
+				data, err := ioutil.ReadFile(c.soundPath(sound))
 				if err != nil {
-					return nil, err
+					return nil, fieldErr(err, "sounds", group)
 				}
 
 				asset.Snd.Options = append(asset.Snd.Options, data)
@@ -98,12 +236,87 @@ func (c config) toAsset() (*common.Asset, error) {
 		}
 
 	default:
-		return nil, InvalidTypeError(c.Type)
+		return nil, fieldErr(InvalidTypeError(c.Type), "type")
 	}
 
 	return asset, err
 }
 
+// directionalAnimations adds the states generated
+// from the config's directions and actions.
+func (c config) directionalAnimations(anims map[string]common.Animation) error {
+	if len(c.Actions) > 0 && len(c.Directions) == 0 {
+		return fieldErr(fmt.Errorf("actions require directions"), "actions")
+	}
+
+	var frame int
+	for i, action := range c.Actions {
+		if action.Frames < 1 {
+			return fieldErr(fmt.Errorf("invalid frame count for action %s: %d", action.Name, action.Frames), "actions", itemKey(i), "frames")
+		}
+
+		if action.Start != nil {
+			frame = *action.Start
+		}
+
+		stride := action.Stride
+		if stride < action.Frames {
+			stride = action.Frames
+		}
+
+		for _, dir := range c.Directions {
+			state := engine.DirectionalState(action.Name, dir)
+			if _, ok := anims[state]; ok {
+				return fieldErr(fmt.Errorf("duplicate animation state: %s", state), "actions", itemKey(i), "name")
+			}
+
+			anim, err := animationConfig{
+				Fps:       action.Fps,
+				Loop:      action.Loop,
+				Start:     frame,
+				End:       actionEnd(frame, action.Frames, action.Loop),
+				Durations: action.Durations,
+				Playback:  action.Playback,
+				Events:    action.Events,
+			}.toAnimation(state)
+			if err != nil {
+				return fieldErr(err, "actions", itemKey(i))
+			}
+
+			anims[state] = anim
+
+			frame += stride
+		}
+	}
+
+	return nil
+}
+
+// actionEnd returns the End of an action's frames, which
+// is exclusive for looping animations.
+func actionEnd(start, frames int, loop bool) int {
+	if loop && frames > 1 {
+		return start + frames
+	}
+
+	return start + frames - 1
+}
+
+// soundPath returns the path of a sound file, which
+// is relative to the config if it is not absolute.
+func (c config) soundPath(sound string) string {
+	if filepath.IsAbs(sound) {
+		return sound
+	}
+
+	return filepath.Join(filepath.Dir(c.path), sound)
+}
+
+// sourceDir returns the path of the source directory.
+func (c config) sourceDir() string {
+	return filepath.Join(filepath.Dir(c.filepath), c.Source)
+}
+
 func (c config) parseImage() (image.Image, error) {
 
 	f, err := os.Open(c.filepath)
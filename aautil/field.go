@@ -0,0 +1,187 @@
+package aautil
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// fieldError is an error in the value of a config field,
+// identified by its path of keys. Sequence items are
+// identified by their index, as in [0].
+type fieldError struct {
+	path []string
+	err  error
+}
+
+// Error implements error.
+func (f *fieldError) Error() string {
+	return f.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (f *fieldError) Unwrap() error {
+	return f.err
+}
+
+// fieldErr attributes an error to a config field. The path is
+// prepended to that of an error already attributed to a field.
+func fieldErr(err error, path ...string) error {
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		return &fieldError{
+			path: append(append([]string(nil), path...), fe.path...),
+			err:  fe.err,
+		}
+	}
+
+	return &fieldError{path: path, err: err}
+}
+
+// itemKey returns the path key of a sequence item.
+func itemKey(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// errorLine returns the line of the field an error is
+// attributed to in YAML data, or zero if it is unknown.
+func errorLine(data []byte, err error) int {
+	var fe *fieldError
+	if !errors.As(err, &fe) {
+		return 0
+	}
+
+	return keyLine(data, fe.path)
+}
+
+// keyLine returns the line of a field in YAML data, or that of
+// its innermost parent found, or zero if none is found. Only
+// block style fields are located, so fields within a flow
+// style value resolve to the line of the value.
+func keyLine(data []byte, path []string) int {
+	lines := strings.Split(string(data), "\n")
+
+	line, start, parent := 0, 0, -1
+	item := false
+
+	for _, key := range path {
+		i := findField(lines, start, parent, key, item)
+		if i < 0 {
+			break
+		}
+
+		line = i + 1
+		parent = len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+
+		// the first field of an item is on the line of the item
+		item = strings.HasPrefix(key, "[")
+		if item {
+			start = i
+			continue
+		}
+
+		start = i + 1
+
+		// the rest of the path is within a flow style value
+		_, value := splitField(lines[i])
+		if value != "" && !strings.HasPrefix(value, "#") {
+			break
+		}
+	}
+
+	return line
+}
+
+// findField returns the index of the line of a key or sequence item
+// within the block of lines from start indented deeper than parent,
+// or -1. If item is set, the first line may be that of the item
+// containing the key.
+func findField(lines []string, start, parent int, key string, item bool) int {
+	index, isItem := -1, strings.HasPrefix(key, "[")
+	if isItem {
+		index, _ = strconv.Atoi(strings.Trim(key, "[]"))
+	}
+
+	items, itemIndent, keyIndent := 0, -1, -1
+
+	for i := start; i < len(lines); i++ {
+		content := strings.TrimLeft(lines[i], " ")
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		indent := len(lines[i]) - len(content)
+		if indent <= parent && !(item && i == start) {
+			break
+		}
+
+		if isItem {
+			if !strings.HasPrefix(content, "- ") && content != "-" {
+				continue
+			}
+
+			if itemIndent < 0 {
+				itemIndent = indent
+			}
+
+			if indent == itemIndent {
+				if items == index {
+					return i
+				}
+
+				items++
+			}
+
+			continue
+		}
+
+		rest := trimItems(content)
+		if rest == "" {
+			continue
+		}
+
+		// only keys of the first level within the block are fields
+		column := indent + len(content) - len(rest)
+		if keyIndent < 0 {
+			keyIndent = column
+		}
+
+		if column != keyIndent {
+			continue
+		}
+
+		if name, _ := splitField(lines[i]); name == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// splitField returns the key and the trimmed value of a
+// block style field line, ignoring sequence item markers.
+func splitField(line string) (string, string) {
+	content := trimItems(strings.TrimSpace(line))
+
+	i := strings.Index(content, ":")
+	if i < 0 {
+		return "", ""
+	}
+
+	name := strings.Trim(content[:i], `"'`)
+
+	return name, strings.TrimSpace(content[i+1:])
+}
+
+// trimItems trims the sequence item markers of a line.
+func trimItems(content string) string {
+	for strings.HasPrefix(content, "- ") {
+		content = strings.TrimLeft(content[2:], " ")
+	}
+
+	if content == "-" {
+		return ""
+	}
+
+	return content
+}
@@ -0,0 +1,223 @@
+package aautil
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/split-cube-studios/ardent/engine"
+	"github.com/split-cube-studios/ardent/internal/common"
+)
+
+var assetTypeNames = map[common.AssetType]string{
+	common.AssetTypeImage:     "image",
+	common.AssetTypeAtlas:     "atlas",
+	common.AssetTypeAnimation: "animation",
+	common.AssetTypeSound:     "sound",
+}
+
+var playbackNames = map[engine.Playback]string{
+	engine.PlaybackForward:  "forward",
+	engine.PlaybackReverse:  "reverse",
+	engine.PlaybackPingPong: "pingpong",
+}
+
+// readAsset reads an asset file.
+func readAsset(path string) (*common.Asset, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := common.NewAsset()
+	if err := asset.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return asset, nil
+}
+
+// Inspect writes a description of the contents of an asset file.
+func Inspect(path string, w io.Writer) error {
+	asset, err := readAsset(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", path, assetTypeNames[asset.Type])
+
+	if asset.Img.Image != nil {
+		size := asset.Img.Bounds().Size()
+		fmt.Fprintf(w, "  image: %dx%d\n", size.X, size.Y)
+	}
+
+	for i, page := range asset.Pages {
+		size := page.Bounds().Size()
+		fmt.Fprintf(w, "  page %d: %dx%d\n", i+1, size.X, size.Y)
+	}
+
+	switch asset.Type {
+	case common.AssetTypeAtlas:
+		for _, name := range regionNames(asset.AtlasMap) {
+			r := asset.AtlasMap[name]
+			fmt.Fprintf(w, "  region %s: page %d, %d,%d %dx%d", name, r.Page, r.X, r.Y, r.W, r.H)
+
+			if r.SourceW > 0 {
+				fmt.Fprintf(w, ", trimmed from %dx%d at %d,%d", r.SourceW, r.SourceH, r.TrimX, r.TrimY)
+			}
+
+			fmt.Fprintln(w)
+		}
+
+	case common.AssetTypeAnimation:
+		fmt.Fprintf(w, "  frame size: %dx%d\n", asset.AnimWidth, asset.AnimHeight)
+
+		states := make([]string, 0, len(asset.AnimationMap))
+		for state := range asset.AnimationMap {
+			states = append(states, state)
+		}
+
+		sort.Strings(states)
+
+		for _, state := range states {
+			a := asset.AnimationMap[state]
+			fmt.Fprintf(
+				w, "  state %s: frames %d-%d, %d fps, %s, loop %t",
+				state, a.Start, int(a.Start)+a.Frames()-1, a.Fps, playbackNames[engine.Playback(a.Playback)], a.Loop,
+			)
+
+			if len(a.Events) > 0 {
+				fmt.Fprintf(w, ", %d event frames", len(a.Events))
+			}
+
+			fmt.Fprintln(w)
+		}
+
+	case common.AssetTypeSound:
+		fmt.Fprintf(w, "  group: %s\n", asset.Snd.Group)
+
+		for i, data := range asset.Snd.Options {
+			fmt.Fprintf(w, "  option %d: %d bytes\n", i, len(data))
+		}
+	}
+
+	return nil
+}
+
+// Unpack extracts the images and sounds of an asset file into a
+// directory, and returns the paths of the extracted files. Files
+// are named after the asset file. Each atlas region is extracted
+// to its own untrimmed PNG, in a directory named after the asset.
+// Regions whose names would be extracted outside of it are errors.
+func Unpack(path, outDir string) ([]string, error) {
+	asset, err := readAsset(path)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(outDir, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+
+	var paths []string
+
+	switch asset.Type {
+	case common.AssetTypeImage, common.AssetTypeAnimation:
+		if err := writePNG(base+".png", asset.Img.Image); err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, base+".png")
+
+	case common.AssetTypeAtlas:
+		pages := append([]common.Image{asset.Img}, asset.Pages...)
+
+		for _, name := range regionNames(asset.AtlasMap) {
+			r := asset.AtlasMap[name]
+			if int(r.Page) >= len(pages) || pages[r.Page].Image == nil {
+				return paths, fmt.Errorf("region %s is on missing page %d", name, r.Page)
+			}
+
+			rel := filepath.Clean(filepath.FromSlash(name) + ".png")
+			if filepath.IsAbs(rel) || filepath.VolumeName(rel) != "" ||
+				rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return paths, fmt.Errorf("region %s is outside of %s", name, base)
+			}
+
+			out := filepath.Join(base, rel)
+			if err := writePNG(out, regionImage(pages[r.Page].Image, r)); err != nil {
+				return paths, err
+			}
+
+			paths = append(paths, out)
+		}
+
+	case common.AssetTypeSound:
+		if err := os.MkdirAll(outDir, 0o700); err != nil {
+			return paths, err
+		}
+
+		for i, data := range asset.Snd.Options {
+			out := fmt.Sprintf("%s_%d.ogg", base, i)
+			if err := ioutil.WriteFile(out, data, 0o600); err != nil {
+				return paths, err
+			}
+
+			paths = append(paths, out)
+		}
+	}
+
+	return paths, nil
+}
+
+// regionImage returns the untrimmed image of an atlas region.
+func regionImage(page image.Image, r common.AtlasRegion) image.Image {
+	w, h := int(r.W), int(r.H)
+	if r.SourceW > 0 && r.SourceH > 0 {
+		w, h = int(r.SourceW), int(r.SourceH)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	dst := image.Rect(0, 0, int(r.W), int(r.H)).Add(image.Pt(int(r.TrimX), int(r.TrimY)))
+	src := image.Pt(int(r.X), int(r.Y)).Add(page.Bounds().Min)
+
+	draw.Draw(img, dst, page, src, draw.Src)
+
+	return img
+}
+
+// writePNG encodes an image to a file, creating its directory.
+func writePNG(path string, img image.Image) error {
+	if img == nil {
+		return fmt.Errorf("%s: missing image", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
+
+// regionNames returns the sorted names of atlas regions.
+func regionNames(regions map[string]common.AtlasRegion) []string {
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
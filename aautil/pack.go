@@ -0,0 +1,404 @@
+package aautil
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/split-cube-studios/ardent/internal/common"
+)
+
+// defaultMaxSize is the default max width and height of a packed page.
+const defaultMaxSize = 2048
+
+// packConfig configures how the PNGs in the source
+// directory of an atlas config are packed.
+type packConfig struct {
+	// Padding is the space left between regions.
+	Padding int `yaml:"padding,omitempty"`
+	// Extrude is the number of times the edge pixels of each
+	// region are repeated around it, to prevent bleeding.
+	Extrude int `yaml:"extrude,omitempty"`
+	// Trim removes transparent borders from regions. Images
+	// of trimmed regions are drawn as if untrimmed.
+	Trim bool `yaml:"trim,omitempty"`
+	// MaxSize is the max width and height of each page.
+	// Regions which do not fit are packed into more pages.
+	MaxSize int `yaml:"maxsize,omitempty"`
+}
+
+// sourceImage is a PNG in a source directory.
+type sourceImage struct {
+	name string
+	img  image.Image
+	// bounds are the bounds of the packed part of the image
+	bounds image.Rectangle
+}
+
+// loadSourceImages loads the PNGs in a directory, in file name order.
+// Images are named by their path relative to the directory, without
+// the extension and with forward slashes, such as "enemies/goblin".
+func loadSourceImages(dir string) ([]sourceImage, error) {
+	var images []sourceImage
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".png" {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		img, err := png.Decode(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		images = append(images, sourceImage{
+			name:   filepath.ToSlash(strings.TrimSuffix(rel, ".png")),
+			img:    img,
+			bounds: img.Bounds(),
+		})
+
+		return nil
+	})
+
+	return images, err
+}
+
+// pack packs images into the pages of an atlas asset.
+func (p packConfig) pack(images []sourceImage, asset *common.Asset) error {
+	if len(images) == 0 {
+		return fmt.Errorf("no images to pack")
+	}
+
+	maxSize := p.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+
+	if p.Trim {
+		for i := range images {
+			images[i].bounds = opaqueBounds(images[i].img)
+		}
+	}
+
+	// pack the largest images first
+	order := make([]int, len(images))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := images[order[i]].bounds.Size(), images[order[j]].bounds.Size()
+		if a.Y != b.Y {
+			return a.Y > b.Y
+		}
+
+		return a.X > b.X
+	})
+
+	var pages []*maxRects
+	placed := make([]image.Rectangle, len(images))
+	onPage := make([]int, len(images))
+
+	// each region is surrounded by its extrusion,
+	// with padding on its right and bottom
+	border := p.Extrude*2 + p.Padding
+
+	for _, i := range order {
+		size := images[i].bounds.Size()
+		w, h := size.X+border, size.Y+border
+
+		page := -1
+		for j, mr := range pages {
+			if r, ok := mr.insert(w, h); ok {
+				page, placed[i] = j, r
+				break
+			}
+		}
+
+		if page < 0 {
+			mr := newMaxRects(maxSize+p.Padding, maxSize+p.Padding)
+
+			r, ok := mr.insert(w, h)
+			if !ok {
+				return fmt.Errorf("image %s exceeds the max texture size of %d", images[i].name, maxSize)
+			}
+
+			pages = append(pages, mr)
+			page, placed[i] = len(pages)-1, r
+		}
+
+		onPage[i] = page
+	}
+
+	canvases := make([]*image.NRGBA, len(pages))
+	for i, mr := range pages {
+		bounds := mr.usedBounds()
+		canvases[i] = image.NewNRGBA(image.Rect(
+			0, 0,
+			bounds.Max.X-p.Padding,
+			bounds.Max.Y-p.Padding,
+		))
+	}
+
+	for i, src := range images {
+		r := placed[i]
+		inner := image.Rect(
+			r.Min.X+p.Extrude,
+			r.Min.Y+p.Extrude,
+			r.Min.X+p.Extrude+src.bounds.Dx(),
+			r.Min.Y+p.Extrude+src.bounds.Dy(),
+		)
+
+		drawExtruded(canvases[onPage[i]], inner, src.img, src.bounds, p.Extrude)
+
+		region := common.AtlasRegion{
+			X:    uint16(inner.Min.X),
+			Y:    uint16(inner.Min.Y),
+			W:    uint16(inner.Dx()),
+			H:    uint16(inner.Dy()),
+			Page: uint16(onPage[i]),
+		}
+
+		if full := src.img.Bounds(); src.bounds != full {
+			region.TrimX = uint16(src.bounds.Min.X - full.Min.X)
+			region.TrimY = uint16(src.bounds.Min.Y - full.Min.Y)
+			region.SourceW = uint16(full.Dx())
+			region.SourceH = uint16(full.Dy())
+		}
+
+		if _, ok := asset.AtlasMap[src.name]; ok {
+			return fmt.Errorf("duplicate atlas region: %s", src.name)
+		}
+
+		asset.AtlasMap[src.name] = region
+	}
+
+	for i, canvas := range canvases {
+		if i == 0 {
+			asset.Img.Image = canvas
+			continue
+		}
+
+		asset.Pages = append(asset.Pages, common.Image{Image: canvas})
+	}
+
+	return nil
+}
+
+// frameSheet lays out images of the same size in a grid of
+// animation frames, with as many columns as fit in MaxSize,
+// and returns the sheet and the frame size.
+func (p packConfig) frameSheet(images []sourceImage) (image.Image, int, int, error) {
+	if len(images) == 0 {
+		return nil, 0, 0, fmt.Errorf("no animation frames")
+	}
+
+	maxSize := p.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+
+	size := images[0].img.Bounds().Size()
+	for _, src := range images {
+		if src.img.Bounds().Size() != size {
+			return nil, 0, 0, fmt.Errorf("frame %s is not %dx%d", src.name, size.X, size.Y)
+		}
+	}
+
+	cols := maxSize / size.X
+	if cols > len(images) {
+		cols = len(images)
+	}
+
+	rows := (len(images) + cols - 1) / cols
+	if cols < 1 || rows*size.Y > maxSize {
+		return nil, 0, 0, fmt.Errorf("frames exceed the max texture size of %d", maxSize)
+	}
+
+	sheet := image.NewNRGBA(image.Rect(0, 0, cols*size.X, rows*size.Y))
+	for i, src := range images {
+		dst := image.Rectangle{Max: size}.Add(image.Pt(i%cols*size.X, i/cols*size.Y))
+		draw.Draw(sheet, dst, src.img, src.img.Bounds().Min, draw.Src)
+	}
+
+	return sheet, size.X, size.Y, nil
+}
+
+// opaqueBounds returns the bounds of the non-transparent pixels of an
+// image. A fully transparent image keeps a single pixel at its origin.
+func opaqueBounds(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	opaque := image.Rectangle{}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+
+			opaque = opaque.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+
+	if opaque.Empty() {
+		return image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+	}
+
+	return opaque
+}
+
+// drawExtruded draws the src bounds of an image into the dst
+// rectangle of a canvas, repeating its edge pixels extrude times.
+func drawExtruded(canvas draw.Image, dst image.Rectangle, img image.Image, src image.Rectangle, extrude int) {
+	draw.Draw(canvas, dst, img, src.Min, draw.Src)
+
+	if extrude <= 0 {
+		return
+	}
+
+	clamp := func(v, min, max int) int {
+		if v < min {
+			return min
+		}
+
+		if v >= max {
+			return max - 1
+		}
+
+		return v
+	}
+
+	outer := dst.Inset(-extrude)
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if (image.Point{X: x, Y: y}).In(dst) {
+				continue
+			}
+
+			canvas.Set(x, y, img.At(
+				clamp(x-dst.Min.X+src.Min.X, src.Min.X, src.Max.X),
+				clamp(y-dst.Min.Y+src.Min.Y, src.Min.Y, src.Max.Y),
+			))
+		}
+	}
+}
+
+// maxRects is a MaxRects bin packer, which places each
+// rectangle in the free space that best fits its short side.
+type maxRects struct {
+	free []image.Rectangle
+	used []image.Rectangle
+}
+
+func newMaxRects(w, h int) *maxRects {
+	return &maxRects{
+		free: []image.Rectangle{image.Rect(0, 0, w, h)},
+	}
+}
+
+// insert places a rectangle, and returns false if it does not fit.
+func (m *maxRects) insert(w, h int) (image.Rectangle, bool) {
+	best, bestShort, bestLong := -1, 0, 0
+
+	for i, f := range m.free {
+		if w > f.Dx() || h > f.Dy() {
+			continue
+		}
+
+		dw, dh := f.Dx()-w, f.Dy()-h
+		short, long := dw, dh
+		if short > long {
+			short, long = long, short
+		}
+
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+
+	if best < 0 {
+		return image.Rectangle{}, false
+	}
+
+	r := image.Rect(0, 0, w, h).Add(m.free[best].Min)
+	m.split(r)
+	m.used = append(m.used, r)
+
+	return r, true
+}
+
+// split replaces the free rectangles overlapping a placed
+// rectangle with the free space around it, and then removes
+// free rectangles contained by others.
+func (m *maxRects) split(r image.Rectangle) {
+	var free []image.Rectangle
+
+	for _, f := range m.free {
+		if !f.Overlaps(r) {
+			free = append(free, f)
+			continue
+		}
+
+		if r.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, r.Min.X, f.Max.Y))
+		}
+
+		if r.Max.X < f.Max.X {
+			free = append(free, image.Rect(r.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+
+		if r.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, r.Min.Y))
+		}
+
+		if r.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, r.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+
+	m.free = m.free[:0]
+	for i, f := range free {
+		contained := false
+		for j, g := range free {
+			// of equal rectangles, keep the first
+			if i != j && f.In(g) && (f != g || j < i) {
+				contained = true
+				break
+			}
+		}
+
+		if !contained {
+			m.free = append(m.free, f)
+		}
+	}
+}
+
+// usedBounds returns the bounds of the placed rectangles.
+func (m *maxRects) usedBounds() image.Rectangle {
+	var bounds image.Rectangle
+	for _, r := range m.used {
+		bounds = bounds.Union(r)
+	}
+
+	return bounds
+}
@@ -0,0 +1,108 @@
+package aautil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/split-cube-studios/ardent/internal/common"
+)
+
+// writeTestImage writes a PNG with an opaque w by h
+// rectangle, inset by a transparent border.
+func writeTestImage(t *testing.T, path string, w, h, border int, c color.NRGBA) {
+	img := image.NewNRGBA(image.Rect(0, 0, w+border*2, h+border*2))
+	for y := border; y < border+h; y++ {
+		for x := border; x < border+w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPack(t *testing.T) {
+	dir, err := os.MkdirTemp("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(filepath.Join(dir, "items"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 6; i++ {
+		c := color.NRGBA{R: uint8(i * 40), G: 255, A: 255}
+		writeTestImage(t, filepath.Join(dir, "items", fmt.Sprintf("item%d.png", i)), 20+i*2, 30-i, 2, c)
+	}
+
+	images, err := loadSourceImages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset := common.NewAsset()
+	conf := packConfig{Padding: 1, Extrude: 1, Trim: true, MaxSize: 64}
+
+	if err := conf.pack(images, asset); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(asset.AtlasMap) != 6 || len(asset.Pages) == 0 {
+		t.Fatalf("Expected %v regions on several pages got %v on %v", 6, len(asset.AtlasMap), len(asset.Pages)+1)
+	}
+
+	pages := append([]common.Image{asset.Img}, asset.Pages...)
+
+	var placed []common.AtlasRegion
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("items/item%d", i)
+
+		r, ok := asset.AtlasMap[name]
+		if !ok {
+			t.Fatalf("Expected region %v", name)
+		}
+
+		if int(r.W) != 20+i*2 || int(r.H) != 30-i || r.TrimX != 2 || r.TrimY != 2 || r.SourceW != r.W+4 {
+			t.Fatalf("Unexpected trimmed region %+v", r)
+		}
+
+		page := pages[r.Page]
+		rect := image.Rect(int(r.X), int(r.Y), int(r.X+r.W), int(r.Y+r.H))
+		if !rect.Inset(-1).In(page.Bounds()) {
+			t.Fatalf("Region %v is outside of page %v", rect, page.Bounds())
+		}
+
+		// the extruded edge matches the region
+		_, g, _, a := page.At(rect.Min.X-1, rect.Min.Y-1).RGBA()
+		if g>>8 != 255 || a>>8 != 255 {
+			t.Fatalf("Expected extruded pixel got %v", page.At(rect.Min.X-1, rect.Min.Y-1))
+		}
+
+		for _, o := range placed {
+			other := image.Rect(int(o.X), int(o.Y), int(o.X+o.W), int(o.Y+o.H))
+			if o.Page == r.Page && rect.Inset(-1).Overlaps(other.Inset(-1)) {
+				t.Fatalf("Region %v overlaps %v", rect, other)
+			}
+		}
+
+		placed = append(placed, r)
+	}
+
+	if err := (packConfig{MaxSize: 16}).pack(images, common.NewAsset()); err == nil {
+		t.Fatal("Expected max texture size error")
+	}
+}
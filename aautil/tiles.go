@@ -0,0 +1,92 @@
+package aautil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/split-cube-studios/ardent/engine"
+	"gopkg.in/yaml.v2"
+)
+
+// configTypeTiles is the config type for tile definitions.
+// Tile definition configs do not produce asset files.
+const configTypeTiles = "tiles"
+
+var tileShapes = map[string]engine.TileShape{
+	"":     engine.TileShapeNone,
+	"none": engine.TileShapeNone,
+	"full": engine.TileShapeFull,
+}
+
+var tileFlags = map[string]engine.TileFlag{
+	"opaque":   engine.TileFlagOpaque,
+	"slippery": engine.TileFlagSlippery,
+	"hazard":   engine.TileFlagHazard,
+}
+
+type tileConfig struct {
+	Version string `yaml:"version"`
+	Type    string `yaml:"type"`
+
+	Tiles map[int]struct {
+		Name  string                 `yaml:"name"`
+		Shape string                 `yaml:"shape,omitempty"`
+		Flags []string               `yaml:"flags,omitempty"`
+		Cost  float64                `yaml:"cost,omitempty"`
+		Props map[string]interface{} `yaml:"props,omitempty"`
+	} `yaml:"tiles"`
+}
+
+// LoadTileDefs parses a tile definition config file
+// and returns the resulting *engine.TileRegistry.
+func LoadTileDefs(path string) (*engine.TileRegistry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTileDefs(data)
+}
+
+// parseTileDefs parses tile definition config data.
+func parseTileDefs(data []byte) (*engine.TileRegistry, error) {
+	var conf tileConfig
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Type != configTypeTiles {
+		return nil, fieldErr(InvalidTypeError(conf.Type), "type")
+	}
+
+	registry := engine.NewTileRegistry()
+
+	for tile, v := range conf.Tiles {
+		key := strconv.Itoa(tile)
+
+		shape, ok := tileShapes[v.Shape]
+		if !ok {
+			return nil, fieldErr(fmt.Errorf("invalid shape for tile %d: %s", tile, v.Shape), "tiles", key, "shape")
+		}
+
+		var flags engine.TileFlag
+		for _, name := range v.Flags {
+			flag, ok := tileFlags[name]
+			if !ok {
+				return nil, fieldErr(fmt.Errorf("invalid flag for tile %d: %s", tile, name), "tiles", key, "flags")
+			}
+			flags |= flag
+		}
+
+		registry.Register(tile, engine.TileDef{
+			Name:  v.Name,
+			Shape: shape,
+			Flags: flags,
+			Cost:  v.Cost,
+			Props: v.Props,
+		})
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,224 @@
+package behavior
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+type testAgent struct {
+	engine.CoreEntity
+}
+
+func (a *testAgent) Class() string {
+	return "agent"
+}
+
+// counter returns an action that runs for a number of
+// ticks before returning a status, and counts its calls.
+func counter(ticks int, status Status, calls *int) Action {
+	n := 0
+	return func(*Tree) Status {
+		*calls++
+		n++
+		if n < ticks {
+			return Running
+		}
+		n = 0
+		return status
+	}
+}
+
+func TestComposites(t *testing.T) {
+	var a, b, c int
+
+	tree := NewTree(NewSequence(
+		counter(2, Success, &a),
+		NewSelector(
+			counter(1, Failure, &b),
+			counter(1, Success, &c),
+		),
+	), nil)
+
+	statuses := []Status{tree.Tick(), tree.Tick()}
+	if statuses[0] != Running || statuses[1] != Success {
+		t.Fatalf("Expected [Running Success] got %v", statuses)
+	}
+
+	if a != 2 || b != 1 || c != 1 {
+		t.Fatalf("Expected 2 1 1 got %d %d %d", a, b, c)
+	}
+
+	a, b = 0, 0
+	tree = NewTree(NewParallel(1,
+		counter(3, Success, &a),
+		counter(2, Failure, &b),
+	), nil)
+
+	for i := 0; i < 2; i++ {
+		if s := tree.Tick(); s != Running {
+			t.Fatalf("Expected %v got %v", Running, s)
+		}
+	}
+
+	if s := tree.Tick(); s != Success {
+		t.Fatalf("Expected %v got %v", Success, s)
+	}
+
+	if a != 3 || b != 2 {
+		t.Fatalf("Expected 3 2 got %d %d", a, b)
+	}
+}
+
+func TestDecorators(t *testing.T) {
+	var calls int
+
+	tree := NewTree(NewInverter(NewRepeat(3, counter(1, Success, &calls))), nil)
+	for i := 0; i < 2; i++ {
+		tree.Tick()
+	}
+
+	if s := tree.Tick(); s != Failure || calls != 3 {
+		t.Fatalf("Expected %v after 3 calls got %v after %d", Failure, s, calls)
+	}
+
+	calls = 0
+	tree = NewTree(NewCooldown(2, counter(1, Success, &calls)), nil)
+
+	expected := []Status{Success, Failure, Success}
+	for i, e := range expected {
+		if s := tree.Tick(); s != e {
+			t.Fatalf("Expected %v at tick %d got %v", e, i, s)
+		}
+	}
+
+	calls = 0
+	tree = NewTree(NewTimeout(2, counter(5, Success, &calls)), nil)
+
+	expected = []Status{Running, Running, Failure, Running}
+	for i, e := range expected {
+		if s := tree.Tick(); s != e {
+			t.Fatalf("Expected %v at tick %d got %v", e, i, s)
+		}
+	}
+}
+
+const testConfig = `
+version: 1.0
+type: behavior
+trees:
+  chase:
+    selector:
+      - sequence:
+          - condition: hasTarget
+          - action: moveTo
+            args: {target: target, speed: 2, arrive: 1}
+      - action: idle
+`
+
+func TestLibrary(t *testing.T) {
+	trees, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idle := 0
+	lib := NewLibrary()
+	lib.Condition("hasTarget", func(t *Tree) bool {
+		_, ok := t.Blackboard["target"]
+		return ok
+	})
+	lib.Action("idle", func(*Tree) Status {
+		idle++
+		return Success
+	})
+
+	root, err := lib.Build(trees["chase"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent := new(testAgent)
+	tree := NewTree(root, agent)
+
+	if s := tree.Tick(); s != Success || idle != 1 {
+		t.Fatalf("Expected idle got %v", s)
+	}
+
+	tree.Blackboard["target"] = engine.Vec2{X: 10}
+
+	ticks := 0
+	for tree.Tick() == Running {
+		ticks++
+	}
+
+	if ticks != 5 || agent.Position().Distance(engine.Vec2{X: 10}) > 1 {
+		t.Fatalf("Expected arrival after 5 ticks got %v after %d", agent.Position(), ticks)
+	}
+
+	_, err = lib.Build(NodeDef{Action: "attack"})
+	if !errors.Is(err, UnknownLeaf("attack")) {
+		t.Fatalf("Expected %v got %v", UnknownLeaf("attack"), err)
+	}
+
+	_, err = lib.Build(NodeDef{Action: "idle", Condition: "hasTarget"})
+	if !errors.Is(err, ErrInvalidNode) {
+		t.Fatalf("Expected %v got %v", ErrInvalidNode, err)
+	}
+
+	validate := NewLibrary()
+	validate.Validate = true
+
+	if _, err := validate.Build(trees["chase"]); err != nil {
+		t.Fatalf("Expected %v got %v", nil, err)
+	}
+
+	_, err = validate.Build(NodeDef{Action: "moveTo", Args: map[string]interface{}{"speed": "fast"}})
+	if err == nil {
+		t.Fatalf("Expected invalid moveTo arg got %v", err)
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	agent := &testAgent{}
+	target := engine.Vec2{X: 10, Y: 10}
+
+	tree := NewTree(&MoveTo{Target: "target", Speed: 2, Arrive: 1}, agent)
+	tree.Blackboard["target"] = target
+
+	if s := tree.Tick(); s != Running {
+		t.Fatalf("Expected %v got %v", Running, s)
+	}
+
+	angle := engine.Vec2{}.AngleTo(target)
+	if agent.Heading() != angle || agent.Direction != engine.AngleToCardinal(angle) {
+		t.Fatalf("Expected %v got %v", angle, agent.Heading())
+	}
+
+	if d := agent.Position().Distance(engine.Vec2{}); math.Abs(d-2) > 1e-9 {
+		t.Fatalf("Expected %v got %v", 2, d)
+	}
+
+	// without interest in range, the waypoint is approached directly
+	tree.Steering = engine.NewContextMap(8, nil, nil, nil, nil)
+	start := agent.Position()
+	tree.Tick()
+
+	if d := agent.Position().Distance(start); math.Abs(d-2) > 1e-9 {
+		t.Fatalf("Expected %v got %v", 2, d)
+	}
+
+	// the only direction of interest is masked by danger
+	tree.Steering.AddBehavior(engine.SteeringFunc(func(cm *engine.ContextMap, in *engine.SteeringInput) {
+		i := int(math.Round(in.Origin.AngleTo(target) / (math.Pi / 4)))
+		cm.Interest()[i] = 1
+		cm.Danger()[i] = 1
+	}))
+
+	start = agent.Position()
+	if s := tree.Tick(); s != Running || agent.Position() != start {
+		t.Fatalf("Expected %v got %v", start, agent.Position())
+	}
+}
@@ -0,0 +1,153 @@
+package behavior
+
+// Sequence ticks its children in order until one fails.
+// A running child is resumed on the next tick.
+type Sequence struct {
+	Children []Node
+
+	current int
+}
+
+// NewSequence returns an instantiated *Sequence.
+func NewSequence(children ...Node) *Sequence {
+	return &Sequence{Children: children}
+}
+
+// Tick implements Node.
+func (s *Sequence) Tick(t *Tree) Status {
+	for ; s.current < len(s.Children); s.current++ {
+		switch s.Children[s.current].Tick(t) {
+		case Running:
+			return Running
+		case Failure:
+			s.current = 0
+			return Failure
+		}
+	}
+
+	s.current = 0
+
+	return Success
+}
+
+// Reset implements Node.
+func (s *Sequence) Reset() {
+	resetAll(s.Children)
+	s.current = 0
+}
+
+// Selector ticks its children in order until one succeeds.
+// A running child is resumed on the next tick.
+type Selector struct {
+	Children []Node
+
+	current int
+}
+
+// NewSelector returns an instantiated *Selector.
+func NewSelector(children ...Node) *Selector {
+	return &Selector{Children: children}
+}
+
+// Tick implements Node.
+func (s *Selector) Tick(t *Tree) Status {
+	for ; s.current < len(s.Children); s.current++ {
+		switch s.Children[s.current].Tick(t) {
+		case Running:
+			return Running
+		case Success:
+			s.current = 0
+			return Success
+		}
+	}
+
+	s.current = 0
+
+	return Failure
+}
+
+// Reset implements Node.
+func (s *Selector) Reset() {
+	resetAll(s.Children)
+	s.current = 0
+}
+
+// Parallel ticks all of its unfinished children every tick.
+// It succeeds once Success children have succeeded, and fails
+// once that is no longer possible. Children still running
+// when it finishes are reset.
+type Parallel struct {
+	Children []Node
+	// Success is the number of children required
+	// to succeed. Zero requires every child.
+	Success int
+
+	results []Status
+}
+
+// NewParallel returns an instantiated *Parallel.
+func NewParallel(success int, children ...Node) *Parallel {
+	return &Parallel{
+		Children: children,
+		Success:  success,
+	}
+}
+
+// Tick implements Node.
+func (p *Parallel) Tick(t *Tree) Status {
+	required := p.Success
+	if required <= 0 || required > len(p.Children) {
+		required = len(p.Children)
+	}
+
+	if len(p.results) != len(p.Children) {
+		p.results = make([]Status, len(p.Children))
+	}
+
+	var succeeded, failed int
+	for i, child := range p.Children {
+		if p.results[i] == Running {
+			p.results[i] = child.Tick(t)
+		}
+
+		switch p.results[i] {
+		case Success:
+			succeeded++
+		case Failure:
+			failed++
+		}
+	}
+
+	status := Running
+	switch {
+	case succeeded >= required:
+		status = Success
+	case failed > len(p.Children)-required:
+		status = Failure
+	}
+
+	if status != Running {
+		p.Reset()
+	}
+
+	return status
+}
+
+// Reset implements Node.
+func (p *Parallel) Reset() {
+	for i, child := range p.Children {
+		if i < len(p.results) && p.results[i] == Running {
+			child.Reset()
+		}
+	}
+
+	for i := range p.results {
+		p.results[i] = Running
+	}
+}
+
+func resetAll(nodes []Node) {
+	for _, n := range nodes {
+		n.Reset()
+	}
+}
@@ -0,0 +1,150 @@
+package behavior
+
+// Inverter inverts the result of its child.
+type Inverter struct {
+	Child Node
+}
+
+// NewInverter returns an instantiated *Inverter.
+func NewInverter(child Node) *Inverter {
+	return &Inverter{Child: child}
+}
+
+// Tick implements Node.
+func (i *Inverter) Tick(t *Tree) Status {
+	switch i.Child.Tick(t) {
+	case Success:
+		return Failure
+	case Failure:
+		return Success
+	default:
+		return Running
+	}
+}
+
+// Reset implements Node.
+func (i *Inverter) Reset() {
+	i.Child.Reset()
+}
+
+// Repeat runs its child Count times, or forever if Count is zero,
+// and fails as soon as its child fails. The child is run at
+// most once per tick.
+type Repeat struct {
+	Child Node
+	Count int
+
+	n int
+}
+
+// NewRepeat returns an instantiated *Repeat.
+func NewRepeat(count int, child Node) *Repeat {
+	return &Repeat{
+		Child: child,
+		Count: count,
+	}
+}
+
+// Tick implements Node.
+func (r *Repeat) Tick(t *Tree) Status {
+	switch r.Child.Tick(t) {
+	case Failure:
+		r.n = 0
+		return Failure
+	case Success:
+		r.n++
+		if r.Count > 0 && r.n >= r.Count {
+			r.n = 0
+			return Success
+		}
+	}
+
+	return Running
+}
+
+// Reset implements Node.
+func (r *Repeat) Reset() {
+	r.Child.Reset()
+	r.n = 0
+}
+
+// Cooldown fails without running its child for
+// a number of ticks after the child finishes.
+type Cooldown struct {
+	Child Node
+	Ticks int
+
+	ready int
+}
+
+// NewCooldown returns an instantiated *Cooldown.
+func NewCooldown(ticks int, child Node) *Cooldown {
+	return &Cooldown{
+		Child: child,
+		Ticks: ticks,
+	}
+}
+
+// Tick implements Node.
+func (c *Cooldown) Tick(t *Tree) Status {
+	if t.ticks < c.ready {
+		return Failure
+	}
+
+	status := c.Child.Tick(t)
+	if status != Running {
+		c.ready = t.ticks + c.Ticks
+	}
+
+	return status
+}
+
+// Reset implements Node.
+// The cooldown continues after a reset.
+func (c *Cooldown) Reset() {
+	c.Child.Reset()
+}
+
+// Timeout fails and resets its child if it is
+// still running after a number of ticks.
+type Timeout struct {
+	Child Node
+	Ticks int
+
+	start   int
+	running bool
+}
+
+// NewTimeout returns an instantiated *Timeout.
+func NewTimeout(ticks int, child Node) *Timeout {
+	return &Timeout{
+		Child: child,
+		Ticks: ticks,
+	}
+}
+
+// Tick implements Node.
+func (to *Timeout) Tick(t *Tree) Status {
+	if !to.running {
+		to.start = t.ticks
+		to.running = true
+	}
+
+	if t.ticks-to.start >= to.Ticks {
+		to.Reset()
+		return Failure
+	}
+
+	status := to.Child.Tick(t)
+	if status != Running {
+		to.running = false
+	}
+
+	return status
+}
+
+// Reset implements Node.
+func (to *Timeout) Reset() {
+	to.Child.Reset()
+	to.running = false
+}
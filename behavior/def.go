@@ -0,0 +1,306 @@
+package behavior
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigType is the config type of behavior tree configs.
+const ConfigType = "behavior"
+
+// ErrInvalidNode occurs when a NodeDef does
+// not define exactly one type of node.
+var ErrInvalidNode = errors.New("invalid behavior node")
+
+// UnknownLeaf occurs when a condition or action
+// is not registered with a Library.
+type UnknownLeaf string
+
+// Error implements error.
+func (u UnknownLeaf) Error() string {
+	return fmt.Sprintf("unknown behavior leaf: %s", string(u))
+}
+
+// InvalidConfigType occurs when a config is not of ConfigType.
+type InvalidConfigType string
+
+// Error implements error.
+func (i InvalidConfigType) Error() string {
+	return fmt.Sprintf("invalid config type: %s", string(i))
+}
+
+// NodeDef defines a node in YAML. Exactly one type of node must be set:
+//
+//	version: 1.0
+//	type: behavior
+//	trees:
+//	  guard:
+//	    selector:
+//	      - sequence:
+//	          - condition: seesPlayer
+//	          - action: moveTo
+//	            args: {target: player, speed: 2}
+//	      - cooldown:
+//	          ticks: 120
+//	          child: {action: wander}
+type NodeDef struct {
+	Sequence []NodeDef    `yaml:"sequence,omitempty"`
+	Selector []NodeDef    `yaml:"selector,omitempty"`
+	Parallel *ParallelDef `yaml:"parallel,omitempty"`
+
+	Inverter *NodeDef      `yaml:"inverter,omitempty"`
+	Repeat   *DecoratorDef `yaml:"repeat,omitempty"`
+	Cooldown *DecoratorDef `yaml:"cooldown,omitempty"`
+	Timeout  *DecoratorDef `yaml:"timeout,omitempty"`
+
+	Condition string `yaml:"condition,omitempty"`
+	Action    string `yaml:"action,omitempty"`
+	// Args are passed to the ActionFactory of an action.
+	Args map[string]interface{} `yaml:"args,omitempty"`
+}
+
+// ParallelDef defines a Parallel node.
+type ParallelDef struct {
+	Success  int       `yaml:"success,omitempty"`
+	Children []NodeDef `yaml:"children"`
+}
+
+// DecoratorDef defines a Repeat, Cooldown or Timeout node.
+type DecoratorDef struct {
+	// Count is the repeat count of a Repeat node.
+	Count int `yaml:"count,omitempty"`
+	// Ticks is the duration of a Cooldown or Timeout node.
+	Ticks int     `yaml:"ticks,omitempty"`
+	Child NodeDef `yaml:"child"`
+}
+
+type config struct {
+	Version string             `yaml:"version"`
+	Type    string             `yaml:"type"`
+	Trees   map[string]NodeDef `yaml:"trees"`
+}
+
+// Load parses the tree definitions of a config file.
+func Load(path string) (map[string]NodeDef, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trees, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return trees, nil
+}
+
+// Parse parses the tree definitions of config data.
+func Parse(data []byte) (map[string]NodeDef, error) {
+	var conf config
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Type != ConfigType {
+		return nil, InvalidConfigType(conf.Type)
+	}
+
+	return conf.Trees, nil
+}
+
+// ActionFactory creates an action node from its YAML args.
+type ActionFactory func(args map[string]interface{}) (Node, error)
+
+// Library resolves the conditions and actions of NodeDefs by name.
+// The built-in moveTo action creates a MoveTo node from the
+// args target, avoid, speed, arrive and size.
+type Library struct {
+	// Validate builds conditions and actions which are not
+	// registered as leaves that fail, so that the structure of
+	// trees and the args of registered actions can be checked
+	// without those defined by a game.
+	Validate bool
+
+	conditions map[string]Condition
+	actions    map[string]ActionFactory
+}
+
+// NewLibrary returns an instantiated *Library.
+func NewLibrary() *Library {
+	l := &Library{
+		conditions: make(map[string]Condition),
+		actions:    make(map[string]ActionFactory),
+	}
+
+	l.Register("moveTo", newMoveTo)
+
+	return l
+}
+
+// Condition registers a condition.
+func (l *Library) Condition(name string, fn func(*Tree) bool) {
+	l.conditions[name] = fn
+}
+
+// Action registers an action without args.
+func (l *Library) Action(name string, fn func(*Tree) Status) {
+	l.Register(name, func(map[string]interface{}) (Node, error) {
+		return Action(fn), nil
+	})
+}
+
+// Register registers an action created from its args.
+// A new node is created for every tree built.
+func (l *Library) Register(name string, factory ActionFactory) {
+	l.actions[name] = factory
+}
+
+// Build creates the nodes of a definition.
+func (l *Library) Build(def NodeDef) (Node, error) {
+	if def.types() != 1 {
+		return nil, ErrInvalidNode
+	}
+
+	switch {
+	case def.Sequence != nil:
+		children, err := l.buildAll(def.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		return NewSequence(children...), nil
+
+	case def.Selector != nil:
+		children, err := l.buildAll(def.Selector)
+		if err != nil {
+			return nil, err
+		}
+		return NewSelector(children...), nil
+
+	case def.Parallel != nil:
+		children, err := l.buildAll(def.Parallel.Children)
+		if err != nil {
+			return nil, err
+		}
+		return NewParallel(def.Parallel.Success, children...), nil
+
+	case def.Inverter != nil:
+		child, err := l.Build(*def.Inverter)
+		if err != nil {
+			return nil, err
+		}
+		return NewInverter(child), nil
+
+	case def.Repeat != nil:
+		child, err := l.Build(def.Repeat.Child)
+		if err != nil {
+			return nil, err
+		}
+		return NewRepeat(def.Repeat.Count, child), nil
+
+	case def.Cooldown != nil:
+		child, err := l.Build(def.Cooldown.Child)
+		if err != nil {
+			return nil, err
+		}
+		return NewCooldown(def.Cooldown.Ticks, child), nil
+
+	case def.Timeout != nil:
+		child, err := l.Build(def.Timeout.Child)
+		if err != nil {
+			return nil, err
+		}
+		return NewTimeout(def.Timeout.Ticks, child), nil
+
+	case def.Condition != "":
+		cond, ok := l.conditions[def.Condition]
+		if !ok {
+			if l.Validate {
+				return Condition(func(*Tree) bool { return false }), nil
+			}
+			return nil, UnknownLeaf(def.Condition)
+		}
+		return cond, nil
+
+	default:
+		factory, ok := l.actions[def.Action]
+		if !ok {
+			if l.Validate {
+				return Action(func(*Tree) Status { return Failure }), nil
+			}
+			return nil, UnknownLeaf(def.Action)
+		}
+		return factory(def.Args)
+	}
+}
+
+// types returns the number of node types set.
+func (def NodeDef) types() int {
+	set := []bool{
+		def.Sequence != nil,
+		def.Selector != nil,
+		def.Parallel != nil,
+		def.Inverter != nil,
+		def.Repeat != nil,
+		def.Cooldown != nil,
+		def.Timeout != nil,
+		def.Condition != "",
+		def.Action != "",
+	}
+
+	var n int
+	for _, ok := range set {
+		if ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (l *Library) buildAll(defs []NodeDef) ([]Node, error) {
+	nodes := make([]Node, len(defs))
+	for i, def := range defs {
+		node, err := l.Build(def)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[i] = node
+	}
+
+	return nodes, nil
+}
+
+func newMoveTo(args map[string]interface{}) (Node, error) {
+	m := &MoveTo{
+		Target: "target",
+		Speed:  1,
+	}
+
+	for key, v := range args {
+		var ok bool
+
+		switch key {
+		case "target":
+			m.Target, ok = v.(string)
+		case "avoid":
+			m.Avoid, ok = v.(string)
+		case "speed":
+			m.Speed, ok = toFloat(v)
+		case "arrive":
+			m.Arrive, ok = toFloat(v)
+		case "size":
+			m.Size, ok = v.(int)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("invalid moveTo arg: %s", key)
+		}
+	}
+
+	return m, nil
+}
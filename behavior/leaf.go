@@ -0,0 +1,120 @@
+package behavior
+
+import (
+	"math"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+// Condition succeeds if its function returns true, and fails otherwise.
+type Condition func(*Tree) bool
+
+// Tick implements Node.
+func (c Condition) Tick(t *Tree) Status {
+	if c(t) {
+		return Success
+	}
+
+	return Failure
+}
+
+// Reset implements Node.
+func (c Condition) Reset() {}
+
+// Action returns the status returned by its function.
+type Action func(*Tree) Status
+
+// Tick implements Node.
+func (a Action) Tick(t *Tree) Status {
+	return a(t)
+}
+
+// Reset implements Node.
+func (a Action) Reset() {}
+
+// Mover is an entity moved by movement actions,
+// such as entities embedding engine.CoreEntity.
+type Mover interface {
+	Position() engine.Vec2
+	MoveTowards(angle, dist, interval float64)
+}
+
+// MoveTo moves the tree's entity towards a target on
+// the blackboard, and succeeds once it has arrived.
+// The route is found by the tree's Pathfinder and steered
+// by its ContextMap, if set. The entity holds its position
+// while the direction of its waypoint is masked by danger.
+// It fails if the entity is not a Mover, the target is
+// unset or the target is unreachable.
+type MoveTo struct {
+	// Target is the blackboard key of the target, which
+	// may be an engine.Vec2 or an engine.Entity.
+	Target string
+	// Avoid is the optional blackboard key of
+	// []engine.Vec2 positions to steer away from.
+	Avoid string
+
+	// Speed is the distance moved per tick.
+	Speed float64
+	// Arrive is the distance from the
+	// target at which the entity has arrived.
+	Arrive float64
+	// Size is the size of the entity in tiles, for pathfinding.
+	Size int
+}
+
+// Tick implements Node.
+func (m *MoveTo) Tick(t *Tree) Status {
+	mover, ok := t.Entity.(Mover)
+	if !ok {
+		return Failure
+	}
+
+	target, ok := t.Blackboard.Position(m.Target)
+	if !ok {
+		return Failure
+	}
+
+	pos := mover.Position()
+	if pos.Distance(target) <= m.Arrive {
+		return Success
+	}
+
+	waypoint := target
+	if t.Pathfinder != nil {
+		size := m.Size
+		if size < 1 {
+			size = 1
+		}
+
+		if waypoint, ok = t.Pathfinder.NextWaypoint(pos, target, size); !ok {
+			return Failure
+		}
+	}
+
+	angle := pos.AngleTo(waypoint)
+	if t.Steering != nil {
+		avoid, _ := t.Blackboard[m.Avoid].([]engine.Vec2)
+
+		a, strength := t.Steering.Angle(pos, []engine.Vec2{waypoint}, avoid)
+
+		switch {
+		case strength > 0:
+			angle = a
+		case t.Steering.Masked(angle):
+			// every direction of interest is masked by danger
+			return Running
+		default:
+			// the waypoint is out of steering range,
+			// and is approached directly
+		}
+	}
+
+	dist := math.Min(m.Speed, pos.Distance(waypoint))
+	mover.MoveTowards(angle, dist, 0)
+
+	return Running
+}
+
+// Reset implements Node.
+func (m *MoveTo) Reset() {}
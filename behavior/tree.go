@@ -0,0 +1,117 @@
+// Package behavior contains behavior trees for entity decision making.
+//
+// A Tree is ticked from the Tick method of the entity it controls:
+//
+//	func (e *Guard) Tick() {
+//		e.tree.Tick()
+//		e.CoreEntity.Tick()
+//	}
+//
+// Nodes hold the running state of their tree, so each
+// agent has its own tree, built from Go or from a YAML
+// definition through a Library.
+package behavior
+
+import "github.com/split-cube-studios/ardent/engine"
+
+// Status is the result of ticking a Node.
+type Status byte
+
+// Node statuses.
+const (
+	Running Status = iota
+	Success
+	Failure
+)
+
+// Node is a node of a behavior tree.
+type Node interface {
+	// Tick updates the node, and returns its status.
+	Tick(*Tree) Status
+	// Reset aborts the node if it is running.
+	Reset()
+}
+
+// Tree is a behavior tree controlling an agent.
+type Tree struct {
+	Root Node
+	// Blackboard holds the agent's memory,
+	// shared between the nodes of the tree.
+	Blackboard Blackboard
+	// Entity is the agent controlled by the tree.
+	Entity engine.Entity
+
+	// Steering optionally steers movement actions.
+	Steering *engine.ContextMap
+	// Pathfinder optionally routes movement actions around walls.
+	Pathfinder *engine.Pathfinder
+
+	ticks int
+}
+
+// NewTree returns an instantiated *Tree
+// controlling an entity.
+func NewTree(root Node, entity engine.Entity) *Tree {
+	return &Tree{
+		Root:       root,
+		Blackboard: make(Blackboard),
+		Entity:     entity,
+	}
+}
+
+// Tick ticks the root node, and returns its status.
+func (t *Tree) Tick() Status {
+	t.ticks++
+	return t.Root.Tick(t)
+}
+
+// Ticks returns the number of times the Tree has been ticked.
+func (t *Tree) Ticks() int {
+	return t.ticks
+}
+
+// Reset aborts every running node.
+func (t *Tree) Reset() {
+	t.Root.Reset()
+}
+
+// Blackboard is the memory of an agent.
+type Blackboard map[string]interface{}
+
+// Float returns a numeric value as a float64.
+func (b Blackboard) Float(key string) (float64, bool) {
+	return toFloat(b[key])
+}
+
+// Bool returns a boolean value.
+func (b Blackboard) Bool(key string) bool {
+	v, _ := b[key].(bool)
+	return v
+}
+
+// Position returns an engine.Vec2 value,
+// or the position of an engine.Entity value.
+func (b Blackboard) Position(key string) (engine.Vec2, bool) {
+	switch v := b[key].(type) {
+	case engine.Vec2:
+		return v, true
+	case engine.Entity:
+		if v.IsDisposed() {
+			return engine.Vec2{}, false
+		}
+		return v.Position(), true
+	default:
+		return engine.Vec2{}, false
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -1,18 +1,130 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/split-cube-studios/ardent/aautil"
 )
 
+const usage = `usage: aautil <command> [flags] [paths]
+
+commands:
+  build     build asset files from the configs in each directory
+  validate  check the configs in each directory without building
+  inspect   describe the contents of asset files
+  unpack    extract the images and sounds of asset files
+
+Directories default to the current directory. Paths which
+are not a command are built, as in "aautil build <paths>".
+Run "aautil <command> -h" for the flags of a command.
+`
+
 func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	args := flag.Args()
+
+	cmd := "build"
+	if len(args) > 0 {
+		switch args[0] {
+		case "build", "validate", "inspect", "unpack":
+			cmd, args = args[0], args[1:]
+		case "help":
+			flag.Usage()
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+
+	var (
+		outDir string
+		dryRun bool
+	)
+
+	switch cmd {
+	case "build":
+		fs.StringVar(&outDir, "o", "", "write asset files to `dir`, instead of next to their configs")
+		fs.BoolVar(&dryRun, "n", false, "validate and list asset files without writing them")
+	case "unpack":
+		fs.StringVar(&outDir, "o", "", "extract files to `dir`, instead of next to the asset file")
+	}
+
+	_ = fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		switch cmd {
+		case "inspect", "unpack":
+			fmt.Fprintf(os.Stderr, "aautil %s: no asset files\n", cmd)
+			os.Exit(2)
+		}
+
+		paths = []string{"./"}
+	}
+
+	var failures aautil.Errors
+
+	for _, path := range paths {
+		var err error
+
+		switch cmd {
+		case "build":
+			var built []string
+			built, err = aautil.Build(path, aautil.Options{OutDir: outDir, DryRun: dryRun})
+			for _, asset := range built {
+				if dryRun {
+					fmt.Println("would write", asset)
+				} else {
+					fmt.Println("wrote", asset)
+				}
+			}
+
+		case "validate":
+			err = aautil.Validate(path)
+
+		case "inspect":
+			err = aautil.Inspect(path, os.Stdout)
+
+		case "unpack":
+			dir := outDir
+			if dir == "" {
+				dir = filepath.Dir(path)
+			}
+
+			var unpacked []string
+			unpacked, err = aautil.Unpack(path, dir)
+			for _, file := range unpacked {
+				fmt.Println("wrote", file)
+			}
+		}
 
-	if len(os.Args) > 1 {
-		for i := 1; i < len(os.Args); i++ {
-			aautil.CreateAssets(os.Args[i])
+		if errs, ok := err.(aautil.Errors); ok {
+			failures = append(failures, errs...)
+		} else if err != nil {
+			failures = append(failures, err)
 		}
+	}
+
+	if len(failures) == 0 {
+		return
+	}
+
+	for _, err := range failures {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if len(failures) == 1 {
+		fmt.Fprintf(os.Stderr, "aautil %s: 1 failure\n", cmd)
 	} else {
-		aautil.CreateAssets("./")
+		fmt.Fprintf(os.Stderr, "aautil %s: %d failures\n", cmd, len(failures))
 	}
+
+	os.Exit(1)
 }
@@ -0,0 +1,185 @@
+package ecs
+
+import "github.com/split-cube-studios/ardent/engine"
+
+// Transform is the position of an entity.
+// Entities without a Transform are positioned at the origin.
+type Transform struct {
+	Position engine.Vec2
+}
+
+// Renderable contains the images of an entity.
+type Renderable struct {
+	Images []engine.Image
+}
+
+// Hits contains the hit shapes of an entity.
+// OnEvent is called with every engine.HitEvent of the entity.
+type Hits struct {
+	Shapes  []engine.HitShape
+	OnEvent func(engine.HitEvent)
+}
+
+// Proxy is an engine.Entity backed by an entity in a World,
+// allowing it to be added to an engine.Context. Its position,
+// images, hit shapes and physics body are read from the
+// *Transform, *Renderable, *Hits and *engine.Body components.
+type Proxy struct {
+	w     *World
+	id    EntityID
+	class string
+
+	collider *engine.Collider
+	prevPos  engine.Vec2
+}
+
+// Proxy returns the Proxy of an entity, creating
+// it with a given class if it does not exist.
+// Nil is returned for dead entities.
+func (w *World) Proxy(id EntityID, class string) *Proxy {
+	if !w.Alive(id) {
+		return nil
+	}
+
+	if p, ok := w.proxies[id]; ok {
+		return p
+	}
+
+	p := &Proxy{
+		w:     w,
+		id:    id,
+		class: class,
+	}
+
+	if t := p.transform(); t != nil {
+		p.prevPos = t.Position
+	}
+
+	w.proxies[id] = p
+
+	return p
+}
+
+// ID returns the ID of the proxied entity.
+func (p *Proxy) ID() EntityID {
+	return p.id
+}
+
+// Tick resolves movement of the entity since the last tick,
+// and moves its images to its position.
+func (p *Proxy) Tick() {
+	t := p.transform()
+	if t == nil {
+		return
+	}
+
+	switch body := p.Body(); {
+	case body != nil && body.World() != nil:
+		t.Position = body.World().Move(body, t.Position.Sub(body.Position()))
+	case p.collider != nil:
+		t.Position = p.collider.Resolve(p.prevPos, t.Position)
+	}
+
+	p.prevPos = t.Position
+
+	for _, img := range p.Images() {
+		img.Translate(t.Position.X, t.Position.Y)
+	}
+}
+
+// SetCollider sets the Proxy's Collider.
+func (p *Proxy) SetCollider(collider *engine.Collider) {
+	p.collider = collider
+}
+
+// Position returns the position of the entity.
+func (p *Proxy) Position() engine.Vec2 {
+	if t := p.transform(); t != nil {
+		return t.Position
+	}
+
+	return engine.Vec2{}
+}
+
+// AddImage adds Images to the Renderable of the entity,
+// creating it if necessary.
+func (p *Proxy) AddImage(images ...engine.Image) {
+	r, _ := p.w.Get(p.id, p.w.Register((*Renderable)(nil))).(*Renderable)
+	if r == nil {
+		r = new(Renderable)
+		p.w.Add(p.id, r)
+	}
+
+	pos := p.Position()
+	for _, img := range images {
+		img.Translate(pos.X, pos.Y)
+	}
+
+	r.Images = append(r.Images, images...)
+}
+
+// Images returns the images of the entity.
+func (p *Proxy) Images() []engine.Image {
+	r, _ := p.w.Get(p.id, p.w.Register((*Renderable)(nil))).(*Renderable)
+	if r == nil {
+		return nil
+	}
+
+	return r.Images
+}
+
+// Class returns the class of the Proxy.
+func (p *Proxy) Class() string {
+	return p.class
+}
+
+// Dispose destroys the entity, disposing its images
+// and removing its body from its world.
+func (p *Proxy) Dispose() {
+	for _, img := range p.Images() {
+		img.Dispose()
+	}
+
+	if body := p.Body(); body != nil && body.World() != nil {
+		body.World().Remove(body)
+	}
+
+	p.w.Destroy(p.id)
+}
+
+// IsDisposed indicates whether the entity has been destroyed.
+func (p *Proxy) IsDisposed() bool {
+	return !p.w.Alive(p.id)
+}
+
+// HitShapes returns the hit shapes of the entity.
+func (p *Proxy) HitShapes() []engine.HitShape {
+	if h := p.hits(); h != nil {
+		return h.Shapes
+	}
+
+	return nil
+}
+
+// HitEvent passes a hit event to the entity.
+func (p *Proxy) HitEvent(event engine.HitEvent) {
+	if h := p.hits(); h != nil && h.OnEvent != nil {
+		h.OnEvent(event)
+	}
+}
+
+// Body returns the physics body of the entity.
+func (p *Proxy) Body() *engine.Body {
+	b, _ := p.w.Get(p.id, p.w.Register((*engine.Body)(nil))).(*engine.Body)
+	return b
+}
+
+func (p *Proxy) transform() *Transform {
+	t, _ := p.w.Get(p.id, p.w.Register((*Transform)(nil))).(*Transform)
+	return t
+}
+
+func (p *Proxy) hits() *Hits {
+	h, _ := p.w.Get(p.id, p.w.Register((*Hits)(nil))).(*Hits)
+	return h
+}
@@ -0,0 +1,98 @@
+package ecs
+
+// Query matches entities by their component types.
+type Query struct {
+	w         *World
+	all, none Mask
+	types     []ComponentType
+}
+
+// Query returns a Query matching entities
+// with components of every given type.
+func (w *World) Query(types ...ComponentType) *Query {
+	return &Query{
+		w:     w,
+		all:   NewMask(types...),
+		types: types,
+	}
+}
+
+// Without excludes entities with components of any of the given types.
+func (q *Query) Without(types ...ComponentType) *Query {
+	q.none |= NewMask(types...)
+	return q
+}
+
+// Matches indicates whether an entity matches the Query.
+func (q *Query) Matches(id EntityID) bool {
+	m := q.w.Mask(id)
+	return q.w.Alive(id) && m.Contains(q.all) && m&q.none == 0
+}
+
+// Entities returns the IDs of all matching entities.
+func (q *Query) Entities() []EntityID {
+	var ids []EntityID
+
+	for _, id := range q.candidates() {
+		if q.Matches(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Each calls fn for every matching entity. Entities may be
+// created, modified or destroyed by fn. Entities created
+// during iteration are not visited, and destroyed entities
+// are skipped.
+func (q *Query) Each(fn func(EntityID)) {
+	for _, id := range q.candidates() {
+		if q.Matches(id) {
+			fn(id)
+		}
+	}
+}
+
+// Count returns the number of matching entities.
+func (q *Query) Count() int {
+	n := 0
+
+	for _, id := range q.candidates() {
+		if q.Matches(id) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// candidates returns a copy of the entities of the
+// smallest storage in the query, or every live entity.
+func (q *Query) candidates() []EntityID {
+	var smallest *storage
+
+	for _, t := range q.types {
+		if int(t) >= len(q.w.storages) {
+			return nil
+		}
+
+		s := q.w.storages[t]
+		if smallest == nil || len(s.ids) < len(smallest.ids) {
+			smallest = s
+		}
+	}
+
+	if smallest != nil {
+		return append([]EntityID(nil), smallest.ids...)
+	}
+
+	var ids []EntityID
+	for i, gen := range q.w.generations {
+		if gen%2 == 1 {
+			ids = append(ids, newEntityID(uint32(i), gen))
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,52 @@
+package ecs
+
+// Phase is a stage of a World tick.
+// Systems run in order of phase, then in the
+// order they were added.
+type Phase int
+
+const (
+	// PhasePreUpdate runs before PhaseUpdate,
+	// typically for input and AI decisions.
+	PhasePreUpdate Phase = iota
+
+	// PhaseUpdate runs game logic.
+	PhaseUpdate
+
+	// PhasePostUpdate runs after PhaseUpdate,
+	// typically for cleanup and synchronization.
+	PhasePostUpdate
+
+	phaseCount
+)
+
+// System updates the entities of a World.
+type System interface {
+	Tick(*World)
+}
+
+// SystemFunc is a function implementing System.
+type SystemFunc func(*World)
+
+// Tick implements System.
+func (f SystemFunc) Tick(w *World) {
+	f(w)
+}
+
+// AddSystem adds systems to a phase of the World.
+func (w *World) AddSystem(phase Phase, systems ...System) {
+	if phase < 0 || phase >= phaseCount {
+		return
+	}
+
+	w.systems[phase] = append(w.systems[phase], systems...)
+}
+
+// Tick runs every system of the World in order.
+func (w *World) Tick() {
+	for _, systems := range w.systems {
+		for _, s := range systems {
+			s.Tick(w)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package ecs contains an entity-component-system that
+// interoperates with engine.Context through entity proxies.
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MaxComponentTypes is the maximum number of
+// component types that can be registered with a World.
+const MaxComponentTypes = 64
+
+// EntityID identifies an entity in a World.
+// IDs of destroyed entities are never reused.
+type EntityID uint64
+
+func newEntityID(index, generation uint32) EntityID {
+	return EntityID(generation)<<32 | EntityID(index)
+}
+
+func (id EntityID) index() uint32 {
+	return uint32(id)
+}
+
+func (id EntityID) generation() uint32 {
+	return uint32(id >> 32)
+}
+
+// ComponentType identifies a registered component type.
+type ComponentType uint
+
+// Mask is a set of component types.
+type Mask uint64
+
+// NewMask returns a Mask containing the given component types.
+func NewMask(types ...ComponentType) Mask {
+	var m Mask
+	for _, t := range types {
+		m |= 1 << t
+	}
+
+	return m
+}
+
+// Contains indicates whether a Mask contains every type of another Mask.
+func (m Mask) Contains(other Mask) bool {
+	return m&other == other
+}
+
+// storage holds every component of a single type,
+// densely packed with a sparse index by entity.
+type storage struct {
+	typ    reflect.Type
+	dense  []interface{}
+	ids    []EntityID
+	sparse []int
+}
+
+func (s *storage) get(id EntityID) (interface{}, bool) {
+	i := int(id.index())
+	if i >= len(s.sparse) || s.sparse[i] == 0 {
+		return nil, false
+	}
+
+	return s.dense[s.sparse[i]-1], true
+}
+
+func (s *storage) set(id EntityID, component interface{}) {
+	i := int(id.index())
+	for i >= len(s.sparse) {
+		s.sparse = append(s.sparse, 0)
+	}
+
+	if s.sparse[i] != 0 {
+		s.dense[s.sparse[i]-1] = component
+		return
+	}
+
+	s.dense = append(s.dense, component)
+	s.ids = append(s.ids, id)
+	s.sparse[i] = len(s.dense)
+}
+
+func (s *storage) remove(id EntityID) {
+	i := int(id.index())
+	if i >= len(s.sparse) || s.sparse[i] == 0 {
+		return
+	}
+
+	j, last := s.sparse[i]-1, len(s.dense)-1
+
+	s.dense[j] = s.dense[last]
+	s.ids[j] = s.ids[last]
+	s.sparse[s.ids[j].index()] = j + 1
+
+	s.dense[last] = nil
+	s.dense = s.dense[:last]
+	s.ids = s.ids[:last]
+	s.sparse[i] = 0
+}
+
+// World contains entities, their components, and the systems
+// that update them.
+type World struct {
+	generations []uint32
+	masks       []Mask
+	free        []uint32
+
+	types    map[reflect.Type]ComponentType
+	storages []*storage
+
+	systems [phaseCount][]System
+	proxies map[EntityID]*Proxy
+}
+
+// NewWorld returns an instantiated *World.
+func NewWorld() *World {
+	return &World{
+		types:   make(map[reflect.Type]ComponentType),
+		proxies: make(map[EntityID]*Proxy),
+	}
+}
+
+// Register registers the type of a component, and returns its
+// ComponentType. Registering an already registered type returns
+// the existing ComponentType. Typed nil pointers may be used,
+// such as (*Health)(nil).
+func (w *World) Register(component interface{}) ComponentType {
+	typ := reflect.TypeOf(component)
+	if t, ok := w.types[typ]; ok {
+		return t
+	}
+
+	if len(w.storages) == MaxComponentTypes {
+		panic(fmt.Sprintf("ecs: cannot register more than %d component types", MaxComponentTypes))
+	}
+
+	t := ComponentType(len(w.storages))
+	w.types[typ] = t
+	w.storages = append(w.storages, &storage{typ: typ})
+
+	return t
+}
+
+// TypeOf returns the ComponentType of a component,
+// if its type is registered.
+func (w *World) TypeOf(component interface{}) (ComponentType, bool) {
+	t, ok := w.types[reflect.TypeOf(component)]
+	return t, ok
+}
+
+// NewEntity creates an entity with the given components.
+func (w *World) NewEntity(components ...interface{}) EntityID {
+	var index uint32
+
+	if n := len(w.free); n > 0 {
+		index = w.free[n-1]
+		w.free = w.free[:n-1]
+	} else {
+		index = uint32(len(w.generations))
+		w.generations = append(w.generations, 0)
+		w.masks = append(w.masks, 0)
+	}
+
+	w.generations[index]++
+	id := newEntityID(index, w.generations[index])
+
+	w.Add(id, components...)
+
+	return id
+}
+
+// Alive indicates whether an entity exists.
+func (w *World) Alive(id EntityID) bool {
+	i := int(id.index())
+	return id.generation() != 0 &&
+		i < len(w.generations) &&
+		w.generations[i] == id.generation() &&
+		w.generations[i]%2 == 1
+}
+
+// Destroy removes an entity and all of its components.
+func (w *World) Destroy(id EntityID) {
+	if !w.Alive(id) {
+		return
+	}
+
+	i := id.index()
+	for t, s := range w.storages {
+		if w.masks[i]&(1<<t) != 0 {
+			s.remove(id)
+		}
+	}
+
+	// even generations mark free slots
+	w.generations[i]++
+	w.masks[i] = 0
+	w.free = append(w.free, i)
+
+	delete(w.proxies, id)
+}
+
+// Add adds components to an entity, replacing any existing
+// components of the same types. Component types are registered
+// as needed. Components added to dead entities are ignored.
+func (w *World) Add(id EntityID, components ...interface{}) {
+	if !w.Alive(id) {
+		return
+	}
+
+	for _, c := range components {
+		t := w.Register(c)
+		w.storages[t].set(id, c)
+		w.masks[id.index()] |= 1 << t
+	}
+}
+
+// Get returns the component of a given type of an entity,
+// or nil if it does not exist.
+func (w *World) Get(id EntityID, t ComponentType) interface{} {
+	if !w.Alive(id) || int(t) >= len(w.storages) {
+		return nil
+	}
+
+	c, _ := w.storages[t].get(id)
+	return c
+}
+
+// Has indicates whether an entity has components of every given type.
+func (w *World) Has(id EntityID, types ...ComponentType) bool {
+	return w.Alive(id) && w.masks[id.index()].Contains(NewMask(types...))
+}
+
+// Mask returns the set of component types of an entity.
+func (w *World) Mask(id EntityID) Mask {
+	if !w.Alive(id) {
+		return 0
+	}
+
+	return w.masks[id.index()]
+}
+
+// Remove removes components of the given types from an entity.
+func (w *World) Remove(id EntityID, types ...ComponentType) {
+	if !w.Alive(id) {
+		return
+	}
+
+	for _, t := range types {
+		if int(t) >= len(w.storages) {
+			continue
+		}
+
+		w.storages[t].remove(id)
+		w.masks[id.index()] &^= 1 << t
+	}
+}
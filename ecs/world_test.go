@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+type health struct {
+	hp int
+}
+
+type poison struct {
+	damage int
+}
+
+type immune struct{}
+
+func TestWorld(t *testing.T) {
+
+	w := NewWorld()
+	healthType := w.Register((*health)(nil))
+	poisonType := w.Register((*poison)(nil))
+	immuneType := w.Register(immune{})
+
+	a := w.NewEntity(&health{hp: 10}, &poison{damage: 3})
+	b := w.NewEntity(&health{hp: 10}, &poison{damage: 3}, immune{})
+	c := w.NewEntity(&health{hp: 10})
+
+	var order []string
+	w.AddSystem(PhasePostUpdate, SystemFunc(func(w *World) {
+		order = append(order, "cleanup")
+
+		w.Query(healthType).Each(func(id EntityID) {
+			if w.Get(id, healthType).(*health).hp <= 0 {
+				w.Destroy(id)
+			}
+		})
+	}))
+	w.AddSystem(PhaseUpdate, SystemFunc(func(w *World) {
+		order = append(order, "poison")
+
+		w.Query(healthType, poisonType).Without(immuneType).Each(func(id EntityID) {
+			w.Get(id, healthType).(*health).hp -= w.Get(id, poisonType).(*poison).damage
+		})
+	}))
+
+	w.Tick()
+
+	if len(order) != 2 || order[0] != "poison" || order[1] != "cleanup" {
+		t.Fatalf("Expected [poison cleanup] got %v", order)
+	}
+
+	if hp := w.Get(a, healthType).(*health).hp; hp != 7 {
+		t.Fatalf("Expected 7 got %d", hp)
+	}
+
+	if hp := w.Get(b, healthType).(*health).hp; hp != 10 {
+		t.Fatalf("Expected 10 got %d", hp)
+	}
+
+	for i := 0; i < 3; i++ {
+		w.Tick()
+	}
+
+	if w.Alive(a) {
+		t.Fatal("Expected entity to be destroyed.")
+	}
+
+	if n := w.Query(healthType).Count(); n != 2 {
+		t.Fatalf("Expected 2 got %d", n)
+	}
+
+	// IDs are not reused
+	d := w.NewEntity()
+	if d == a || w.Has(d, healthType) {
+		t.Fatalf("Expected new entity got %v", d)
+	}
+
+	w.Remove(c, healthType)
+	if w.Has(c, healthType) || w.Get(c, healthType) != nil {
+		t.Fatal("Expected health to be removed.")
+	}
+}
+
+func TestProxy(t *testing.T) {
+
+	w := NewWorld()
+	id := w.NewEntity(&Transform{Position: engine.Vec2{X: 1, Y: 2}})
+
+	var e engine.Entity = w.Proxy(id, "player")
+	if w.Proxy(id, "player") != e {
+		t.Fatal("Expected the same proxy.")
+	}
+
+	if pos := e.Position(); pos != (engine.Vec2{X: 1, Y: 2}) {
+		t.Fatalf("Expected %v got %v", engine.Vec2{X: 1, Y: 2}, pos)
+	}
+
+	if _, ok := e.(engine.HitShaper); !ok {
+		t.Fatal("Expected proxy to implement engine.HitShaper.")
+	}
+
+	e.Dispose()
+	if !e.IsDisposed() || w.Alive(id) {
+		t.Fatal("Expected proxy to be disposed.")
+	}
+}
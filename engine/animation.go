@@ -3,11 +3,62 @@ package engine
 // Animation is a series of frames that play in sequence.
 type Animation interface {
 	SetState(string)
+	State() string
 
 	SetTickCount(int)
+	TickCount() int
 	Play()
 	Pause()
 	Reset()
 
+	// SetSpeed scales the playback speed, where 1 is normal speed.
+	SetSpeed(float64)
+	Speed() float64
+
+	// Frame returns the index of the current frame within the state.
+	Frame() int
+	// Progress returns the progress through the
+	// current cycle of the state, from 0 to 1.
+	Progress() float64
+
+	// OnFrame sets a callback for when a frame is shown.
+	OnFrame(func(AnimationFrame))
+	// OnLoop sets a callback for when a looping state restarts.
+	OnLoop(func(state string))
+	// OnComplete sets a callback for when
+	// a non-looping state has finished.
+	OnComplete(func(state string))
+
+	// Crossfade sets the state, fading out the
+	// previous state over a number of ticks.
+	Crossfade(state string, ticks int)
+	// SyncTo makes the clock follow another Animation
+	// while both are in the same state, or stops
+	// following if the Animation is nil.
+	SyncTo(Animation)
+
 	Image
 }
+
+// AnimationFrame describes a frame of an Animation
+// as it is shown, and is passed to OnFrame callbacks.
+type AnimationFrame struct {
+	State string
+	// Frame is the index of the frame within the state.
+	Frame int
+	// Events are the names of the events declared on the frame.
+	Events []string
+}
+
+// Playback is the order in which the frames of an animation state play.
+type Playback byte
+
+// Playback orders.
+const (
+	// PlaybackForward plays frames from the first to the last.
+	PlaybackForward Playback = iota
+	// PlaybackReverse plays frames from the last to the first.
+	PlaybackReverse
+	// PlaybackPingPong plays frames forward and then back.
+	PlaybackPingPong
+)
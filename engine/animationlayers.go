@@ -0,0 +1,131 @@
+package engine
+
+// AnimationLayers is a stack of Animations drawn over each
+// other, such as a body, equipment and a status aura. Each
+// layer follows the state and clock of the base layer, unless
+// it is overridden with its own state, such as an upper body
+// attack over a lower body walk. Layers are drawn in the order
+// given by their z depth, so it should be set when adding them.
+//
+// The images of the layers are added to entities:
+//
+//	e.AddImage(layers.Images()...)
+type AnimationLayers struct {
+	layers    []Animation
+	names     map[string]int
+	overrides map[int]bool
+}
+
+// NewAnimationLayers returns an instantiated
+// *AnimationLayers with a base layer.
+func NewAnimationLayers(base Animation) *AnimationLayers {
+	return &AnimationLayers{
+		layers:    []Animation{base},
+		names:     make(map[string]int),
+		overrides: make(map[int]bool),
+	}
+}
+
+// Add adds a named layer above the others,
+// which follows the state of the base layer.
+func (l *AnimationLayers) Add(name string, anim Animation) {
+	l.names[name] = len(l.layers)
+	l.layers = append(l.layers, anim)
+
+	anim.SyncTo(l.Base())
+	anim.SetState(l.Base().State())
+}
+
+// Base returns the base layer.
+func (l *AnimationLayers) Base() Animation {
+	return l.layers[0]
+}
+
+// Layer returns a named layer, or nil if it does not exist.
+func (l *AnimationLayers) Layer(name string) Animation {
+	i, ok := l.names[name]
+	if !ok {
+		return nil
+	}
+
+	return l.layers[i]
+}
+
+// Images returns the images of the layers, starting with the base.
+func (l *AnimationLayers) Images() []Image {
+	images := make([]Image, len(l.layers))
+	for i, anim := range l.layers {
+		images[i] = anim
+	}
+
+	return images
+}
+
+// State returns the state of the base layer.
+func (l *AnimationLayers) State() string {
+	return l.Base().State()
+}
+
+// SetState sets the state of the base layer
+// and the layers which are not overridden.
+func (l *AnimationLayers) SetState(state string) {
+	for i, anim := range l.layers {
+		if !l.overrides[i] {
+			anim.SetState(state)
+		}
+	}
+}
+
+// Crossfade crossfades the state of the base layer
+// and the layers which are not overridden.
+func (l *AnimationLayers) Crossfade(state string, ticks int) {
+	for i, anim := range l.layers {
+		if !l.overrides[i] {
+			anim.Crossfade(state, ticks)
+		}
+	}
+}
+
+// Override sets the state of a named layer independently of
+// the base layer, until it is released. Overrides of
+// non-looping states are released by Tick once finished.
+func (l *AnimationLayers) Override(name, state string) {
+	i, ok := l.names[name]
+	if !ok {
+		return
+	}
+
+	l.overrides[i] = true
+
+	// restart even if already in the state
+	anim := l.layers[i]
+	anim.SetState(state)
+	anim.Reset()
+}
+
+// Overridden indicates whether a named layer is overridden.
+func (l *AnimationLayers) Overridden(name string) bool {
+	return l.overrides[l.names[name]]
+}
+
+// Release returns a named layer to the state of the
+// base layer, after which its clock follows the base.
+func (l *AnimationLayers) Release(name string) {
+	i, ok := l.names[name]
+	if !ok || !l.overrides[i] {
+		return
+	}
+
+	delete(l.overrides, i)
+	l.layers[i].SetState(l.State())
+}
+
+// Tick releases finished overrides. It should be called
+// each tick, such as from the Tick method of an entity.
+func (l *AnimationLayers) Tick() {
+	for name, i := range l.names {
+		if l.overrides[i] && l.layers[i].Progress() >= 1 {
+			l.Release(name)
+		}
+	}
+}
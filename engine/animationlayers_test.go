@@ -0,0 +1,62 @@
+package engine
+
+import "testing"
+
+func TestAnimationLayers(t *testing.T) {
+	base, upper, aura := newTestAnimation(), newTestAnimation(), newTestAnimation()
+
+	layers := NewAnimationLayers(base)
+	layers.Add("upper", upper)
+	layers.Add("aura", aura)
+	layers.SetState("walk")
+
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			// layers ticked before the base lag by a tick
+			aura.Tick()
+			base.Tick()
+			upper.Tick()
+			layers.Tick()
+		}
+	}
+
+	tick(5)
+
+	if upper.State() != "walk" || upper.TickCount() != 5 || upper.Frame() != base.Frame() {
+		t.Fatalf("Expected %v got %v at tick %v", "walk", upper.State(), upper.TickCount())
+	}
+
+	if aura.TickCount() != 4 {
+		t.Fatalf("Expected %v got %v", 4, aura.TickCount())
+	}
+
+	layers.Override("upper", "attack")
+	layers.Crossfade("run", 4)
+
+	if upper.State() != "attack" || aura.State() != "run" || !layers.Overridden("upper") {
+		t.Fatalf("Expected %v got %v", "attack", upper.State())
+	}
+
+	if state, _, weight := base.Fade(); state != "walk" || weight != 1 {
+		t.Fatalf("Expected %v got %v with weight %v", "walk", state, weight)
+	}
+
+	tick(2)
+
+	if _, _, weight := base.Fade(); weight != 0.5 {
+		t.Fatalf("Expected %v got %v", 0.5, weight)
+	}
+
+	// the attack lasts 8 ticks, after which the layer follows the base
+	tick(6)
+
+	if upper.State() != "run" || layers.Overridden("upper") {
+		t.Fatalf("Expected %v got %v", "run", upper.State())
+	}
+
+	tick(1)
+
+	if upper.TickCount() != base.TickCount() {
+		t.Fatalf("Expected %v got %v", base.TickCount(), upper.TickCount())
+	}
+}
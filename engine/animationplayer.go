@@ -0,0 +1,372 @@
+package engine
+
+import "math"
+
+// AnimationSequence describes the frames of an animation state.
+type AnimationSequence struct {
+	// Frames is the number of frames in the state.
+	Frames int
+	// Fps is the default frame rate. Frames without
+	// a duration are not advanced if it is zero.
+	Fps int
+	// Durations optionally sets the duration
+	// of each frame in ticks, in frame order.
+	Durations []float64
+
+	Loop     bool
+	Playback Playback
+
+	// Events maps frame indices to the names
+	// of the events passed to OnFrame callbacks.
+	Events map[int][]string
+}
+
+// length returns the number of steps in a cycle of the sequence.
+func (s AnimationSequence) length() int {
+	n := s.Frames
+	if n < 1 {
+		return 1
+	}
+
+	if s.Playback == PlaybackPingPong && n > 1 {
+		// a non-looping ping-pong ends on the first frame,
+		// whereas a loop does not repeat it
+		if s.Loop {
+			return 2*n - 2
+		}
+
+		return 2*n - 1
+	}
+
+	return n
+}
+
+// frame returns the frame index of a step.
+func (s AnimationSequence) frame(step int) int {
+	n := s.Frames
+	if n < 1 {
+		return 0
+	}
+
+	switch s.Playback {
+	case PlaybackReverse:
+		return n - 1 - step
+
+	case PlaybackPingPong:
+		if step >= n {
+			return 2*n - 2 - step
+		}
+	}
+
+	return step
+}
+
+// duration returns the duration of a frame in ticks.
+func (s AnimationSequence) duration(frame int) float64 {
+	if frame < len(s.Durations) && s.Durations[frame] > 0 {
+		return s.Durations[frame]
+	}
+
+	if s.Fps <= 0 {
+		return math.Inf(1)
+	}
+
+	return 60 / float64(s.Fps)
+}
+
+// AnimationPlayer plays the AnimationSequence of an animation
+// state. Backends embed it to implement most of Animation,
+// tick it once per game tick from their Renderer's Tick,
+// and only read the frame returned by Frame when drawing.
+type AnimationPlayer struct {
+	state string
+	seq   AnimationSequence
+
+	step    int
+	elapsed float64
+	ticks   float64
+	entered bool
+	done    bool
+
+	speed  float64
+	paused bool
+
+	onFrame    func(AnimationFrame)
+	onLoop     func(string)
+	onComplete func(string)
+
+	leader Animation
+
+	fade                   *AnimationPlayer
+	fadeTicks, fadeElapsed float64
+}
+
+// NewAnimationPlayer returns an instantiated *AnimationPlayer.
+func NewAnimationPlayer() *AnimationPlayer {
+	return &AnimationPlayer{speed: 1}
+}
+
+// SetSequence sets the state and its sequence, and resets the player.
+func (p *AnimationPlayer) SetSequence(state string, seq AnimationSequence) {
+	p.state, p.seq = state, seq
+	p.Reset()
+}
+
+// CrossfadeSequence sets the state and its sequence like
+// SetSequence, fading out the previous state over a number of ticks.
+func (p *AnimationPlayer) CrossfadeSequence(state string, seq AnimationSequence, ticks int) {
+	prev := &AnimationPlayer{
+		state:   p.state,
+		seq:     p.seq,
+		step:    p.step,
+		elapsed: p.elapsed,
+		ticks:   p.ticks,
+		entered: true,
+		done:    p.done,
+		speed:   p.speed,
+	}
+
+	p.SetSequence(state, seq)
+
+	if ticks > 0 {
+		p.fade = prev
+		p.fadeTicks, p.fadeElapsed = float64(ticks), 0
+	}
+}
+
+// Fade returns the state and frame being faded out, and its
+// weight from 1 to 0. The weight is zero if not crossfading.
+func (p *AnimationPlayer) Fade() (state string, frame int, weight float64) {
+	if p.fade == nil {
+		return "", 0, 0
+	}
+
+	return p.fade.state, p.fade.Frame(), 1 - p.fadeElapsed/p.fadeTicks
+}
+
+// SyncTo implements Animation.
+func (p *AnimationPlayer) SyncTo(leader Animation) {
+	p.leader = leader
+}
+
+// Sequence returns the sequence of the current state.
+func (p *AnimationPlayer) Sequence() AnimationSequence {
+	return p.seq
+}
+
+// State implements Animation.
+func (p *AnimationPlayer) State() string {
+	return p.state
+}
+
+// SetTickCount implements Animation. The player is
+// advanced to the tick without calling callbacks.
+func (p *AnimationPlayer) SetTickCount(count int) {
+	p.Reset()
+	p.advance(float64(count), false)
+	p.entered = true
+}
+
+// TickCount implements Animation.
+func (p *AnimationPlayer) TickCount() int {
+	return int(p.ticks)
+}
+
+// Play implements Animation.
+func (p *AnimationPlayer) Play() {
+	p.paused = false
+}
+
+// Pause implements Animation.
+func (p *AnimationPlayer) Pause() {
+	p.paused = true
+}
+
+// Reset implements Animation.
+func (p *AnimationPlayer) Reset() {
+	p.step, p.elapsed, p.ticks = 0, 0, 0
+	p.entered, p.done = false, false
+	p.fade = nil
+}
+
+// SetSpeed implements Animation.
+// Negative speeds are treated as zero.
+func (p *AnimationPlayer) SetSpeed(speed float64) {
+	p.speed = math.Max(0, speed)
+}
+
+// Speed implements Animation.
+func (p *AnimationPlayer) Speed() float64 {
+	return p.speed
+}
+
+// Frame implements Animation.
+func (p *AnimationPlayer) Frame() int {
+	return p.seq.frame(p.step)
+}
+
+// FrameOffset returns the frame that would be shown after
+// advancing the player by a number of ticks at normal speed.
+func (p *AnimationPlayer) FrameOffset(ticks int) int {
+	if ticks <= 0 {
+		return p.Frame()
+	}
+
+	q := *p
+	q.advance(float64(ticks), false)
+
+	return q.Frame()
+}
+
+// Progress implements Animation.
+func (p *AnimationPlayer) Progress() float64 {
+	if p.done {
+		return 1
+	}
+
+	var total, elapsed float64
+	for i := 0; i < p.seq.length(); i++ {
+		d := p.seq.duration(p.seq.frame(i))
+		if i < p.step {
+			elapsed += d
+		}
+
+		total += d
+	}
+
+	if math.IsInf(total, 1) {
+		return 0
+	}
+
+	return math.Min(1, (elapsed+p.elapsed)/total)
+}
+
+// Done indicates whether a non-looping state has finished.
+func (p *AnimationPlayer) Done() bool {
+	return p.done
+}
+
+// OnFrame implements Animation.
+func (p *AnimationPlayer) OnFrame(fn func(AnimationFrame)) {
+	p.onFrame = fn
+}
+
+// OnLoop implements Animation.
+func (p *AnimationPlayer) OnLoop(fn func(string)) {
+	p.onLoop = fn
+}
+
+// OnComplete implements Animation.
+func (p *AnimationPlayer) OnComplete(fn func(string)) {
+	p.onComplete = fn
+}
+
+// Tick advances the player by one tick scaled by
+// its speed, and calls any callbacks on the way.
+// While following a leader in the same state, it is
+// instead advanced to the tick count of the leader,
+// lagging by a tick if ticked before the leader.
+func (p *AnimationPlayer) Tick() {
+	if p.paused {
+		return
+	}
+
+	delta := p.speed
+	if p.leader != nil && p.leader.State() == p.state {
+		target := float64(p.leader.TickCount())
+
+		// the leader restarted the state
+		if target < p.ticks {
+			fade, fadeElapsed := p.fade, p.fadeElapsed
+			p.Reset()
+			p.fade, p.fadeElapsed = fade, fadeElapsed
+		}
+
+		// join the leader without replaying the frames it has shown
+		if !p.entered {
+			p.advance(target-p.ticks, false)
+		}
+
+		delta = target - p.ticks
+	}
+
+	if !p.entered {
+		p.entered = true
+		p.enter()
+	}
+
+	if p.fade != nil {
+		p.fade.advance(delta, false)
+
+		if p.fadeElapsed += delta; p.fadeElapsed >= p.fadeTicks {
+			p.fade = nil
+		}
+	}
+
+	p.advance(delta, true)
+}
+
+func (p *AnimationPlayer) advance(ticks float64, notify bool) {
+	p.ticks += ticks
+	if p.done {
+		return
+	}
+
+	p.elapsed += ticks
+	for !p.done {
+		d := p.seq.duration(p.Frame())
+		if p.elapsed < d {
+			return
+		}
+
+		p.elapsed -= d
+		p.next(notify)
+	}
+}
+
+func (p *AnimationPlayer) next(notify bool) {
+	p.step++
+
+	if p.step >= p.seq.length() {
+		if !p.seq.Loop {
+			p.step = p.seq.length() - 1
+			p.elapsed = 0
+			p.done = true
+
+			if notify && p.onComplete != nil {
+				p.onComplete(p.state)
+			}
+
+			return
+		}
+
+		p.step = 0
+
+		if notify && p.onLoop != nil {
+			p.onLoop(p.state)
+
+			// the callback changed the state
+			if !p.entered {
+				return
+			}
+		}
+	}
+
+	if notify {
+		p.enter()
+	}
+}
+
+// enter calls the OnFrame callback for the current frame.
+func (p *AnimationPlayer) enter() {
+	if p.onFrame == nil {
+		return
+	}
+
+	frame := p.Frame()
+	p.onFrame(AnimationFrame{
+		State:  p.state,
+		Frame:  frame,
+		Events: p.seq.Events[frame],
+	})
+}
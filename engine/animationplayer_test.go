@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnimationPlayerEvents(t *testing.T) {
+	p := NewAnimationPlayer()
+
+	var (
+		frames []int
+		events []string
+		loops  int
+	)
+
+	p.OnFrame(func(f AnimationFrame) {
+		frames = append(frames, f.Frame)
+		events = append(events, f.Events...)
+	})
+	p.OnLoop(func(string) { loops++ })
+
+	// the first frame lasts 4 ticks, the others 2
+	p.SetSequence("walk", AnimationSequence{
+		Frames:    3,
+		Fps:       30,
+		Durations: []float64{4},
+		Loop:      true,
+		Events:    map[int][]string{1: {"footstep"}},
+	})
+
+	for i := 0; i < 8; i++ {
+		p.Tick()
+	}
+
+	if !reflect.DeepEqual(frames, []int{0, 1, 2, 0}) {
+		t.Fatalf("Expected %v got %v", []int{0, 1, 2, 0}, frames)
+	}
+
+	if !reflect.DeepEqual(events, []string{"footstep"}) || loops != 1 {
+		t.Fatalf("Expected %v got %v with %v loops", []string{"footstep"}, events, loops)
+	}
+
+	p.SetTickCount(5)
+	if p.Frame() != 1 || p.Progress() != 0.625 {
+		t.Fatalf("Expected %v got %v with progress %v", 1, p.Frame(), p.Progress())
+	}
+
+	if f := p.FrameOffset(2); f != 2 {
+		t.Fatalf("Expected %v got %v", 2, f)
+	}
+}
+
+func TestAnimationPlayerPlayback(t *testing.T) {
+	p := NewAnimationPlayer()
+
+	var (
+		frames    []int
+		completed []string
+	)
+
+	p.OnFrame(func(f AnimationFrame) { frames = append(frames, f.Frame) })
+	p.OnComplete(func(state string) { completed = append(completed, state) })
+
+	p.SetSequence("bounce", AnimationSequence{
+		Frames:   3,
+		Fps:      60,
+		Playback: PlaybackPingPong,
+	})
+
+	for i := 0; i < 10; i++ {
+		p.Tick()
+	}
+
+	if !reflect.DeepEqual(frames, []int{0, 1, 2, 1, 0}) {
+		t.Fatalf("Expected %v got %v", []int{0, 1, 2, 1, 0}, frames)
+	}
+
+	if len(completed) != 1 || !p.Done() || p.Progress() != 1 {
+		t.Fatalf("Expected completion got %v", completed)
+	}
+
+	frames = nil
+	p.SetSpeed(2)
+	p.SetSequence("rewind", AnimationSequence{
+		Frames:   3,
+		Fps:      60,
+		Loop:     true,
+		Playback: PlaybackReverse,
+	})
+	p.Tick()
+
+	if !reflect.DeepEqual(frames, []int{2, 1, 0}) || p.TickCount() != 2 {
+		t.Fatalf("Expected %v got %v after %v ticks", []int{2, 1, 0}, frames, p.TickCount())
+	}
+
+	p.Pause()
+	p.Tick()
+
+	if p.TickCount() != 2 {
+		t.Fatalf("Expected %v got %v", 2, p.TickCount())
+	}
+}
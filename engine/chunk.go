@@ -0,0 +1,289 @@
+package engine
+
+import (
+	"container/list"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+)
+
+// Chunk is a square region of tile data
+// belonging to a chunked Tilemap.
+type Chunk struct {
+	// Data contains values representing tiles,
+	// indexed as Data[z][y][x] relative to the chunk origin.
+	Data [2][][]int
+}
+
+// NewChunk returns an empty *Chunk of a given size.
+func NewChunk(size int) *Chunk {
+	c := new(Chunk)
+	for z := range c.Data {
+		c.Data[z] = make([][]int, size)
+		for y := range c.Data[z] {
+			c.Data[z][y] = make([]int, size)
+		}
+	}
+
+	return c
+}
+
+// checkSize returns an error if the chunk is not size by size tiles.
+func (c *Chunk) checkSize(size int) error {
+	for z := range c.Data {
+		if len(c.Data[z]) != size {
+			return fmt.Errorf("chunk has %d rows, expected %d", len(c.Data[z]), size)
+		}
+
+		for y, row := range c.Data[z] {
+			if len(row) != size {
+				return fmt.Errorf("chunk row %d has %d tiles, expected %d", y, len(row), size)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ChunkProvider supplies chunks to a chunked Tilemap.
+type ChunkProvider interface {
+	// Bounds returns the bounds of the world in tiles.
+	Bounds() image.Rectangle
+
+	// LoadChunk returns the chunk at the given chunk coordinates.
+	// The returned chunk must be size by size tiles.
+	LoadChunk(cx, cy, size int) (*Chunk, error)
+}
+
+// ChunkSaver is an optional interface for a ChunkProvider
+// that persists modified chunks when they are evicted.
+type ChunkSaver interface {
+	SaveChunk(cx, cy int, c *Chunk) error
+}
+
+type chunkEntry struct {
+	key   image.Point
+	chunk *Chunk
+	dirty bool
+}
+
+// chunkCache is an LRU cache of loaded chunks.
+type chunkCache struct {
+	provider ChunkProvider
+	size     int
+	capacity int
+
+	entries map[image.Point]*list.Element
+	lru     *list.List
+
+	err error
+}
+
+func newChunkCache(provider ChunkProvider, size, capacity int) *chunkCache {
+	return &chunkCache{
+		provider: provider,
+		size:     size,
+		capacity: capacity,
+		entries:  make(map[image.Point]*list.Element, capacity),
+		lru:      list.New(),
+	}
+}
+
+// key returns the chunk coordinates and chunk-relative
+// coordinates of a tile.
+func (cc *chunkCache) key(x, y int) (image.Point, int, int) {
+	cx, cy := floorDiv(x, cc.size), floorDiv(y, cc.size)
+	return image.Pt(cx, cy), x - cx*cc.size, y - cy*cc.size
+}
+
+// entry returns a loaded chunk, loading it if required.
+// A nil entry is returned if the chunk failed to load.
+func (cc *chunkCache) entry(key image.Point) *chunkEntry {
+	if el, ok := cc.entries[key]; ok {
+		cc.lru.MoveToFront(el)
+		return el.Value.(*chunkEntry)
+	}
+
+	chunk, err := cc.provider.LoadChunk(key.X, key.Y, cc.size)
+	if err == nil && chunk == nil {
+		err = errors.New("nil chunk")
+	}
+	if err == nil {
+		err = chunk.checkSize(cc.size)
+	}
+	if err != nil {
+		cc.err = fmt.Errorf("failed to load chunk %d %d: %w", key.X, key.Y, err)
+		return nil
+	}
+
+	e := &chunkEntry{
+		key:   key,
+		chunk: chunk,
+	}
+	cc.entries[key] = cc.lru.PushFront(e)
+
+	for cc.lru.Len() > cc.capacity {
+		cc.evict(cc.lru.Back())
+	}
+
+	return e
+}
+
+func (cc *chunkCache) evict(el *list.Element) {
+	e := el.Value.(*chunkEntry)
+
+	if saver, ok := cc.provider.(ChunkSaver); ok && e.dirty {
+		if err := saver.SaveChunk(e.key.X, e.key.Y, e.chunk); err != nil {
+			cc.err = fmt.Errorf("failed to save chunk %d %d: %w", e.key.X, e.key.Y, err)
+		}
+	}
+
+	cc.lru.Remove(el)
+	delete(cc.entries, e.key)
+}
+
+// reset discards all loaded chunks without saving them.
+func (cc *chunkCache) reset() {
+	cc.entries = make(map[image.Point]*list.Element, cc.capacity)
+	cc.lru.Init()
+	cc.err = nil
+}
+
+// takeErr returns and clears the most recent error.
+func (cc *chunkCache) takeErr() error {
+	err := cc.err
+	cc.err = nil
+
+	return err
+}
+
+func (cc *chunkCache) get(x, y, z int) int {
+	key, lx, ly := cc.key(x, y)
+
+	e := cc.entry(key)
+	if e == nil {
+		return 0
+	}
+
+	return e.chunk.Data[z][ly][lx]
+}
+
+func (cc *chunkCache) set(x, y, z, tile int) {
+	key, lx, ly := cc.key(x, y)
+
+	e := cc.entry(key)
+	if e == nil {
+		return
+	}
+
+	e.chunk.Data[z][ly][lx] = tile
+	e.dirty = true
+}
+
+// flush saves all modified chunks without evicting them.
+func (cc *chunkCache) flush() error {
+	saver, ok := cc.provider.(ChunkSaver)
+	if !ok {
+		return nil
+	}
+
+	for el := cc.lru.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*chunkEntry)
+		if !e.dirty {
+			continue
+		}
+
+		if err := saver.SaveChunk(e.key.X, e.key.Y, e.chunk); err != nil {
+			return fmt.Errorf("failed to save chunk %d %d: %w", e.key.X, e.key.Y, err)
+		}
+		e.dirty = false
+	}
+
+	return nil
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+
+	return q
+}
+
+// FileChunkProvider is a ChunkProvider that stores
+// chunks as files within a directory. Chunks without
+// a file are requested from an optional fallback provider,
+// such as a map generator, or are otherwise left empty.
+type FileChunkProvider struct {
+	dir      string
+	bounds   image.Rectangle
+	fallback ChunkProvider
+}
+
+// NewFileChunkProvider returns a *FileChunkProvider for a given directory
+// and world bounds. The fallback provider is optional.
+func NewFileChunkProvider(dir string, bounds image.Rectangle, fallback ChunkProvider) *FileChunkProvider {
+	return &FileChunkProvider{
+		dir:      dir,
+		bounds:   bounds,
+		fallback: fallback,
+	}
+}
+
+// Bounds implements ChunkProvider.
+func (p *FileChunkProvider) Bounds() image.Rectangle {
+	return p.bounds
+}
+
+// LoadChunk implements ChunkProvider.
+func (p *FileChunkProvider) LoadChunk(cx, cy, size int) (*Chunk, error) {
+	f, err := os.Open(p.path(cx, cy))
+	if errors.Is(err, os.ErrNotExist) {
+		if p.fallback != nil {
+			return p.fallback.LoadChunk(cx, cy, size)
+		}
+		return NewChunk(size), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := new(Chunk)
+	if err := gob.NewDecoder(f).Decode(c); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkSize(size); err != nil {
+		return nil, fmt.Errorf("%s: %w", p.path(cx, cy), err)
+	}
+
+	return c, nil
+}
+
+// SaveChunk implements ChunkSaver.
+func (p *FileChunkProvider) SaveChunk(cx, cy int, c *Chunk) error {
+	if err := os.MkdirAll(p.dir, 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.Create(p.path(cx, cy))
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(f).Encode(c); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (p *FileChunkProvider) path(cx, cy int) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%d_%d.chunk", cx, cy))
+}
@@ -22,7 +22,7 @@ func (c *Collider) Resolve(src, dst Vec2) Vec2 {
 	ix++
 	iy++
 
-	if c.m.GetTileValue(ix, iy, 1) == 0 {
+	if !c.m.IsSolid(ix, iy) {
 		return dst
 	}
 
@@ -61,7 +61,7 @@ func (c *Collider) Resolve(src, dst Vec2) Vec2 {
 	}
 
 	// check secondary collision
-	if c.m.GetTileValue(nix, niy, 1) != 0 {
+	if c.m.IsSolid(nix, niy) {
 		tileX, tileY = c.m.IndexToIso(nix, niy)
 		centerX, centerY = tileX, tileY-float64(c.m.TileWidth-c.m.TileWidth/4)
 
@@ -113,7 +113,7 @@ func (c *Collider) Resolve(src, dst Vec2) Vec2 {
 
 	// FIXME
 	// check tertiary collison
-	if c.m.GetTileValue(nix, niy, 1) != 0 {
+	if c.m.IsSolid(nix, niy) {
 		tileX, tileY = c.m.IndexToIso(nix, niy)
 		centerX, centerY = tileX, tileY-float64(c.m.TileWidth-c.m.TileWidth/4)
 
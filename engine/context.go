@@ -1,9 +1,6 @@
 package engine
 
-import (
-	"image"
-	"math"
-)
+import "math"
 
 // Context is a rendering context.
 type Context struct {
@@ -11,33 +8,238 @@ type Context struct {
 	*Collider
 	*Tilemap
 
+	// Physics optionally resolves collisions for
+	// entities with a Body, in place of the Collider.
+	Physics *PhysicsWorld
+
+	// Events dispatches gameplay events. Published
+	// events are dispatched at the end of each Tick.
+	Events *EventBus
+
+	options ContextOptions
+
 	partitionMap *PartitionMap
+	hits         *hitTracker
+
+	focus []Focus
+	keys  map[[2]int]bool
+	ticks int
 
 	entitySwap []Entity
+	removeSwap []Entity
+}
+
+// ContextOptions configures the partitioning and
+// simulation regions of a Context.
+type ContextOptions struct {
+	// PartitionSize is the width and height of each
+	// partition in pixels. Defaults to 250.
+	PartitionSize int
+	// BucketCount is the initial partition bucket count.
+	// Defaults to 1000.
+	BucketCount int
+	// SimulationRadius is the number of partitions around
+	// each focus point that are updated every tick.
+	// Defaults to the number of partitions covering the viewport.
+	SimulationRadius int
+	// SleepRadius is the number of partitions beyond the
+	// simulation radius that are updated at a reduced rate.
+	// Sleeping regions are disabled by default.
+	SleepRadius int
+	// SleepInterval is the number of ticks between updates
+	// of sleeping partitions. Updates are staggered between
+	// partitions to spread the cost. Defaults to 10.
+	SleepInterval int
+}
+
+// Focus is a point of interest around which entities are simulated.
+type Focus interface {
+	Position() Vec2
 }
 
 // NewContext creates a Context with the given Renderer and Collider.
 func NewContext(renderer Renderer, collider *Collider, tilemap *Tilemap) *Context {
+	return NewContextWithOptions(renderer, collider, tilemap, ContextOptions{})
+}
+
+// NewContextWithOptions creates a Context with the given
+// Renderer, Collider and partitioning options.
+func NewContextWithOptions(
+	renderer Renderer,
+	collider *Collider,
+	tilemap *Tilemap,
+	options ContextOptions,
+) *Context {
+	if options.PartitionSize <= 0 {
+		options.PartitionSize = 250
+	}
+
+	if options.BucketCount <= 0 {
+		options.BucketCount = 1000
+	}
+
+	if options.SleepInterval <= 0 {
+		options.SleepInterval = 10
+	}
+
 	ctx := &Context{
-		Renderer: renderer,
-		Collider: collider,
-		Tilemap:  tilemap,
+		Renderer:     renderer,
+		Collider:     collider,
+		Tilemap:      tilemap,
+		Events:       NewEventBus(),
+		options:      options,
+		partitionMap: NewPartitionMap(options.PartitionSize, options.BucketCount),
+		hits:         newHitTracker(),
+		keys:         make(map[[2]int]bool),
 	}
 
-	// TODO configurable values
-	ctx.partitionMap = NewPartitionMap(250, 1000)
+	ctx.partitionMap.OnDispose(func(entry PartitionEntry) {
+		e := entry.(Entity)
+		ctx.detach(e)
+
+		if hook, ok := e.(DisposedHook); ok {
+			hook.OnDisposed(ctx)
+		}
+	})
 
 	return ctx
 }
 
+// AddedHook is implemented by entities
+// notified when they are added to a Context.
+type AddedHook interface {
+	OnAdded(*Context)
+}
+
+// RemovedHook is implemented by entities notified
+// when they are removed from a Context by RemoveEntity.
+type RemovedHook interface {
+	OnRemoved(*Context)
+}
+
+// DisposedHook is implemented by entities notified when a
+// Context drops them after they are disposed. Disposed entities
+// are dropped the next time their partition is updated.
+type DisposedHook interface {
+	OnDisposed(*Context)
+}
+
+// AddFocus adds focus points to the Context. Entities around
+// every focus point are simulated, in addition to those
+// around the viewport.
+func (c *Context) AddFocus(focus ...Focus) {
+	c.focus = append(c.focus, focus...)
+}
+
+// RemoveFocus removes a focus point from the Context.
+func (c *Context) RemoveFocus(focus Focus) {
+	for i, f := range c.focus {
+		if f != focus {
+			continue
+		}
+
+		copy(c.focus[i:], c.focus[i+1:])
+		c.focus[len(c.focus)-1] = nil
+		c.focus = c.focus[:len(c.focus)-1]
+
+		return
+	}
+}
+
 // AddEntity adds Entities to the Context.
+// Entities are added at the start of the next Tick.
+// It panics if an Entity is not a pointer.
 func (c *Context) AddEntity(entities ...Entity) {
+	for _, e := range entities {
+		checkEntity(e)
+	}
+
 	c.entitySwap = append(c.entitySwap, entities...)
 }
 
+// RemoveEntity removes Entities and their children from the
+// Context without disposing them. Their images are removed
+// from the Renderer, and their bodies from the PhysicsWorld.
+// Entities are removed at the start of the next Tick,
+// after any pending additions.
+func (c *Context) RemoveEntity(entities ...Entity) {
+	c.removeSwap = append(c.removeSwap, entities...)
+}
+
+// detach removes the images, body and hit contacts of an entity.
+func (c *Context) detach(e Entity) {
+	c.RemoveImage(expandImages(e.Images())...)
+	c.hits.remove(e)
+
+	if p, ok := e.(Physical); ok {
+		if body := p.Body(); body != nil && body.World() != nil {
+			body.World().Remove(body)
+		}
+	}
+
+	if n, ok := e.(Node); ok && n.node().ctx == c {
+		n.node().ctx = nil
+	}
+}
+
 // GetEntities gets the Context's Entities.
 func (c *Context) GetEntities(class string) []Entity {
-	entries := c.partitionMap.Class(class)
+	return toEntities(c.partitionMap.Class(class))
+}
+
+// EntityFilter reports whether an Entity should
+// be included in the results of a query.
+type EntityFilter func(Entity) bool
+
+// ClassFilter returns an EntityFilter that
+// includes entities of any of the given classes.
+func ClassFilter(classes ...string) EntityFilter {
+	return func(e Entity) bool {
+		for _, class := range classes {
+			if e.Class() == class {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// QueryRadius returns all entities positioned within a radius
+// of a position. A nil filter includes every entity.
+func (c *Context) QueryRadius(pos Vec2, radius float64, filter EntityFilter) []Entity {
+	return toEntities(c.partitionMap.QueryRadius(pos, radius, entryFilter(filter)))
+}
+
+// QueryRect returns all entities positioned within a region.
+// A nil filter includes every entity.
+func (c *Context) QueryRect(r AABB, filter EntityFilter) []Entity {
+	return toEntities(c.partitionMap.QueryRect(r, entryFilter(filter)))
+}
+
+// Nearest returns up to n entities closest to a position within
+// a maximum distance, ordered by distance. A nil filter
+// includes every entity.
+func (c *Context) Nearest(pos Vec2, n int, maxDist float64, filter EntityFilter) []Entity {
+	return toEntities(c.partitionMap.Nearest(pos, n, maxDist, entryFilter(filter)))
+}
+
+// Raycast returns all entities within a radius of a ray cast
+// from an origin along an angle in radians, up to a maximum
+// distance, ordered by distance along the ray. The Entry of
+// each RayHit is an Entity. A nil filter includes every entity.
+func (c *Context) Raycast(origin Vec2, angle, dist, radius float64, filter EntityFilter) []RayHit {
+	return c.partitionMap.Raycast(origin, angle, dist, radius, entryFilter(filter))
+}
+
+func entryFilter(filter EntityFilter) EntryFilter {
+	return func(e PartitionEntry) bool {
+		entity, ok := e.(Entity)
+		return ok && (filter == nil || filter(entity))
+	}
+}
+
+func toEntities(entries []PartitionEntry) []Entity {
 	entities := make([]Entity, len(entries))
 	for i, entry := range entries {
 		entities[i] = entry.(Entity)
@@ -47,37 +249,158 @@ func (c *Context) GetEntities(class string) []Entity {
 
 // Tick updates the Context's internal state.
 func (c *Context) Tick() {
-	for i, e := range c.entitySwap {
+	c.addPending()
+	c.removePending()
+
+	c.partitionMap.TickKeys(c.activeKeys(), c.updateEntities)
+	c.ticks++
+
+	c.Events.Flush()
+}
+
+// addPending adds the entities queued by AddEntity.
+func (c *Context) addPending() {
+	// children of new entities are appended while iterating
+	for i := 0; i < len(c.entitySwap); i++ {
+		e := c.entitySwap[i]
+
+		if n, ok := e.(Node); ok {
+			n.node().ctx = c
+
+			for _, child := range n.node().children {
+				if child.node().ctx != c {
+					child.node().ctx = c
+					c.entitySwap = append(c.entitySwap, child)
+				}
+			}
+		}
+
 		if c.Collider != nil {
 			e.SetCollider(c.Collider)
 		}
 
-		c.AddImage(e.Images()...)
+		if p, ok := e.(Physical); ok && c.Physics != nil {
+			if body := p.Body(); body != nil && body.World() == nil {
+				c.Physics.Add(body, e.Position())
+			}
+		}
+
+		c.AddImage(expandImages(e.Images())...)
 		c.partitionMap.Add(e)
 
+		if hook, ok := e.(AddedHook); ok {
+			hook.OnAdded(c)
+		}
+
 		c.entitySwap[i] = nil
 	}
 
 	c.entitySwap = c.entitySwap[:0]
+}
+
+// removePending removes the entities queued by RemoveEntity.
+func (c *Context) removePending() {
+	// children of removed entities are appended while iterating
+	for i := 0; i < len(c.removeSwap); i++ {
+		e := c.removeSwap[i]
+
+		if n, ok := e.(Node); ok {
+			for _, child := range n.node().children {
+				c.removeSwap = append(c.removeSwap, child)
+			}
+		}
+
+		if c.partitionMap.Remove(e) {
+			c.detach(e)
+
+			if hook, ok := e.(RemovedHook); ok {
+				hook.OnRemoved(c)
+			}
+		}
+
+		c.removeSwap[i] = nil
+	}
+
+	c.removeSwap = c.removeSwap[:0]
+}
+
+// activeKeys returns the keys of all partitions
+// to update during the current tick.
+func (c *Context) activeKeys() [][2]int {
+	size := float64(c.options.PartitionSize)
 
 	vp := c.Viewport()
-	pos := Vec2{
-		X: float64(vp.Min.X),
-		Y: float64(vp.Min.Y),
+	center := Vec2{
+		X: float64(vp.Min.X+vp.Max.X) / 2,
+		Y: float64(vp.Min.Y+vp.Max.Y) / 2,
 	}
 
-	// cell dist to load from partition map
-	pcells := int(math.Max(
-		float64(vp.Dx()),
-		float64(vp.Dy()),
-	))/250 - 1
+	// partitions covering the viewport
+	radius := c.options.SimulationRadius
+	if radius <= 0 {
+		radius = int(math.Ceil(math.Max(
+			float64(vp.Dx()),
+			float64(vp.Dy()),
+		)/2/size)) + 1
+	}
+
+	sleep := c.options.SleepRadius
+
+	// true for awake partitions, false for sleeping ones
+	for key := range c.keys {
+		delete(c.keys, key)
+	}
+
+	mark := func(pos Vec2) {
+		k := c.partitionMap.positionToKey(pos)
+
+		for x := k[0] - radius - sleep; x <= k[0]+radius+sleep; x++ {
+			for y := k[1] - radius - sleep; y <= k[1]+radius+sleep; y++ {
+				key := [2]int{x, y}
+				awake := abs(x-k[0]) <= radius && abs(y-k[1]) <= radius
+
+				if awake || !c.keys[key] {
+					c.keys[key] = awake
+				}
+			}
+		}
+	}
 
-	c.partitionMap.Tick(pos, pcells, c.updateEntities)
+	mark(center)
+	for _, f := range c.focus {
+		mark(f.Position())
+	}
+
+	keys := make([][2]int, 0, len(c.keys))
+	for key, awake := range c.keys {
+		if !awake {
+			// stagger sleeping partitions across the interval
+			phase := (key[0]*31 + key[1]) % c.options.SleepInterval
+			if phase < 0 {
+				phase += c.options.SleepInterval
+			}
+
+			if c.ticks%c.options.SleepInterval != phase {
+				continue
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
 }
 
 func (c *Context) updateEntities(entries []PartitionEntry) {
 	for _, entry := range entries {
-		entry.(Entity).Tick()
+		e := entry.(Entity)
+		e.Tick()
+
+		for _, img := range e.Images() {
+			if g, ok := img.(ImageGroup); ok {
+				g.Tick()
+			}
+		}
 
 		hitbox, ok := entry.(Hitbox)
 		if !ok {
@@ -92,27 +415,15 @@ func (c *Context) updateEntities(entries []PartitionEntry) {
 					continue
 				}
 
-				sourcePos := hitbox.Position()
-				sourceBounds := hitbox.HitBounds()
-				targetPos := targetHitbox.Position()
-				targetBounds := targetHitbox.HitBounds()
-
-				if image.Rect(
-					int(sourcePos.X)+sourceBounds.Min.X,
-					int(sourcePos.Y)+sourceBounds.Min.Y,
-					int(sourcePos.X)+sourceBounds.Max.X,
-					int(sourcePos.Y)+sourceBounds.Max.Y,
-				).Overlaps(
-					image.Rect(
-						int(targetPos.X)+targetBounds.Min.X,
-						int(targetPos.Y)+targetBounds.Min.Y,
-						int(targetPos.X)+targetBounds.Max.X,
-						int(targetPos.Y)+targetBounds.Max.Y,
-					),
-				) {
+				sourceBounds := rectBounds(hitbox.HitBounds(), hitbox.Position())
+				targetBounds := rectBounds(targetHitbox.HitBounds(), targetHitbox.Position())
+
+				if sourceBounds.Overlaps(targetBounds) {
 					hitbox.Hit(targetEntry.(Entity))
 				}
 			}
 		}
 	}
+
+	c.hits.update(entries)
 }
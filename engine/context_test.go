@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+type testRenderer struct {
+	viewport image.Rectangle
+	images   int
+}
+
+func (r *testRenderer) AddImage(images ...Image)    { r.images += len(images) }
+func (r *testRenderer) RemoveImage(images ...Image) { r.images -= len(images) }
+func (r *testRenderer) SetCamera(*Camera)           {}
+func (r *testRenderer) ScreenToWorld(v Vec2) Vec2   { return v }
+func (r *testRenderer) SetViewport(int, int)        {}
+func (r *testRenderer) Viewport() image.Rectangle   { return r.viewport }
+func (r *testRenderer) Tick()                       {}
+
+type testTickEntity struct {
+	CoreEntity
+	ticks int
+}
+
+func (e *testTickEntity) Tick() {
+	e.ticks++
+	e.CoreEntity.Tick()
+}
+
+func (e *testTickEntity) Class() string {
+	return "test"
+}
+
+func TestContextFocus(t *testing.T) {
+
+	ctx := NewContextWithOptions(
+		&testRenderer{viewport: image.Rect(0, 0, 200, 200)},
+		nil,
+		nil,
+		ContextOptions{
+			PartitionSize:    100,
+			SimulationRadius: 1,
+			SleepRadius:      2,
+			SleepInterval:    4,
+		},
+	)
+
+	town := &testTickEntity{CoreEntity: CoreEntity{Vec2: Vec2{X: 5000, Y: 5000}}}
+	ctx.AddFocus(town)
+
+	onscreen := &testTickEntity{CoreEntity: CoreEntity{Vec2: Vec2{X: 150, Y: 150}}}
+	sleeping := &testTickEntity{CoreEntity: CoreEntity{Vec2: Vec2{X: 450, Y: 150}}}
+	inactive := &testTickEntity{CoreEntity: CoreEntity{Vec2: Vec2{X: 2000, Y: 150}}}
+
+	ctx.AddEntity(town, onscreen, sleeping, inactive)
+
+	for i := 0; i < 8; i++ {
+		ctx.Tick()
+	}
+
+	if town.ticks != 8 {
+		t.Fatalf("Expected 8 ticks got %d", town.ticks)
+	}
+
+	if onscreen.ticks != 8 {
+		t.Fatalf("Expected 8 ticks got %d", onscreen.ticks)
+	}
+
+	if sleeping.ticks != 2 {
+		t.Fatalf("Expected 2 ticks got %d", sleeping.ticks)
+	}
+
+	if inactive.ticks != 0 {
+		t.Fatalf("Expected 0 ticks got %d", inactive.ticks)
+	}
+}
+
+type testHookEntity struct {
+	testNode
+	hooks []string
+}
+
+func (e *testHookEntity) OnAdded(*Context)    { e.hooks = append(e.hooks, "added") }
+func (e *testHookEntity) OnRemoved(*Context)  { e.hooks = append(e.hooks, "removed") }
+func (e *testHookEntity) OnDisposed(*Context) { e.hooks = append(e.hooks, "disposed") }
+
+type testImage struct {
+	Image
+	disposed bool
+}
+
+func (i *testImage) Translate(float64, float64) {}
+func (i *testImage) Dispose()                   { i.disposed = true }
+
+func TestContextLifecycle(t *testing.T) {
+
+	r := &testRenderer{viewport: image.Rect(0, 0, 200, 200)}
+	ctx := NewContext(r, nil, nil)
+
+	removed := &testHookEntity{}
+	removed.AddImage(new(testImage))
+
+	disposed := &testHookEntity{}
+	disposed.AddImage(new(testImage))
+
+	ctx.AddEntity(removed, disposed)
+	ctx.Tick()
+
+	if r.images != 2 {
+		t.Fatalf("Expected 2 images got %d", r.images)
+	}
+
+	ctx.RemoveEntity(removed)
+	disposed.Dispose()
+	ctx.Tick()
+
+	if r.images != 0 {
+		t.Fatalf("Expected 0 images got %d", r.images)
+	}
+
+	if n := len(ctx.QueryRadius(Vec2{}, 100, nil)); n != 0 {
+		t.Fatalf("Expected 0 entities got %d", n)
+	}
+
+	expected := []string{"added", "removed"}
+	if !reflect.DeepEqual(expected, removed.hooks) {
+		t.Fatalf("Expected %v got %v", expected, removed.hooks)
+	}
+
+	expected = []string{"added", "disposed"}
+	if !reflect.DeepEqual(expected, disposed.hooks) {
+		t.Fatalf("Expected %v got %v", expected, disposed.hooks)
+	}
+
+	if removed.IsDisposed() {
+		t.Fatal("Expected removed entity not to be disposed.")
+	}
+}
+
+type testImageGroup struct {
+	testImage
+	parts []Image
+}
+
+func (g *testImageGroup) Parts() []Image { return g.parts }
+func (g *testImageGroup) Tick()          {}
+
+func TestExpandImages(t *testing.T) {
+	a, b := &testImage{}, &testImage{}
+
+	for _, test := range []struct {
+		input    []Image
+		expected []Image
+	}{
+		{[]Image{a, b}, []Image{a, b}},
+		{[]Image{&testImageGroup{parts: []Image{a}}, b}, []Image{a, b}},
+		{[]Image{a, &testImageGroup{parts: []Image{b}}}, []Image{a, b}},
+		{[]Image{&testImageGroup{}}, []Image{}},
+		{[]Image{&testImageGroup{}, a}, []Image{a}},
+	} {
+		actual := expandImages(test.input)
+		if len(actual) != len(test.expected) {
+			t.Fatalf("Input %v, expected %v got %v", test.input, test.expected, actual)
+		}
+
+		for i := range actual {
+			if actual[i] != test.expected[i] {
+				t.Fatalf("Input %v, expected %v got %v", test.input, test.expected, actual)
+			}
+		}
+	}
+}
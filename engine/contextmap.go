@@ -1,19 +1,22 @@
 package engine
 
-import (
-	"image"
-	"math"
-)
+import "math"
 
 // ContextMap handles context aware
 // steering behaviors for in-game AI.
+// Behaviors write to separate interest and danger maps
+// of discrete directions. Directions with too much danger
+// are masked, and the direction of highest remaining
+// interest is selected.
 type ContextMap struct {
-	arc       float64
-	cmap, buf []float64
+	// DangerThreshold is how much more danger than the safest
+	// direction a direction may have before it is masked.
+	DangerThreshold float64
 
-	approachBehavior *ContextMapBehavior
-	avoidBehavior    *ContextMapBehavior
-	wallBehavior     *ContextMapBehavior
+	arc                   float64
+	interest, danger, buf []float64
+
+	behaviors []SteeringBehavior
 
 	tmap *Tilemap
 }
@@ -31,112 +34,238 @@ type ContextMapBehavior struct {
 // to values calculated by a ContextMapBehavior.
 type CMapModFunc func([]float64)
 
+// SteeringInput is the input of a ContextMap for a single update.
+type SteeringInput struct {
+	// Origin is the position of the agent.
+	Origin Vec2
+	// Heading is the current heading of the agent in radians.
+	// It is selected when there is no interest in any direction.
+	Heading float64
+
+	// Excite are positions the agent is attracted to.
+	Excite []Vec2
+	// Inhibit are positions the agent is repelled by.
+	Inhibit []Vec2
+
+	// Allies are nearby agents of the same group.
+	Allies []SteeringAgent
+	// Leader is an optional agent to stay near.
+	Leader *SteeringAgent
+}
+
+// SteeringAgent is the position and heading
+// of another agent, used as a SteeringInput.
+type SteeringAgent struct {
+	Position Vec2
+	Heading  float64
+}
+
+// SteeringBehavior writes to the interest
+// and danger maps of a ContextMap.
+type SteeringBehavior interface {
+	Steer(*ContextMap, *SteeringInput)
+}
+
+// SteeringFunc is a function implementing SteeringBehavior.
+type SteeringFunc func(*ContextMap, *SteeringInput)
+
+// Steer implements SteeringBehavior.
+func (f SteeringFunc) Steer(cm *ContextMap, in *SteeringInput) {
+	f(cm, in)
+}
+
 // NewContextMap returns an instantiated *ContextMap.
 // The resolution indicates how many discrete directions will be calculated.
 // The tilemap is only required if there is a corresponding wallBehavior.
-// All behavior parameters are optional.
+// All behavior parameters are optional, and are added as
+// SteerApproach, SteerAvoid and SteerWalls behaviors.
 func NewContextMap(
 	resolution int,
 	tmap *Tilemap,
 	approachBehavior, avoidBehavior, wallBehavior *ContextMapBehavior,
 ) *ContextMap {
-	return &ContextMap{
-		arc:              (math.Pi * 2) / float64(resolution),
-		cmap:             make([]float64, resolution),
-		buf:              make([]float64, resolution),
-		tmap:             tmap,
-		approachBehavior: approachBehavior,
-		avoidBehavior:    avoidBehavior,
-		wallBehavior:     wallBehavior,
-	}
-}
-
-// Angle returns a selected angle to move based on specified inputs.
-func (cm *ContextMap) Angle(origin Vec2, excite, inhibit []Vec2) float64 {
-
-	if cm.approachBehavior != nil {
-		for _, v := range excite {
-			cm.apply(
-				origin, v, false,
-				cm.approachBehavior,
-			)
-		}
+	cm := &ContextMap{
+		DangerThreshold: 0.1,
+		arc:             (math.Pi * 2) / float64(resolution),
+		interest:        make([]float64, resolution),
+		danger:          make([]float64, resolution),
+		buf:             make([]float64, resolution),
+		tmap:            tmap,
 	}
 
-	if cm.avoidBehavior != nil {
-		for _, v := range inhibit {
-			cm.apply(
-				origin, v, true,
-				cm.avoidBehavior,
-			)
-		}
+	if approachBehavior != nil {
+		cm.AddBehavior(&SteerApproach{*approachBehavior})
 	}
 
-	if cm.wallBehavior != nil {
-		x, y := cm.tmap.IsoToIndex(origin.X, origin.Y)
+	if avoidBehavior != nil {
+		cm.AddBehavior(&SteerAvoid{*avoidBehavior})
+	}
+
+	if wallBehavior != nil {
+		cm.AddBehavior(&SteerWalls{*wallBehavior})
+	}
+
+	return cm
+}
+
+// AddBehavior adds steering behaviors to the ContextMap.
+// Behaviors are applied in the order they were added.
+func (cm *ContextMap) AddBehavior(behaviors ...SteeringBehavior) {
+	cm.behaviors = append(cm.behaviors, behaviors...)
+}
+
+// Tilemap returns the Tilemap of the ContextMap.
+func (cm *ContextMap) Tilemap() *Tilemap {
+	return cm.tmap
+}
+
+// Resolution returns the number of directions of the ContextMap.
+func (cm *ContextMap) Resolution() int {
+	return len(cm.interest)
+}
+
+// Interest returns the interest map of the current update.
+// Index i is the direction of angle i*2π/Resolution().
+func (cm *ContextMap) Interest() []float64 {
+	return cm.interest
+}
+
+// Danger returns the danger map of the current update.
+func (cm *ContextMap) Danger() []float64 {
+	return cm.danger
+}
 
-		for _, wall := range cm.tmap.WallsAround(image.Pt(x+1, y+1), 1) {
-			wx, wy := cm.tmap.IndexToIso(wall.X, wall.Y)
-			wy -= float64(cm.tmap.TileWidth - cm.tmap.TileWidth/4)
+// Masked reports whether the direction closest to an angle
+// was masked by danger in the current update.
+func (cm *ContextMap) Masked(angle float64) bool {
+	n := len(cm.danger)
+	i := int(math.Round(angle/cm.arc)) % n
+	if i < 0 {
+		i += n
+	}
 
-			cm.apply(
-				origin, Vec2{X: wx, Y: wy}, true,
-				cm.wallBehavior,
-			)
+	for _, d := range cm.danger {
+		if cm.danger[i] > d+cm.DangerThreshold {
+			return true
 		}
 	}
 
-	var maxIndex int
-	for i, m := range cm.cmap {
-		if m > cm.cmap[maxIndex] {
-			maxIndex = i
+	return false
+}
+
+// AddInterest adds interest in a direction. Interest falls off
+// with the angle from the direction, and is half the weight
+// at right angles to it.
+func (cm *ContextMap) AddInterest(angle, weight float64) {
+	cm.add(cm.interest, angle, weight, false, nil)
+}
+
+// AddDanger adds danger in a direction. Danger falls off
+// with the angle from the direction, and is zero at
+// right angles to it and beyond.
+func (cm *ContextMap) AddDanger(angle, weight float64) {
+	cm.add(cm.danger, angle, weight, true, nil)
+}
+
+func (cm *ContextMap) add(m []float64, angle, weight float64, danger bool, mod CMapModFunc) {
+	if weight == 0 || math.IsNaN(angle) {
+		return
+	}
+
+	for i := range cm.buf {
+		dot := math.Cos(float64(i)*cm.arc - angle)
+
+		if danger {
+			cm.buf[i] = math.Max(0, dot) * weight
+		} else {
+			cm.buf[i] = (dot + 1) / 2 * weight
 		}
 	}
 
-	// clear cmap
-	for i := range cm.cmap {
-		cm.cmap[i] = 0
+	if mod != nil {
+		mod(cm.buf)
 	}
 
-	return float64(maxIndex) * cm.arc
+	for i, v := range cm.buf {
+		m[i] += v
+		cm.buf[i] = 0
+	}
 }
 
-func (cm *ContextMap) apply(u, v Vec2, inhibit bool, behavior *ContextMapBehavior) {
-
-	dist := u.Distance(v)
-	m := math.Max(0, math.Min(
-		1, (behavior.Distance-dist)/behavior.Distance,
-	))
+// Angle returns a selected angle to move based on specified inputs,
+// and the strength of the interest in that direction.
+// A strength of zero indicates there is no interest in any direction.
+func (cm *ContextMap) Angle(origin Vec2, excite, inhibit []Vec2) (float64, float64) {
+	return cm.Steer(SteeringInput{
+		Origin:  origin,
+		Excite:  excite,
+		Inhibit: inhibit,
+	})
+}
 
-	if m == 0 {
-		return
+// Steer applies every behavior to the input, and returns the
+// selected angle and the strength of the interest in that
+// direction. The angle is interpolated between neighboring
+// directions. If there is no interest in any safe direction,
+// the input heading is returned with a strength of zero.
+func (cm *ContextMap) Steer(in SteeringInput) (float64, float64) {
+	for i := range cm.interest {
+		cm.interest[i] = 0
+		cm.danger[i] = 0
 	}
 
-	target := v.Sub(u).Normalize()
+	for _, b := range cm.behaviors {
+		b.Steer(cm, &in)
+	}
 
-	for i := 0; i < len(cm.cmap); i++ {
-		delta := (target.Dot(Vec2{
-			X: math.Cos(float64(i) * cm.arc),
-			Y: math.Sin(float64(i) * cm.arc),
-		}) + 1) / 2 * m
+	minDanger := math.Inf(1)
+	for _, d := range cm.danger {
+		minDanger = math.Min(minDanger, d)
+	}
 
-		if math.IsNaN(delta) {
-			continue
+	// mask dangerous directions
+	n := len(cm.interest)
+	for i, d := range cm.danger {
+		if d > minDanger+cm.DangerThreshold {
+			cm.interest[i] = 0
 		}
+	}
 
-		if inhibit {
-			delta = -delta
+	best := -1
+	for i, v := range cm.interest {
+		if v > 0 && (best < 0 || v > cm.interest[best]) {
+			best = i
 		}
+	}
 
-		cm.buf[i] = delta
+	if best < 0 {
+		return in.Heading, 0
 	}
 
-	if behavior.Mod != nil {
-		behavior.Mod(cm.buf)
+	// fit a parabola through the best direction and its neighbors
+	l := cm.interest[(best+n-1)%n]
+	c := cm.interest[best]
+	r := cm.interest[(best+1)%n]
+
+	var offset float64
+	if denom := l - 2*c + r; denom < 0 {
+		offset = math.Max(-0.5, math.Min(0.5, (l-r)/(2*denom)))
 	}
 
-	for i, delta := range cm.buf {
-		cm.cmap[i] += delta
-		cm.buf[i] = 0
+	angle := math.Mod((float64(best)+offset)*cm.arc, math.Pi*2)
+	if angle < 0 {
+		angle += math.Pi * 2
 	}
+
+	return angle, c - (l-r)*offset/4
+}
+
+// falloff returns the weight of a behavior for
+// a position at a distance, from 1 to 0 at the max distance.
+func falloff(dist, max float64) float64 {
+	if max <= 0 {
+		return 0
+	}
+
+	return math.Max(0, math.Min(1, (max-dist)/max))
 }
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContextMapAngle(t *testing.T) {
+	cm := NewContextMap(
+		16, nil,
+		&ContextMapBehavior{Distance: 100},
+		&ContextMapBehavior{Distance: 100},
+		nil,
+	)
+
+	// interpolated between directions
+	target := Vec2{}.Translate(0.2, 50)
+	angle, strength := cm.Angle(Vec2{}, []Vec2{target}, nil)
+
+	if math.Abs(angle-0.2) > 0.05 || strength <= 0 {
+		t.Fatalf("Expected %v got %v with strength %v", 0.2, angle, strength)
+	}
+
+	// danger masks the direction of the target
+	angle, _ = cm.Angle(
+		Vec2{},
+		[]Vec2{{X: 50}},
+		[]Vec2{{X: 20}},
+	)
+
+	if math.Abs(math.Cos(angle)) > 0.5 {
+		t.Fatalf("Expected masked direction got %v", angle)
+	}
+
+	// out of range
+	if _, strength = cm.Angle(Vec2{}, []Vec2{{X: 500}}, nil); strength != 0 {
+		t.Fatalf("Expected %v got %v", 0, strength)
+	}
+}
+
+func TestSteeringBehaviors(t *testing.T) {
+	cm := NewContextMap(32, nil, nil, nil, nil)
+
+	orbit, err := NewSteeringBehavior("orbit", map[string]float64{"radius": 50, "distance": 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm.AddBehavior(orbit)
+
+	// on the orbit, move tangentially
+	angle, strength := cm.Steer(SteeringInput{
+		Origin: Vec2{X: -50},
+		Excite: []Vec2{{}},
+	})
+
+	if math.Abs(angle-math.Pi/2) > 0.05 {
+		t.Fatalf("Expected %v got %v", math.Pi/2, angle)
+	}
+
+	// interest falls off with distance, scaled by the weight
+	orbit, _ = NewSteeringBehavior("orbit", map[string]float64{"radius": 50, "distance": 200, "weight": 2})
+	cm = NewContextMap(32, nil, nil, nil, nil)
+	cm.AddBehavior(orbit)
+
+	_, weighted := cm.Steer(SteeringInput{
+		Origin: Vec2{X: -50},
+		Excite: []Vec2{{}},
+	})
+
+	if math.Abs(weighted-2*strength) > 1e-9 || strength >= 1 {
+		t.Fatalf("Expected %v got %v", 2*strength, weighted)
+	}
+
+	cm = NewContextMap(32, nil, nil, nil, nil)
+	cm.AddBehavior(
+		&SteerAlign{Distance: 100, Weight: 1},
+		&SteerLeader{Distance: 50, Weight: 2},
+	)
+
+	// allies heading down, leader close by
+	in := SteeringInput{
+		Allies: []SteeringAgent{
+			{Position: Vec2{X: 10}, Heading: math.Pi / 2},
+			{Position: Vec2{X: -10}, Heading: math.Pi / 2},
+		},
+		Leader: &SteeringAgent{Position: Vec2{X: 20}},
+	}
+
+	if angle, _ = cm.Steer(in); math.Abs(angle-math.Pi/2) > 0.05 {
+		t.Fatalf("Expected %v got %v", math.Pi/2, angle)
+	}
+
+	// leader far away
+	in.Leader.Position = Vec2{Y: -500}
+	if angle, _ = cm.Steer(in); math.Abs(angle-math.Pi*3/2) > 0.05 {
+		t.Fatalf("Expected %v got %v", math.Pi*3/2, angle)
+	}
+
+	if _, err := NewSteeringBehavior("teleport", nil); err != UnknownSteeringBehavior("teleport") {
+		t.Fatalf("Expected %v got %v", UnknownSteeringBehavior("teleport"), err)
+	}
+}
@@ -0,0 +1,180 @@
+package engine
+
+import "math"
+
+// DirectionSet is a set of evenly spaced facing directions,
+// named in order of increasing angle from the angle of the
+// first direction. Angles increase clockwise on screen,
+// with 0 facing east.
+type DirectionSet struct {
+	Names []string
+	// Offset is the angle of the first direction in radians.
+	Offset float64
+}
+
+// Built-in direction sets.
+var (
+	Directions4 = DirectionSet{
+		Names: []string{"e", "s", "w", "n"},
+	}
+	// Directions4Diagonal is common for isometric sprites.
+	Directions4Diagonal = DirectionSet{
+		Names:  []string{"se", "sw", "nw", "ne"},
+		Offset: math.Pi / 4,
+	}
+	Directions8 = DirectionSet{
+		Names: []string{"e", "se", "s", "sw", "w", "nw", "n", "ne"},
+	}
+	Directions16 = DirectionSet{
+		Names: []string{
+			"e", "ese", "se", "sse", "s", "ssw", "sw", "wsw",
+			"w", "wnw", "nw", "nnw", "n", "nne", "ne", "ene",
+		},
+	}
+)
+
+// Index returns the index of the direction closest to an angle.
+func (d DirectionSet) Index(angle float64) int {
+	n := len(d.Names)
+	step := math.Pi * 2 / float64(n)
+
+	i := int(math.Round((angle - d.Offset) / step))
+
+	return (i%n + n) % n
+}
+
+// Name returns the name of the direction closest to an angle.
+func (d DirectionSet) Name(angle float64) string {
+	return d.Names[d.Index(angle)]
+}
+
+// Angle returns the angle of a direction.
+func (d DirectionSet) Angle(i int) float64 {
+	return d.Offset + float64(i)*math.Pi*2/float64(len(d.Names))
+}
+
+// DirectionalState returns the animation state name of
+// an action facing a direction, such as "walk_ne".
+// The state is the direction alone if the action is empty.
+func DirectionalState(action, direction string) string {
+	if action == "" {
+		return direction
+	}
+
+	return action + "_" + direction
+}
+
+// MirrorMode indicates which facing directions of
+// a DirectionalAnimation are mirrored from the others.
+type MirrorMode byte
+
+// Mirror modes.
+const (
+	// MirrorNone draws every direction.
+	MirrorNone MirrorMode = iota
+	// MirrorWest mirrors west facing directions
+	// from the east facing directions.
+	MirrorWest
+	// MirrorEast mirrors east facing directions
+	// from the west facing directions.
+	MirrorEast
+)
+
+// DirectionalAnimation selects the state of an Animation
+// from an action and a facing angle. Mirrored directions
+// are drawn by setting the horizontal scale of the Animation
+// to -1, so its origin should be horizontally centered, and
+// it should not be scaled by an entity transform.
+// The Animation, rather than the DirectionalAnimation,
+// is added to entities.
+type DirectionalAnimation struct {
+	Directions DirectionSet
+	Mirror     MirrorMode
+
+	anim      Animation
+	action    string
+	direction int
+	mirrored  bool
+}
+
+// NewDirectionalAnimation returns an instantiated *DirectionalAnimation.
+func NewDirectionalAnimation(anim Animation, directions DirectionSet, mirror MirrorMode) *DirectionalAnimation {
+	return &DirectionalAnimation{
+		Directions: directions,
+		Mirror:     mirror,
+		anim:       anim,
+	}
+}
+
+// Animation returns the underlying Animation.
+func (d *DirectionalAnimation) Animation() Animation {
+	return d.anim
+}
+
+// SetAction sets the action, keeping the facing direction.
+func (d *DirectionalAnimation) SetAction(action string) {
+	d.set(action, d.direction)
+}
+
+// Face sets the facing direction closest to an angle,
+// keeping the action. The animation continues from its
+// current tick, so turning does not restart the action.
+func (d *DirectionalAnimation) Face(angle float64) {
+	d.set(d.action, d.Directions.Index(angle))
+}
+
+// Set sets both the action and the facing angle.
+func (d *DirectionalAnimation) Set(action string, angle float64) {
+	d.set(action, d.Directions.Index(angle))
+}
+
+// Action returns the current action.
+func (d *DirectionalAnimation) Action() string {
+	return d.action
+}
+
+// Direction returns the name of the current facing direction.
+func (d *DirectionalAnimation) Direction() string {
+	return d.Directions.Names[d.direction]
+}
+
+// Mirrored indicates whether the current
+// facing direction is mirrored.
+func (d *DirectionalAnimation) Mirrored() bool {
+	return d.mirrored
+}
+
+func (d *DirectionalAnimation) set(action string, direction int) {
+	turned := action == d.action && direction != d.direction
+	d.action, d.direction = action, direction
+
+	drawn, mirrored := direction, false
+
+	angle := d.Directions.Angle(direction)
+	east := math.Cos(angle)
+
+	// directions facing exactly north or south are never mirrored
+	if (d.Mirror == MirrorWest && east < -1e-9) || (d.Mirror == MirrorEast && east > 1e-9) {
+		drawn = d.Directions.Index(math.Pi - angle)
+		mirrored = true
+	}
+
+	if state := DirectionalState(action, d.Directions.Names[drawn]); state != d.anim.State() {
+		tick := d.anim.TickCount()
+		d.anim.SetState(state)
+
+		if turned {
+			d.anim.SetTickCount(tick)
+		}
+	}
+
+	if mirrored != d.mirrored {
+		if mirrored {
+			d.anim.Scale(-1, 1)
+		} else {
+			d.anim.Scale(1, 1)
+		}
+
+		d.mirrored = mirrored
+	}
+}
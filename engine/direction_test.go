@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+type testScaledAnimation struct {
+	*testAnimation
+	sx float64
+}
+
+func (a *testScaledAnimation) Scale(sx, sy float64) { a.sx = sx }
+
+func TestDirectionSet(t *testing.T) {
+	tests := []struct {
+		set   DirectionSet
+		angle float64
+		name  string
+	}{
+		{Directions4, 0, "e"},
+		{Directions4, math.Pi / 2, "s"},
+		{Directions4, -math.Pi / 2, "n"},
+		{Directions4Diagonal, 0.1, "se"},
+		{Directions4Diagonal, -0.1, "ne"},
+		{Directions8, math.Pi * 3 / 4, "sw"},
+		{Directions8, math.Pi * 2, "e"},
+		{Directions16, math.Pi / 8, "ese"},
+		{Directions16, -math.Pi / 8, "ene"},
+	}
+
+	for _, test := range tests {
+		if name := test.set.Name(test.angle); name != test.name {
+			t.Fatalf("Expected %v got %v", test.name, name)
+		}
+	}
+}
+
+func TestDirectionalAnimation(t *testing.T) {
+	anim := &testScaledAnimation{testAnimation: newTestAnimation(), sx: 1}
+	d := NewDirectionalAnimation(anim, Directions8, MirrorWest)
+
+	d.Set("walk", 0)
+	if anim.State() != "walk_e" || anim.sx != 1 {
+		t.Fatalf("Expected %v got %v with scale %v", "walk_e", anim.State(), anim.sx)
+	}
+
+	// turning keeps the clock, changing the action resets it
+	anim.SetTickCount(5)
+	d.Face(math.Pi / 2)
+	if anim.State() != "walk_s" || anim.TickCount() != 5 {
+		t.Fatalf("Expected %v got %v at tick %v", "walk_s", anim.State(), anim.TickCount())
+	}
+
+	d.SetAction("run")
+	if anim.TickCount() != 0 {
+		t.Fatalf("Expected %v got %v", 0, anim.TickCount())
+	}
+
+	d.SetAction("walk")
+
+	// west facing directions are drawn mirrored
+	d.Face(math.Pi * 3 / 4)
+	if anim.State() != "walk_se" || anim.sx != -1 || !d.Mirrored() {
+		t.Fatalf("Expected %v got %v with scale %v", "walk_se", anim.State(), anim.sx)
+	}
+
+	if d.Direction() != "sw" {
+		t.Fatalf("Expected %v got %v", "sw", d.Direction())
+	}
+
+	// north is never mirrored
+	d.SetAction("idle")
+	d.Face(-math.Pi / 2)
+	if anim.State() != "idle_n" || anim.sx != 1 || d.Mirrored() {
+		t.Fatalf("Expected %v got %v with scale %v", "idle_n", anim.State(), anim.sx)
+	}
+
+	d = NewDirectionalAnimation(anim, Directions4Diagonal, MirrorEast)
+	d.Set("", math.Pi/4)
+	if anim.State() != "sw" || anim.sx != -1 {
+		t.Fatalf("Expected %v got %v with scale %v", "sw", anim.State(), anim.sx)
+	}
+}
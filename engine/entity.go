@@ -1,8 +1,14 @@
 package engine
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
 
 // Entity is a basic game entity.
+// Entities are compared by identity, such as when used
+// as map keys, so implementations must be pointers.
 type Entity interface {
 	Tick()
 
@@ -19,6 +25,13 @@ type Entity interface {
 	IsDisposed() bool
 }
 
+// checkEntity panics if an Entity is not a pointer.
+func checkEntity(e Entity) {
+	if reflect.ValueOf(e).Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("engine: entity %T must be a pointer", e))
+	}
+}
+
 // CoreEntity is a default Entity implementation.
 type CoreEntity struct {
 	Vec2
@@ -30,23 +43,38 @@ type CoreEntity struct {
 
 	images []Image
 
+	parent    *CoreEntity
+	children  []Node
+	transform *Transform
+	ctx       *Context
+
 	collider *Collider
+	body     *Body
 	disposed bool
 
 	lastAngle float64
 }
 
 // Tick updates the CoreEntity's position.
+// If the CoreEntity has a Body in a PhysicsWorld, movement
+// since the last tick is resolved by the world instead of
+// the Collider. Children follow their parent, and do not
+// resolve collisions.
+// Images are moved to the world position of the CoreEntity.
+// If the CoreEntity or an ancestor has a rotation, scale or
+// alpha, they are also set on the images.
 func (e *CoreEntity) Tick() {
-	if e.collider != nil {
+	switch {
+	case e.parent != nil:
+	case e.body != nil && e.body.world != nil:
+		e.Vec2 = e.body.world.Move(e.body, e.Vec2.Sub(e.body.pos))
+	case e.collider != nil:
 		e.Vec2 = e.collider.Resolve(e.prevPos, e.Vec2)
 	}
 
 	e.prevPos = e.Vec2
 
-	for _, img := range e.images {
-		img.Translate(e.X, e.Y)
-	}
+	e.updateImages()
 }
 
 // SetCollider sets the CoreEntity's Collider.
@@ -54,15 +82,36 @@ func (e *CoreEntity) SetCollider(collider *Collider) {
 	e.collider = collider
 }
 
-// Position gets the CoreEntity's current position.
+// SetBody sets the CoreEntity's Body. The Body's Data
+// field is set to the CoreEntity if it is unset.
+func (e *CoreEntity) SetBody(body *Body) {
+	if body != nil && body.Data == nil {
+		body.Data = e
+	}
+
+	e.body = body
+}
+
+// Body gets the CoreEntity's Body.
+func (e *CoreEntity) Body() *Body {
+	return e.body
+}
+
+// Position gets the CoreEntity's current world position.
+// The Vec2 field of a child is relative to its parent.
 func (e *CoreEntity) Position() Vec2 {
-	return e.Vec2
+	if e.parent == nil {
+		return e.Vec2
+	}
+
+	return e.WorldTransform().Position
 }
 
 // AddImage adds an Image to the CoreEntity.
 func (e *CoreEntity) AddImage(image ...Image) {
+	pos := e.Position()
 	for _, img := range image {
-		img.Translate(e.X, e.Y)
+		img.Translate(pos.X, pos.Y)
 	}
 
 	e.images = append(e.images, image...)
@@ -73,9 +122,36 @@ func (e *CoreEntity) Images() []Image {
 	return e.images
 }
 
-// Dispose marks the CoreEntity as disposed, and disposes its Images.
+// Dispose marks the CoreEntity as disposed, and disposes
+// its Images and children. It is removed from its parent.
 func (e *CoreEntity) Dispose() {
 	e.disposed = true
+
+	if e.parent != nil {
+		for i, c := range e.parent.children {
+			if c.node() == e {
+				copy(e.parent.children[i:], e.parent.children[i+1:])
+				e.parent.children[len(e.parent.children)-1] = nil
+				e.parent.children = e.parent.children[:len(e.parent.children)-1]
+				break
+			}
+		}
+
+		e.parent = nil
+	}
+
+	children := e.children
+	e.children = nil
+
+	for _, child := range children {
+		child.node().parent = nil
+		child.Dispose()
+	}
+
+	if e.body != nil && e.body.world != nil {
+		e.body.world.Remove(e.body)
+	}
+
 	for _, img := range e.images {
 		img.Dispose()
 	}
@@ -86,6 +162,12 @@ func (e *CoreEntity) IsDisposed() bool {
 	return e.disposed
 }
 
+// Heading returns the angle in radians of
+// the last MoveTowards call.
+func (e *CoreEntity) Heading() float64 {
+	return e.lastAngle
+}
+
 // MoveTowards moves the CoreEntity in the direction of angle
 // by distance dist. The change in angle between MoveTowards
 // calls is limited to a delta of the interval argument.
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// EventBus dispatches gameplay events to subscribers by type.
+// Events are arbitrary values, typically structs such as
+// DamageEvent{Target: e, Amount: 10}.
+type EventBus struct {
+	handlers map[reflect.Type][]*Subscription
+	ifaces   []reflect.Type
+	queue    []interface{}
+	flushing []interface{}
+}
+
+// Subscription is a registered event handler.
+type Subscription struct {
+	bus     *EventBus
+	typ     reflect.Type
+	handler reflect.Value
+}
+
+// NewEventBus returns an instantiated *EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[reflect.Type][]*Subscription),
+	}
+}
+
+// Subscribe registers a handler for events of a type.
+// The handler must be a function with a single argument,
+// such as func(DamageEvent). Handlers with an interface
+// argument receive every event implementing the interface.
+// Subscribe panics if the handler is not a valid function.
+func (b *EventBus) Subscribe(handler interface{}) *Subscription {
+	v := reflect.ValueOf(handler)
+	t := v.Type()
+
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		panic(fmt.Sprintf("engine: invalid event handler %s", t))
+	}
+
+	s := &Subscription{
+		bus:     b,
+		typ:     t.In(0),
+		handler: v,
+	}
+
+	if _, ok := b.handlers[s.typ]; !ok && s.typ.Kind() == reflect.Interface {
+		b.ifaces = append(b.ifaces, s.typ)
+	}
+
+	b.handlers[s.typ] = append(b.handlers[s.typ], s)
+
+	return s
+}
+
+// Unsubscribe removes the handler from its EventBus.
+func (s *Subscription) Unsubscribe() {
+	if s.bus == nil {
+		return
+	}
+
+	subs := s.bus.handlers[s.typ]
+	for i, sub := range subs {
+		if sub != s {
+			continue
+		}
+
+		// copy to avoid modifying a slice being dispatched to
+		s.bus.handlers[s.typ] = append(subs[:i:i], subs[i+1:]...)
+		break
+	}
+
+	s.bus = nil
+}
+
+// Publish queues an event to be dispatched on the next Flush.
+func (b *EventBus) Publish(event interface{}) {
+	b.queue = append(b.queue, event)
+}
+
+// Dispatch immediately dispatches an event to its subscribers.
+// Handlers of the event's type are called first, followed by
+// handlers of interfaces it implements, each in the order
+// they subscribed.
+func (b *EventBus) Dispatch(event interface{}) {
+	if event == nil {
+		return
+	}
+
+	t := reflect.TypeOf(event)
+	args := []reflect.Value{reflect.ValueOf(event)}
+
+	call := func(subs []*Subscription) {
+		for _, s := range subs {
+			if s.bus != nil {
+				s.handler.Call(args)
+			}
+		}
+	}
+
+	call(b.handlers[t])
+
+	for _, typ := range b.ifaces {
+		if t.Implements(typ) {
+			call(b.handlers[typ])
+		}
+	}
+}
+
+// Clear discards all queued events without dispatching them.
+func (b *EventBus) Clear() {
+	for i := range b.queue {
+		b.queue[i] = nil
+	}
+
+	b.queue = b.queue[:0]
+}
+
+// Flush dispatches all queued events in the order they were
+// published. Events published while flushing are queued for
+// the next Flush.
+func (b *EventBus) Flush() {
+	b.flushing, b.queue = b.queue, b.flushing[:0]
+
+	for i, event := range b.flushing {
+		b.Dispatch(event)
+		b.flushing[i] = nil
+	}
+
+	b.flushing = b.flushing[:0]
+}
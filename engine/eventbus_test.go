@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testDamageEvent struct {
+	amount int
+}
+
+func (e testDamageEvent) String() string {
+	return "damage"
+}
+
+type testPickupEvent struct{}
+
+func TestEventBus(t *testing.T) {
+
+	bus := NewEventBus()
+
+	var received []interface{}
+	damage := bus.Subscribe(func(e testDamageEvent) {
+		received = append(received, e)
+
+		// published while flushing
+		bus.Publish(testPickupEvent{})
+	})
+	bus.Subscribe(func(e testPickupEvent) {
+		received = append(received, e)
+	})
+
+	var named []string
+	bus.Subscribe(func(s interface{ String() string }) {
+		named = append(named, s.String())
+	})
+
+	bus.Publish(testDamageEvent{amount: 1})
+	bus.Publish(testDamageEvent{amount: 2})
+
+	if len(received) != 0 {
+		t.Fatalf("Expected deferred dispatch got %v", received)
+	}
+
+	bus.Flush()
+
+	expected := []interface{}{testDamageEvent{amount: 1}, testDamageEvent{amount: 2}}
+	if !reflect.DeepEqual(expected, received) {
+		t.Fatalf("Expected %v got %v", expected, received)
+	}
+
+	if !reflect.DeepEqual([]string{"damage", "damage"}, named) {
+		t.Fatalf("Expected 2 interface events got %v", named)
+	}
+
+	damage.Unsubscribe()
+	bus.Publish(testDamageEvent{amount: 3})
+	bus.Flush()
+
+	expected = append(expected, testPickupEvent{}, testPickupEvent{})
+	if !reflect.DeepEqual(expected, received) {
+		t.Fatalf("Expected %v got %v", expected, received)
+	}
+}
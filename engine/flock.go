@@ -0,0 +1,285 @@
+package engine
+
+import "math"
+
+// Headed is implemented by entities with a heading,
+// such as those embedding CoreEntity.
+type Headed interface {
+	Heading() float64
+}
+
+// Formation returns the offset of a formation slot from the
+// leader, for a leader heading along the positive x axis.
+// Count is the number of slots, excluding the leader.
+type Formation func(slot, count int, spacing float64) Vec2
+
+// FormationLine places members side by side, behind the leader.
+func FormationLine(slot, count int, spacing float64) Vec2 {
+	return Vec2{
+		X: -spacing,
+		Y: (float64(slot) - float64(count-1)/2) * spacing,
+	}
+}
+
+// FormationWedge places members in a V behind the leader,
+// alternating between the left and right sides.
+func FormationWedge(slot, count int, spacing float64) Vec2 {
+	row := float64(slot/2 + 1)
+	side := 1.0
+	if slot%2 == 1 {
+		side = -1
+	}
+
+	return Vec2{
+		X: -row * spacing,
+		Y: side * row * spacing,
+	}
+}
+
+// FormationCircle places members evenly in a circle
+// around the leader, with a radius of spacing.
+func FormationCircle(slot, count int, spacing float64) Vec2 {
+	return Vec2{}.Translate(math.Pi*2*float64(slot)/float64(count), spacing)
+}
+
+// Flock moves a group of entities together, using boids style
+// separation, alignment and cohesion, and optionally keeps
+// them in formation around a leader. Neighbors are looked up
+// through the Context, and must be members of the Flock.
+//
+// The result of Force can be passed to CoreEntity.MoveTowards,
+// or Behavior added to the ContextMap of a member:
+//
+//	angle, strength := cmap.Steer(flock.Input(e))
+//	e.MoveTowards(angle, speed*math.Min(1, strength), 0)
+type Flock struct {
+	// Radius is the distance within which
+	// members are neighbors.
+	Radius float64
+	// Separation is the distance below
+	// which neighbors are avoided.
+	Separation float64
+
+	SeparationWeight float64
+	AlignmentWeight  float64
+	CohesionWeight   float64
+
+	// Leader is the optional leader of a formation.
+	Leader Entity
+	// Formation is the optional formation around the Leader.
+	Formation Formation
+	// Spacing is the distance between formation slots.
+	Spacing         float64
+	FormationWeight float64
+
+	ctx     *Context
+	members []Entity
+	index   map[Entity]int
+}
+
+// NewFlock returns an instantiated *Flock with
+// equal weights, whose members are in a Context.
+func NewFlock(ctx *Context, radius, separation float64) *Flock {
+	return &Flock{
+		Radius:           radius,
+		Separation:       separation,
+		SeparationWeight: 1,
+		AlignmentWeight:  1,
+		CohesionWeight:   1,
+		Spacing:          separation * 2,
+		FormationWeight:  1,
+		ctx:              ctx,
+		index:            make(map[Entity]int),
+	}
+}
+
+// Add adds members to the Flock.
+// Formation slots are assigned in the order members are added.
+// It panics if a member is not a pointer.
+func (f *Flock) Add(members ...Entity) {
+	for _, e := range members {
+		checkEntity(e)
+
+		if _, ok := f.index[e]; ok {
+			continue
+		}
+
+		f.index[e] = len(f.members)
+		f.members = append(f.members, e)
+	}
+}
+
+// Remove removes a member from the Flock.
+func (f *Flock) Remove(e Entity) {
+	i, ok := f.index[e]
+	if !ok {
+		return
+	}
+
+	copy(f.members[i:], f.members[i+1:])
+	f.members[len(f.members)-1] = nil
+	f.members = f.members[:len(f.members)-1]
+
+	delete(f.index, e)
+	for j := i; j < len(f.members); j++ {
+		f.index[f.members[j]] = j
+	}
+
+	if f.Leader == e {
+		f.Leader = nil
+	}
+}
+
+// Members returns the members of the Flock.
+func (f *Flock) Members() []Entity {
+	return f.members
+}
+
+// Neighbors returns the members within Radius of a member.
+func (f *Flock) Neighbors(e Entity) []Entity {
+	return f.ctx.QueryRadius(e.Position(), f.Radius, func(n Entity) bool {
+		_, ok := f.index[n]
+		return ok && n != e
+	})
+}
+
+// Slot returns the world position of a member's formation
+// slot, and false if the member is not in formation.
+func (f *Flock) Slot(e Entity) (Vec2, bool) {
+	i, ok := f.index[e]
+	if !ok || f.Formation == nil || f.Leader == nil || e == f.Leader {
+		return Vec2{}, false
+	}
+
+	leader, ok := f.index[f.Leader]
+	if ok && i > leader {
+		i--
+	}
+
+	count := len(f.members)
+	if ok {
+		count--
+	}
+
+	heading := headingOf(f.Leader)
+	offset := f.Formation(i, count, f.Spacing).Rotate(heading)
+
+	return f.Leader.Position().Add(offset), true
+}
+
+// Force returns the weighted sum of the separation, alignment,
+// cohesion and formation forces on a member. Each force has
+// a length of at most one before it is weighted.
+func (f *Flock) Force(e Entity) Vec2 {
+	force, _ := f.force(e)
+	return force
+}
+
+func (f *Flock) force(e Entity) (Vec2, []Entity) {
+	pos := e.Position()
+	neighbors := f.Neighbors(e)
+
+	var separation, alignment, center Vec2
+	for _, n := range neighbors {
+		npos := n.Position()
+		center = center.Add(npos)
+
+		if d := pos.Distance(npos); d > 0 && d < f.Separation {
+			separation = separation.Add(
+				pos.Sub(npos).Scale((f.Separation - d) / f.Separation / d),
+			)
+		}
+
+		alignment = alignment.Translate(headingOf(n), 1)
+	}
+
+	var force Vec2
+
+	if len(neighbors) > 0 {
+		count := float64(len(neighbors))
+
+		force = force.Add(clampLength(separation).Scale(f.SeparationWeight))
+		force = force.Add(alignment.Scale(f.AlignmentWeight / count))
+
+		center = center.Scale(1 / count)
+		if d := pos.Distance(center); d > 0 {
+			cohesion := center.Sub(pos).Scale(math.Min(1, d/f.Radius) / d)
+			force = force.Add(cohesion.Scale(f.CohesionWeight))
+		}
+	}
+
+	if slot, ok := f.Slot(e); ok {
+		if d := pos.Distance(slot); d > 0 {
+			pull := 1.0
+			if f.Spacing > 0 {
+				pull = math.Min(1, d/f.Spacing)
+			}
+
+			force = force.Add(slot.Sub(pos).Scale(pull / d * f.FormationWeight))
+		}
+	}
+
+	return force, neighbors
+}
+
+// Behavior returns a SteeringBehavior for a member. Its force is
+// added as interest, and neighbors within the separation distance
+// as danger. It should only be used in the ContextMap of the member.
+func (f *Flock) Behavior(e Entity) SteeringBehavior {
+	return SteeringFunc(func(cm *ContextMap, in *SteeringInput) {
+		force, neighbors := f.force(e)
+		if l := force.Length(); l > 0 {
+			cm.AddInterest(force.Angle(), math.Min(1, l))
+		}
+
+		pos := e.Position()
+		for _, n := range neighbors {
+			if d := pos.Distance(n.Position()); d > 0 && d < f.Separation {
+				cm.AddDanger(pos.AngleTo(n.Position()), falloff(d, f.Separation))
+			}
+		}
+	})
+}
+
+// Input returns a SteeringInput for a member, with its
+// neighbors as allies and the leader of the Flock, for use
+// with behaviors such as SteerAlign and SteerLeader.
+func (f *Flock) Input(e Entity) SteeringInput {
+	in := SteeringInput{
+		Origin:  e.Position(),
+		Heading: headingOf(e),
+	}
+
+	for _, n := range f.Neighbors(e) {
+		in.Allies = append(in.Allies, SteeringAgent{
+			Position: n.Position(),
+			Heading:  headingOf(n),
+		})
+	}
+
+	if f.Leader != nil && f.Leader != e {
+		in.Leader = &SteeringAgent{
+			Position: f.Leader.Position(),
+			Heading:  headingOf(f.Leader),
+		}
+	}
+
+	return in
+}
+
+func headingOf(e Entity) float64 {
+	if h, ok := e.(Headed); ok {
+		return h.Heading()
+	}
+
+	return 0
+}
+
+// clampLength returns v with a length of at most one.
+func clampLength(v Vec2) Vec2 {
+	if l := v.Length(); l > 1 {
+		return v.Scale(1 / l)
+	}
+
+	return v
+}
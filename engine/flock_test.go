@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestFlock(t *testing.T) {
+	ctx := NewContext(&testRenderer{viewport: image.Rect(0, 0, 500, 500)}, nil, nil)
+
+	a, b, c := new(testTickEntity), new(testTickEntity), new(testTickEntity)
+	a.Vec2 = Vec2{X: 0, Y: 0}
+	b.Vec2 = Vec2{X: 5, Y: 0}
+	c.Vec2 = Vec2{X: 300, Y: 0}
+
+	ctx.AddEntity(a, b, c)
+	ctx.Tick()
+
+	flock := NewFlock(ctx, 100, 20)
+	flock.AlignmentWeight = 0
+	flock.CohesionWeight = 0
+	flock.Add(a, b, c)
+
+	if n := flock.Neighbors(a); len(n) != 1 || n[0] != b {
+		t.Fatalf("Expected [b] got %v", n)
+	}
+
+	// separation pushes a away from b
+	if f := flock.Force(a); f.X >= 0 {
+		t.Fatalf("Expected separation got %v", f)
+	}
+
+	flock.SeparationWeight = 0
+	flock.CohesionWeight = 1
+	b.Vec2 = Vec2{X: 50}
+
+	if f := flock.Force(a); f.X <= 0 {
+		t.Fatalf("Expected cohesion got %v", f)
+	}
+
+	// formation around a leader heading down
+	flock.CohesionWeight = 0
+	flock.Leader = a
+	flock.Formation = FormationLine
+	flock.Spacing = 10
+	a.MoveTowards(math.Pi/2, 0, 0)
+
+	slot, ok := flock.Slot(b)
+	expected := Vec2{X: 5, Y: -10}
+	if !ok || slot.Distance(expected) > 1e-9 {
+		t.Fatalf("Expected %v got %v", expected, slot)
+	}
+
+	if _, ok := flock.Slot(a); ok {
+		t.Fatal("Expected leader not to have a slot.")
+	}
+
+	flock.Remove(a)
+	if flock.Leader != nil || len(flock.Members()) != 2 {
+		t.Fatalf("Expected leader to be removed got %v", flock.Members())
+	}
+}
@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"image"
+	"math"
+)
+
+// HasLineOfSight indicates whether an unobstructed straight line
+// exists between two tiles. Opaque tiles block line of sight,
+// excluding the tiles at either end of the line.
+func (t *Tilemap) HasLineOfSight(a, b image.Point) bool {
+
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := sign(b.X-a.X), sign(b.Y-a.Y)
+	e := dx + dy
+
+	p := a
+	for p != b {
+		if p != a && t.IsOpaque(p.X, p.Y) {
+			return false
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			p.Y += sy
+		}
+	}
+
+	return true
+}
+
+// FieldOfView returns all tiles visible from an origin
+// within a given radius, using symmetric shadowcasting.
+// Opaque tiles are visible, but block the tiles behind them.
+func (t *Tilemap) FieldOfView(origin image.Point, radius int) []image.Point {
+	var visible []image.Point
+
+	t.fov(origin, radius, func(p image.Point) {
+		visible = append(visible, p)
+	})
+
+	return visible
+}
+
+// fov calls reveal for every tile visible from an origin.
+// A tile may be revealed more than once.
+func (t *Tilemap) fov(origin image.Point, radius int, reveal func(image.Point)) {
+
+	if !t.InBounds(origin, 1) {
+		return
+	}
+
+	reveal(origin)
+
+	type row struct {
+		depth      int
+		start, end float64
+	}
+
+	for quadrant := 0; quadrant < 4; quadrant++ {
+		transform := func(depth, col int) image.Point {
+			switch quadrant {
+			case 0:
+				return image.Pt(origin.X+col, origin.Y-depth)
+			case 1:
+				return image.Pt(origin.X+depth, origin.Y+col)
+			case 2:
+				return image.Pt(origin.X+col, origin.Y+depth)
+			default:
+				return image.Pt(origin.X-depth, origin.Y+col)
+			}
+		}
+
+		isWall := func(p image.Point) bool {
+			return !t.InBounds(p, 1) || t.IsOpaque(p.X, p.Y)
+		}
+
+		rows := []row{{depth: 1, start: -1, end: 1}}
+		for len(rows) > 0 {
+			r := rows[len(rows)-1]
+			rows = rows[:len(rows)-1]
+
+			if r.depth > radius {
+				continue
+			}
+
+			minCol := int(math.Floor(float64(r.depth)*r.start + 0.5))
+			maxCol := int(math.Ceil(float64(r.depth)*r.end - 0.5))
+
+			// -1 unset, 0 floor, 1 wall
+			prev := -1
+
+			for col := minCol; col <= maxCol; col++ {
+				p := transform(r.depth, col)
+				wall := isWall(p)
+
+				symmetric := float64(col) >= float64(r.depth)*r.start &&
+					float64(col) <= float64(r.depth)*r.end
+
+				if (wall || symmetric) && col*col+r.depth*r.depth <= radius*radius && t.InBounds(p, 1) {
+					reveal(p)
+				}
+
+				slope := float64(2*col-1) / float64(2*r.depth)
+
+				if prev == 1 && !wall {
+					r.start = slope
+				}
+				if prev == 0 && wall {
+					rows = append(rows, row{depth: r.depth + 1, start: r.start, end: slope})
+				}
+
+				prev = 0
+				if wall {
+					prev = 1
+				}
+			}
+
+			if prev == 0 {
+				rows = append(rows, row{depth: r.depth + 1, start: r.start, end: r.end})
+			}
+		}
+	}
+}
+
+// FogState indicates the exploration state of a tile.
+type FogState byte
+
+const (
+	// FogUnexplored indicates a tile that has never been visible.
+	FogUnexplored FogState = iota
+
+	// FogSeen indicates a tile that has been visible,
+	// but is not currently visible.
+	FogSeen
+
+	// FogVisible indicates a currently visible tile.
+	FogVisible
+)
+
+// FogOfWar tracks the exploration state of tiles in a Tilemap.
+// Assign it to the Fog field of a Tilemap for the IsoRenderer to
+// hide unexplored tiles and darken tiles that are not visible.
+type FogOfWar struct {
+	// SeenTint is the color scale applied to seen tiles
+	// that are not currently visible.
+	SeenTint float64
+	// HideImages indicates whether images that are not on a
+	// visible tile should be hidden by the IsoRenderer.
+	HideImages bool
+
+	tmap    *Tilemap
+	seen    map[image.Point]struct{}
+	visible map[image.Point]struct{}
+}
+
+// NewFogOfWar returns an instantiated *FogOfWar with every tile unexplored.
+func NewFogOfWar(tmap *Tilemap) *FogOfWar {
+	return &FogOfWar{
+		SeenTint: 0.5,
+		tmap:     tmap,
+		seen:     make(map[image.Point]struct{}),
+		visible:  make(map[image.Point]struct{}),
+	}
+}
+
+// Update marks all visible tiles as seen, and reveals the
+// field of view from an origin within a given radius.
+func (f *FogOfWar) Update(origin image.Point, radius int) {
+	f.Hide()
+	f.Reveal(origin, radius)
+}
+
+// Hide marks all visible tiles as seen.
+func (f *FogOfWar) Hide() {
+	f.visible = make(map[image.Point]struct{}, len(f.visible))
+}
+
+// Reveal marks the field of view from an origin within a given
+// radius as visible, in addition to any already visible tiles.
+// This allows multiple viewers to share a FogOfWar.
+func (f *FogOfWar) Reveal(origin image.Point, radius int) {
+	f.tmap.fov(origin, radius, func(p image.Point) {
+		f.visible[p] = struct{}{}
+		f.seen[p] = struct{}{}
+	})
+}
+
+// State returns the exploration state of a tile.
+func (f *FogOfWar) State(x, y int) FogState {
+	p := image.Pt(x, y)
+
+	if _, ok := f.visible[p]; ok {
+		return FogVisible
+	}
+
+	if _, ok := f.seen[p]; ok {
+		return FogSeen
+	}
+
+	return FogUnexplored
+}
+
+// IsVisible indicates whether a tile is currently visible.
+func (f *FogOfWar) IsVisible(x, y int) bool {
+	_, ok := f.visible[image.Pt(x, y)]
+	return ok
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"image"
+	"testing"
+)
+
+func TestHasLineOfSight(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+
+	cases := []struct {
+		a, b     image.Point
+		expected bool
+	}{
+		{image.Pt(0, 0), image.Pt(5, 0), true},
+		{image.Pt(0, 0), image.Pt(0, 2), true},
+		{image.Pt(0, 2), image.Pt(3, 2), true},
+		{image.Pt(0, 0), image.Pt(2, 2), false},
+		{image.Pt(0, 2), image.Pt(0, 4), false},
+		// walls themselves can be seen
+		{image.Pt(0, 0), image.Pt(1, 1), true},
+	}
+
+	for _, c := range cases {
+		if actual := tmap.HasLineOfSight(c.a, c.b); actual != c.expected {
+			t.Fatalf("Expected %v got %v for %v to %v", c.expected, actual, c.a, c.b)
+		}
+
+		if actual := tmap.HasLineOfSight(c.b, c.a); actual != c.expected {
+			t.Fatalf("Expected %v got %v for %v to %v", c.expected, actual, c.b, c.a)
+		}
+	}
+}
+
+func TestFieldOfView(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+
+	visible := make(map[image.Point]bool)
+	for _, p := range tmap.FieldOfView(image.Pt(0, 0), 10) {
+		visible[p] = true
+	}
+
+	cases := []struct {
+		p        image.Point
+		expected bool
+	}{
+		{image.Pt(0, 0), true},
+		{image.Pt(5, 0), true},
+		{image.Pt(0, 2), true},
+		{image.Pt(2, 1), true},
+		{image.Pt(3, 2), false},
+		{image.Pt(5, 5), false},
+		{image.Pt(0, 4), false},
+	}
+
+	for _, c := range cases {
+		if visible[c.p] != c.expected {
+			t.Fatalf("Expected visibility %v got %v for %v", c.expected, visible[c.p], c.p)
+		}
+	}
+
+	// radius
+	for _, p := range tmap.FieldOfView(image.Pt(0, 0), 2) {
+		if p.X*p.X+p.Y*p.Y > 4 {
+			t.Fatalf("Expected %v to be outside of radius 2", p)
+		}
+	}
+
+	// symmetry
+	origin := image.Pt(3, 4)
+	for _, p := range tmap.FieldOfView(origin, 10) {
+		if tmap.IsOpaque(p.X, p.Y) {
+			continue
+		}
+
+		found := false
+		for _, q := range tmap.FieldOfView(p, 10) {
+			if q == origin {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			t.Fatalf("Expected %v to see %v", p, origin)
+		}
+	}
+}
+
+func TestFogOfWar(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+	fog := NewFogOfWar(tmap)
+
+	if s := fog.State(0, 0); s != FogUnexplored {
+		t.Fatalf("Expected %v got %v", FogUnexplored, s)
+	}
+
+	fog.Update(image.Pt(0, 0), 10)
+	if s := fog.State(5, 0); s != FogVisible {
+		t.Fatalf("Expected %v got %v", FogVisible, s)
+	}
+
+	fog.Update(image.Pt(0, 4), 10)
+	if s := fog.State(5, 0); s != FogSeen {
+		t.Fatalf("Expected %v got %v", FogSeen, s)
+	}
+	if s := fog.State(3, 4); s != FogVisible {
+		t.Fatalf("Expected %v got %v", FogVisible, s)
+	}
+	if s := fog.State(5, 5); s != FogUnexplored {
+		t.Fatalf("Expected %v got %v", FogUnexplored, s)
+	}
+}
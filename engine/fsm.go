@@ -0,0 +1,331 @@
+package engine
+
+import "fmt"
+
+// UnknownState occurs when an FSM state is not defined.
+type UnknownState string
+
+// Error implements error.
+func (u UnknownState) Error() string {
+	return fmt.Sprintf("unknown state: %s", string(u))
+}
+
+// FSM is a hierarchical finite state machine with named states.
+// Unlike StateMachine, exactly one leaf state is active at a time,
+// along with each of its ancestors. Callbacks are run in a
+// deterministic order: exits from the innermost state outwards,
+// enters and ticks from the outermost state inwards.
+type FSM struct {
+	// Animation optionally has its state set to the
+	// Animation of the innermost active state defining one.
+	Animation Animation
+	// TraceLimit is the number of recent transitions kept
+	// in the trace. The trace is disabled by default.
+	TraceLimit int
+
+	states  map[string]*FSMState
+	current *FSMState
+	trace   []FSMTransition
+
+	// events fired during a transition are queued
+	transitioning bool
+	queue         []string
+}
+
+// FSMState is a state of an FSM.
+type FSMState struct {
+	// Name is the unique name of the state.
+	Name string
+	// Initial is the name of the sub-state
+	// entered along with this state.
+	Initial string
+	// Animation is the animation state set when this
+	// state is entered. Sub-states inherit it unless
+	// they define their own.
+	Animation string
+
+	OnEnter func()
+	OnTick  func()
+	OnExit  func()
+
+	parent      *FSMState
+	transitions []fsmTransition
+}
+
+// FSMTransition is a recorded transition between leaf states.
+type FSMTransition struct {
+	From, To string
+	// Event is the event that triggered the transition,
+	// empty for automatic and forced transitions.
+	Event string
+}
+
+type fsmTransition struct {
+	event string
+	to    string
+	guard func() bool
+}
+
+// NewFSM returns an instantiated *FSM.
+func NewFSM() *FSM {
+	return &FSM{
+		states: make(map[string]*FSMState),
+	}
+}
+
+// AddState defines a state, and returns it so that its callbacks
+// can be set. A non-empty parent makes the state a sub-state
+// of a previously defined state. AddState panics if the state
+// is already defined, or the parent is not.
+func (f *FSM) AddState(name, parent string) *FSMState {
+	if _, ok := f.states[name]; ok {
+		panic(fmt.Sprintf("engine: state %s already defined", name))
+	}
+
+	s := &FSMState{Name: name}
+
+	if parent != "" {
+		p, ok := f.states[parent]
+		if !ok {
+			panic(UnknownState(parent))
+		}
+
+		s.parent = p
+	}
+
+	f.states[name] = s
+
+	return s
+}
+
+// State returns a defined state, or nil.
+func (f *FSM) State(name string) *FSMState {
+	return f.states[name]
+}
+
+// AddTransition adds a transition from one state to another,
+// triggered by an event. Transitions of a state also apply to its
+// sub-states, with those of inner states checked first, in the
+// order they were added. A transition with an empty event is
+// checked on every Tick. The guard is optional, and the
+// transition is only taken if it returns true.
+// AddTransition panics if either state is not defined.
+func (f *FSM) AddTransition(from, event, to string, guard func() bool) {
+	s, ok := f.states[from]
+	if !ok {
+		panic(UnknownState(from))
+	}
+
+	if _, ok := f.states[to]; !ok {
+		panic(UnknownState(to))
+	}
+
+	s.transitions = append(s.transitions, fsmTransition{
+		event: event,
+		to:    to,
+		guard: guard,
+	})
+}
+
+// SetState forces a transition to a state, ignoring guards.
+// It is used to enter the initial state of the FSM.
+func (f *FSM) SetState(name string) error {
+	s, ok := f.states[name]
+	if !ok {
+		return UnknownState(name)
+	}
+
+	f.transition(s, "")
+
+	return nil
+}
+
+// Fire triggers an event, taking the first matching transition
+// whose guard passes. Fire indicates whether a transition was taken.
+// Events fired during a transition, such as from OnEnter, are
+// queued and fired in order once the transition completes,
+// and Fire returns false for them.
+func (f *FSM) Fire(event string) bool {
+	if event == "" {
+		return false
+	}
+
+	if f.transitioning {
+		f.queue = append(f.queue, event)
+		return false
+	}
+
+	return f.fire(event)
+}
+
+func (f *FSM) fire(event string) bool {
+	for s := f.current; s != nil; s = s.parent {
+		for _, t := range s.transitions {
+			if t.event != event || (t.guard != nil && !t.guard()) {
+				continue
+			}
+
+			f.transition(f.states[t.to], event)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tick takes the first passing automatic transition, if any,
+// then calls OnTick for every active state.
+func (f *FSM) Tick() {
+	if f.current == nil {
+		return
+	}
+
+	f.fire("")
+
+	for _, s := range f.path(f.current) {
+		if s.OnTick != nil {
+			s.OnTick()
+		}
+	}
+}
+
+// Current returns the name of the active leaf state,
+// or an empty string if the FSM has not started.
+func (f *FSM) Current() string {
+	if f.current == nil {
+		return ""
+	}
+
+	return f.current.Name
+}
+
+// Is indicates whether a state is active,
+// either as the leaf state or one of its ancestors.
+func (f *FSM) Is(name string) bool {
+	for s := f.current; s != nil; s = s.parent {
+		if s.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Trace returns the most recent transitions,
+// up to TraceLimit, oldest first.
+func (f *FSM) Trace() []FSMTransition {
+	return f.trace
+}
+
+// transition exits active states up to the nearest common
+// ancestor of the target, then enters states down to the
+// target and its initial sub-states. A state transitioning
+// to itself or an ancestor is exited and entered again.
+// The current state is the state being exited or entered
+// while its callback runs.
+func (f *FSM) transition(to *FSMState, event string) {
+	from := f.current
+	f.transitioning = true
+
+	// find the innermost common ancestor that stays active
+	var common *FSMState
+	for s := to.parent; s != nil && common == nil; s = s.parent {
+		for a := from; a != nil; a = a.parent {
+			if a == s {
+				common = s
+				break
+			}
+		}
+	}
+
+	for s := from; s != nil && s != common; s = s.parent {
+		f.current = s
+
+		if s.OnExit != nil {
+			s.OnExit()
+		}
+	}
+
+	// enter from below the common ancestor down to the target
+	var enter []*FSMState
+	for s := to; s != common; s = s.parent {
+		enter = append(enter, s)
+	}
+
+	for i := len(enter) - 1; i >= 0; i-- {
+		f.current = enter[i]
+
+		if enter[i].OnEnter != nil {
+			enter[i].OnEnter()
+		}
+	}
+
+	f.current = to
+
+	for f.current.Initial != "" {
+		s, ok := f.states[f.current.Initial]
+		if !ok || s.parent != f.current {
+			panic(fmt.Sprintf("engine: invalid initial state %s", f.current.Initial))
+		}
+
+		f.current = s
+
+		if s.OnEnter != nil {
+			s.OnEnter()
+		}
+	}
+
+	f.updateAnimation()
+
+	if f.TraceLimit > 0 {
+		t := FSMTransition{To: f.current.Name, Event: event}
+		if from != nil {
+			t.From = from.Name
+		}
+
+		if len(f.trace) >= f.TraceLimit {
+			copy(f.trace, f.trace[len(f.trace)-f.TraceLimit+1:])
+			f.trace = f.trace[:f.TraceLimit-1]
+		}
+
+		f.trace = append(f.trace, t)
+	}
+
+	f.transitioning = false
+
+	for len(f.queue) > 0 {
+		event := f.queue[0]
+		f.queue[0] = ""
+		f.queue = f.queue[1:]
+
+		f.fire(event)
+	}
+}
+
+func (f *FSM) updateAnimation() {
+	if f.Animation == nil {
+		return
+	}
+
+	for s := f.current; s != nil; s = s.parent {
+		if s.Animation != "" {
+			f.Animation.SetState(s.Animation)
+			return
+		}
+	}
+}
+
+// path returns the active states from the outermost to s.
+func (f *FSM) path(s *FSMState) []*FSMState {
+	var path []*FSMState
+	for ; s != nil; s = s.parent {
+		path = append(path, s)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
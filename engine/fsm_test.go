@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFSM(t *testing.T) {
+	var calls []string
+	record := func(s *FSMState) {
+		name := s.Name
+		s.OnEnter = func() { calls = append(calls, "enter "+name) }
+		s.OnTick = func() { calls = append(calls, "tick "+name) }
+		s.OnExit = func() { calls = append(calls, "exit "+name) }
+	}
+
+	anim := newTestAnimation()
+
+	fsm := NewFSM()
+	fsm.Animation = anim
+	fsm.TraceLimit = 2
+
+	ground := fsm.AddState("ground", "")
+	ground.Initial = "idle"
+	record(ground)
+
+	idle := fsm.AddState("idle", "ground")
+	idle.Animation = "idle"
+	record(idle)
+
+	walk := fsm.AddState("walk", "ground")
+	walk.Animation = "walk"
+	record(walk)
+
+	air := fsm.AddState("air", "")
+	air.Animation = "jump"
+	record(air)
+
+	stamina := 0
+	fsm.AddTransition("idle", "move", "walk", nil)
+	fsm.AddTransition("ground", "jump", "air", func() bool { return stamina > 0 })
+	fsm.AddTransition("air", "", "ground", nil)
+
+	if err := fsm.SetState("ground"); err != nil {
+		t.Fatal(err)
+	}
+
+	if fsm.Current() != "idle" || !fsm.Is("ground") || anim.State() != "idle" {
+		t.Fatalf("Expected idle got %s", fsm.Current())
+	}
+
+	if fsm.Fire("jump") {
+		t.Fatal("Expected guard to block transition.")
+	}
+
+	calls = nil
+	fsm.Fire("move")
+	fsm.Tick()
+
+	expected := []string{"exit idle", "enter walk", "tick ground", "tick walk"}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("Expected %v got %v", expected, calls)
+	}
+
+	stamina = 1
+	calls = nil
+
+	// inherited from ground
+	if !fsm.Fire("jump") || anim.State() != "jump" {
+		t.Fatalf("Expected air got %s", fsm.Current())
+	}
+
+	// automatic transition back to the initial sub-state of ground
+	fsm.Tick()
+
+	expected = []string{
+		"exit walk", "exit ground", "enter air",
+		"exit air", "enter ground", "enter idle",
+		"tick ground", "tick idle",
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("Expected %v got %v", expected, calls)
+	}
+
+	trace := []FSMTransition{
+		{From: "walk", To: "air", Event: "jump"},
+		{From: "air", To: "idle"},
+	}
+	if !reflect.DeepEqual(trace, fsm.Trace()) {
+		t.Fatalf("Expected %v got %v", trace, fsm.Trace())
+	}
+
+	if err := fsm.SetState("swim"); err != UnknownState("swim") {
+		t.Fatalf("Expected %v got %v", UnknownState("swim"), err)
+	}
+}
+
+func TestFSMFireFromEnter(t *testing.T) {
+	var calls []string
+
+	fsm := NewFSM()
+	fsm.AddState("alive", "")
+	dead := fsm.AddState("dead", "")
+	gone := fsm.AddState("gone", "")
+
+	fsm.AddTransition("alive", "die", "dead", nil)
+	fsm.AddTransition("dead", "despawn", "gone", nil)
+
+	dead.OnEnter = func() {
+		calls = append(calls, "enter "+fsm.Current())
+
+		// queued until the transition to dead completes
+		if fsm.Fire("despawn") {
+			t.Fatal("Expected despawn to be queued.")
+		}
+	}
+	dead.OnExit = func() { calls = append(calls, "exit "+fsm.Current()) }
+	gone.OnEnter = func() { calls = append(calls, "enter "+fsm.Current()) }
+
+	if err := fsm.SetState("alive"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !fsm.Fire("die") {
+		t.Fatal("Expected die to transition.")
+	}
+
+	if fsm.Current() != "gone" {
+		t.Fatalf("Expected %v got %v", "gone", fsm.Current())
+	}
+
+	expected := []string{"enter dead", "exit dead", "enter gone"}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("Expected %v got %v", expected, calls)
+	}
+}
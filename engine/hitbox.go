@@ -1,11 +1,273 @@
 package engine
 
-import "image"
+import (
+	"fmt"
+	"image"
+	"sort"
+)
 
 // Hitbox is an object with collision support.
+// Hit is called every tick while bounds overlap.
+//
+// Deprecated: HitShaper supports float shapes,
+// collision layers and overlap phases.
 type Hitbox interface {
 	Position() Vec2
-	HitBounds() image.Rectangle // TODO upgrade to float based approach?
+	HitBounds() image.Rectangle
 	HitClasses() []string
 	Hit(Entity)
 }
+
+// HitShape is a named collision shape used for hit detection,
+// such as a hurtbox or the blade of a weapon.
+type HitShape struct {
+	// Name identifies the shape, and must be
+	// unique among the shapes of an entity.
+	Name string
+	// Shape is relative to the position of the entity.
+	Shape Shape
+	// Layer is the set of layers the shape is on.
+	Layer uint32
+	// Mask is the set of layers the shape detects.
+	Mask uint32
+}
+
+// HitPhase is the phase of an overlap between two HitShapes.
+type HitPhase int
+
+const (
+	// HitBegin occurs on the first tick of an overlap.
+	HitBegin HitPhase = iota
+
+	// HitStay occurs on every following tick of an overlap.
+	HitStay
+
+	// HitEnd occurs on the first tick two shapes no longer
+	// overlap while both entities are updated, or when
+	// either entity is removed from the Context or disposed.
+	HitEnd
+)
+
+// HitEvent describes an overlap between a
+// HitShape and the HitShape of another entity.
+type HitEvent struct {
+	Phase HitPhase
+
+	Shape      HitShape
+	Other      Entity
+	OtherShape HitShape
+}
+
+// HitShaper is an Entity with hit detection through
+// HitShapes. A shape receives events for every shape of
+// another entity whose layer is included in its mask.
+type HitShaper interface {
+	Position() Vec2
+	HitShapes() []HitShape
+	HitEvent(HitEvent)
+}
+
+type hitPair struct {
+	src, dst           Entity
+	srcShape, dstShape string
+}
+
+type hitContact struct {
+	pair  hitPair
+	event HitEvent
+}
+
+type hitRef struct {
+	entity Entity
+	shape  HitShape
+	pos    Vec2
+	bounds AABB
+}
+
+// hitTracker detects HitShape overlaps, tracking
+// the phase of each overlapping pair across ticks.
+type hitTracker struct {
+	active   map[hitPair]struct{}
+	contacts []hitContact
+	next     []hitContact
+	refs     []hitRef
+}
+
+func newHitTracker() *hitTracker {
+	return &hitTracker{
+		active: make(map[hitPair]struct{}),
+	}
+}
+
+// update detects overlaps between the HitShapes
+// of entries, and dispatches HitEvents.
+func (h *hitTracker) update(entries []PartitionEntry) {
+
+	updated := make(map[Entity]struct{}, len(entries))
+
+	for _, entry := range entries {
+		e, ok := entry.(Entity)
+		if !ok {
+			continue
+		}
+		updated[e] = struct{}{}
+
+		shaper, ok := entry.(HitShaper)
+		if !ok || e.IsDisposed() {
+			continue
+		}
+
+		pos := shaper.Position()
+		shapes := shaper.HitShapes()
+		for i, shape := range shapes {
+			for _, prev := range shapes[:i] {
+				if prev.Name == shape.Name {
+					panic(fmt.Sprintf("engine: duplicate hit shape %s on %T", shape.Name, e))
+				}
+			}
+
+			if shape.Shape == nil {
+				continue
+			}
+
+			h.refs = append(h.refs, hitRef{
+				entity: e,
+				shape:  shape,
+				pos:    pos,
+				bounds: shape.Shape.Bounds().Translate(pos),
+			})
+		}
+	}
+
+	// sweep and prune along the x axis
+	sort.Slice(h.refs, func(i, j int) bool {
+		return h.refs[i].bounds.Min.X < h.refs[j].bounds.Min.X
+	})
+
+	for i := range h.refs {
+		a := &h.refs[i]
+
+		for j := i + 1; j < len(h.refs); j++ {
+			b := &h.refs[j]
+			if b.bounds.Min.X >= a.bounds.Max.X {
+				break
+			}
+
+			if a.entity == b.entity || !a.bounds.Overlaps(b.bounds) {
+				continue
+			}
+
+			aDetects := a.shape.Mask&b.shape.Layer != 0
+			bDetects := b.shape.Mask&a.shape.Layer != 0
+			if !aDetects && !bDetects {
+				continue
+			}
+
+			if _, ok := Overlap(a.shape.Shape, a.pos, b.shape.Shape, b.pos); !ok {
+				continue
+			}
+
+			if aDetects {
+				h.next = append(h.next, newHitContact(a, b))
+			}
+			if bDetects {
+				h.next = append(h.next, newHitContact(b, a))
+			}
+		}
+	}
+
+	next := make(map[hitPair]struct{}, len(h.next))
+	for i := range h.next {
+		c := &h.next[i]
+		next[c.pair] = struct{}{}
+
+		if _, ok := h.active[c.pair]; ok {
+			c.event.Phase = HitStay
+		}
+
+		c.pair.src.(HitShaper).HitEvent(c.event)
+	}
+
+	for _, c := range h.contacts {
+		if _, ok := next[c.pair]; ok || c.pair.src.IsDisposed() {
+			continue
+		}
+
+		// contacts of entities that were not updated, such as those
+		// in sleeping regions, are kept until both are updated again
+		_, srcUpdated := updated[c.pair.src]
+		_, dstUpdated := updated[c.pair.dst]
+		if (!srcUpdated || !dstUpdated) && !c.pair.dst.IsDisposed() {
+			next[c.pair] = struct{}{}
+			h.next = append(h.next, c)
+			continue
+		}
+
+		c.event.Phase = HitEnd
+		c.pair.src.(HitShaper).HitEvent(c.event)
+	}
+
+	h.active = next
+
+	for i := range h.contacts {
+		h.contacts[i] = hitContact{}
+	}
+	h.contacts, h.next = h.next, h.contacts[:0]
+
+	for i := range h.refs {
+		h.refs[i] = hitRef{}
+	}
+	h.refs = h.refs[:0]
+}
+
+// remove drops the contacts involving an entity that left
+// the Context, dispatching HitEnd events for them to entities
+// that are not disposed.
+func (h *hitTracker) remove(e Entity) {
+	n := 0
+	for _, c := range h.contacts {
+		if c.pair.src != e && c.pair.dst != e {
+			h.contacts[n] = c
+			n++
+			continue
+		}
+
+		delete(h.active, c.pair)
+
+		if !c.pair.src.IsDisposed() {
+			c.event.Phase = HitEnd
+			c.pair.src.(HitShaper).HitEvent(c.event)
+		}
+	}
+
+	for i := n; i < len(h.contacts); i++ {
+		h.contacts[i] = hitContact{}
+	}
+	h.contacts = h.contacts[:n]
+}
+
+func newHitContact(src, dst *hitRef) hitContact {
+	return hitContact{
+		pair: hitPair{
+			src:      src.entity,
+			dst:      dst.entity,
+			srcShape: src.shape.Name,
+			dstShape: dst.shape.Name,
+		},
+		event: HitEvent{
+			Phase:      HitBegin,
+			Shape:      src.shape,
+			Other:      dst.entity,
+			OtherShape: dst.shape,
+		},
+	}
+}
+
+// rectBounds returns an integer rectangle
+// as an AABB at a float position.
+func rectBounds(r image.Rectangle, pos Vec2) AABB {
+	return AABB{
+		Min: Vec2{X: float64(r.Min.X), Y: float64(r.Min.Y)},
+		Max: Vec2{X: float64(r.Max.X), Y: float64(r.Max.Y)},
+	}.Translate(pos)
+}
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"image"
+	"testing"
+)
+
+type testHitEntity struct {
+	CoreEntity
+	shapes []HitShape
+	events []HitEvent
+}
+
+func (e *testHitEntity) Class() string {
+	return "test"
+}
+
+func (e *testHitEntity) HitShapes() []HitShape {
+	return e.shapes
+}
+
+func (e *testHitEntity) HitEvent(event HitEvent) {
+	e.events = append(e.events, event)
+}
+
+func TestHitTracker(t *testing.T) {
+
+	const (
+		layerPlayer = 1 << iota
+		layerEnemy
+	)
+
+	player := &testHitEntity{
+		shapes: []HitShape{
+			{
+				Name:  "sword",
+				Shape: Circle{Offset: Vec2{X: 10}, Radius: 5},
+				Mask:  layerEnemy,
+			},
+			{
+				Name:  "hurtbox",
+				Shape: AABB{Min: Vec2{X: -2, Y: -2}, Max: Vec2{X: 2, Y: 2}},
+				Layer: layerPlayer,
+			},
+		},
+	}
+
+	enemy := &testHitEntity{
+		shapes: []HitShape{
+			{
+				Name:  "hurtbox",
+				Shape: AABB{Min: Vec2{X: -2, Y: -2}, Max: Vec2{X: 2, Y: 2}},
+				Layer: layerEnemy,
+			},
+		},
+	}
+	enemy.X = 14.5
+
+	h := newHitTracker()
+	entries := []PartitionEntry{player, enemy}
+
+	for i := 0; i < 3; i++ {
+		h.update(entries)
+	}
+
+	enemy.X = 100
+	h.update(entries)
+
+	expected := []HitPhase{HitBegin, HitStay, HitStay, HitEnd}
+	if len(player.events) != len(expected) {
+		t.Fatalf("Expected %d events got %d", len(expected), len(player.events))
+	}
+
+	for i, event := range player.events {
+		if event.Phase != expected[i] {
+			t.Fatalf("Expected %v got %v", expected[i], event.Phase)
+		}
+
+		if event.Shape.Name != "sword" || event.OtherShape.Name != "hurtbox" || event.Other != enemy {
+			t.Fatalf("Expected sword hitting enemy hurtbox got %+v", event)
+		}
+	}
+
+	// the enemy does not detect the player
+	if len(enemy.events) != 0 {
+		t.Fatalf("Expected 0 events got %d", len(enemy.events))
+	}
+}
+
+// testValueEntity is not comparable, as it holds a slice.
+type testValueEntity struct {
+	*CoreEntity
+	tags []string
+}
+
+func (e testValueEntity) Class() string {
+	return "value"
+}
+
+func TestHitTrackerInvalid(t *testing.T) {
+
+	// entities must be pointers
+	func() {
+		defer func() {
+			if r := recover(); r != "engine: entity engine.testValueEntity must be a pointer" {
+				t.Fatalf("Expected panic adding a non-pointer entity got %v", r)
+			}
+		}()
+
+		ctx := NewContext(nil, nil, nil)
+		ctx.AddEntity(testValueEntity{CoreEntity: new(CoreEntity)})
+	}()
+
+	// shape names must be unique
+	func() {
+		defer func() {
+			if r := recover(); r != "engine: duplicate hit shape hurtbox on *engine.testHitEntity" {
+				t.Fatalf("Expected panic for duplicate hit shapes got %v", r)
+			}
+		}()
+
+		e := &testHitEntity{
+			shapes: []HitShape{
+				{Name: "hurtbox", Shape: Circle{Radius: 1}},
+				{Name: "hurtbox", Shape: Circle{Radius: 2}},
+			},
+		}
+
+		newHitTracker().update([]PartitionEntry{e})
+	}()
+}
+
+func TestHitTrackerRemove(t *testing.T) {
+
+	r := &testRenderer{viewport: image.Rect(0, 0, 200, 200)}
+	ctx := NewContext(r, nil, nil)
+
+	player := &testHitEntity{
+		shapes: []HitShape{{Name: "sword", Shape: Circle{Radius: 5}, Mask: 1}},
+	}
+	enemy := &testHitEntity{
+		shapes: []HitShape{{Name: "hurtbox", Shape: Circle{Radius: 5}, Layer: 1}},
+	}
+	player.X, player.Y = 50, 50
+	enemy.X, enemy.Y = 55, 50
+
+	ctx.AddEntity(player, enemy)
+	ctx.Tick()
+
+	// removing an entity ends its contacts
+	ctx.RemoveEntity(enemy)
+	ctx.Tick()
+
+	if len(ctx.hits.contacts) != 0 {
+		t.Fatalf("Expected %v contacts got %v", 0, len(ctx.hits.contacts))
+	}
+
+	// and it begins new contacts when added again
+	ctx.AddEntity(enemy)
+	ctx.Tick()
+
+	expected := []HitPhase{HitBegin, HitEnd, HitBegin}
+	if len(player.events) != len(expected) {
+		t.Fatalf("Expected %v got %v", expected, player.events)
+	}
+
+	for i, event := range player.events {
+		if event.Phase != expected[i] {
+			t.Fatalf("Expected %v got %v", expected[i], event.Phase)
+		}
+	}
+}
@@ -11,6 +11,8 @@ type Image interface {
 	Offset(float64, float64)
 
 	// Scale sets the x y scale of the image relative to the origin.
+	// The image is scaled about its origin, so a negative
+	// scale mirrors it in place.
 	Scale(float64, float64)
 
 	// Rotate sets the rotation in radians relative to the origin.
@@ -58,3 +60,48 @@ type Image interface {
 	// Class returns the image class.
 	Class() string
 }
+
+// ImageGroup is an Image drawn as a group of other Images,
+// such as a skeleton of atlas images. A Context adds and
+// removes the parts of a group in place of the group itself,
+// and ticks the group after the entity it belongs to.
+type ImageGroup interface {
+	Image
+
+	// Parts returns the Images drawn by the group.
+	Parts() []Image
+	// Tick updates the parts of the group.
+	Tick()
+}
+
+// expandImages returns images with each ImageGroup replaced by its parts.
+func expandImages(images []Image) []Image {
+	var (
+		expanded []Image
+		found    bool
+	)
+
+	for i, img := range images {
+		g, ok := img.(ImageGroup)
+		if !ok {
+			if found {
+				expanded = append(expanded, img)
+			}
+
+			continue
+		}
+
+		if !found {
+			expanded = append(expanded, images[:i]...)
+			found = true
+		}
+
+		expanded = append(expanded, g.Parts()...)
+	}
+
+	if !found {
+		return images
+	}
+
+	return expanded
+}
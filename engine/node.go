@@ -0,0 +1,195 @@
+package engine
+
+import "math"
+
+// Node is an Entity that can be part of an entity hierarchy.
+// Node is implemented by embedding CoreEntity.
+type Node interface {
+	Entity
+	node() *CoreEntity
+}
+
+// Transform is a position, rotation, scale and alpha.
+type Transform struct {
+	Position Vec2
+	// Rotation is in radians.
+	Rotation float64
+	Scale    Vec2
+	Alpha    float64
+}
+
+// identityTransform does not modify what it is applied to.
+var identityTransform = Transform{
+	Scale: Vec2{X: 1, Y: 1},
+	Alpha: 1,
+}
+
+// Apply returns a child transform relative to t.
+func (t Transform) Apply(child Transform) Transform {
+	pos := Vec2{
+		X: child.Position.X * t.Scale.X,
+		Y: child.Position.Y * t.Scale.Y,
+	}
+
+	sin, cos := math.Sincos(t.Rotation)
+
+	return Transform{
+		Position: Vec2{
+			X: t.Position.X + pos.X*cos - pos.Y*sin,
+			Y: t.Position.Y + pos.X*sin + pos.Y*cos,
+		},
+		Rotation: t.Rotation + child.Rotation,
+		Scale: Vec2{
+			X: t.Scale.X * child.Scale.X,
+			Y: t.Scale.Y * child.Scale.Y,
+		},
+		Alpha: t.Alpha * child.Alpha,
+	}
+}
+
+func (e *CoreEntity) node() *CoreEntity {
+	return e
+}
+
+// AddChild attaches Nodes to the CoreEntity. The position of a
+// child is relative to its parent, and it inherits the rotation,
+// scale and alpha of its parent. Children are removed from any
+// previous parent, and are added to the Context of their parent.
+// It panics if a child is the CoreEntity or one of its ancestors.
+func (e *CoreEntity) AddChild(children ...Node) {
+	for _, child := range children {
+		n := child.node()
+		for a := e; a != nil; a = a.parent {
+			if a == n {
+				panic("engine: cannot add an entity or its ancestor as a child")
+			}
+		}
+
+		if n.parent != nil {
+			n.parent.RemoveChild(child)
+		}
+
+		n.parent = e
+		e.children = append(e.children, child)
+
+		if e.ctx != nil && n.ctx != e.ctx {
+			n.ctx = e.ctx
+			e.ctx.AddEntity(child)
+		}
+	}
+}
+
+// RemoveChild detaches a Node from the CoreEntity.
+// The child keeps its world position.
+func (e *CoreEntity) RemoveChild(child Node) {
+	for i, c := range e.children {
+		if c != child {
+			continue
+		}
+
+		n := child.node()
+		world := n.WorldTransform()
+
+		copy(e.children[i:], e.children[i+1:])
+		e.children[len(e.children)-1] = nil
+		e.children = e.children[:len(e.children)-1]
+
+		n.parent = nil
+		n.Vec2 = world.Position
+		n.prevPos = world.Position
+
+		return
+	}
+}
+
+// Parent returns the parent of the CoreEntity, if any.
+func (e *CoreEntity) Parent() *CoreEntity {
+	return e.parent
+}
+
+// Children returns the children of the CoreEntity.
+func (e *CoreEntity) Children() []Node {
+	return e.children
+}
+
+// SetRotation sets the rotation of the CoreEntity relative to its parent.
+func (e *CoreEntity) SetRotation(d float64) {
+	e.localTransform().Rotation = d
+}
+
+// SetScale sets the scale of the CoreEntity relative to its parent.
+func (e *CoreEntity) SetScale(x, y float64) {
+	e.localTransform().Scale = Vec2{X: x, Y: y}
+}
+
+// SetAlpha sets the alpha of the CoreEntity relative to its parent.
+func (e *CoreEntity) SetAlpha(a float64) {
+	e.localTransform().Alpha = a
+}
+
+// LocalTransform returns the position, rotation, scale
+// and alpha of the CoreEntity relative to its parent.
+func (e *CoreEntity) LocalTransform() Transform {
+	t := identityTransform
+	if e.transform != nil {
+		t = *e.transform
+	}
+
+	t.Position = e.Vec2
+	return t
+}
+
+// WorldTransform returns the transform of the CoreEntity,
+// including the transforms of all of its ancestors.
+func (e *CoreEntity) WorldTransform() Transform {
+	t := e.LocalTransform()
+	if e.parent == nil {
+		return t
+	}
+
+	return e.parent.WorldTransform().Apply(t)
+}
+
+// localTransform returns the local transform,
+// allocating it on first use.
+func (e *CoreEntity) localTransform() *Transform {
+	if e.transform == nil {
+		t := identityTransform
+		e.transform = &t
+	}
+
+	return e.transform
+}
+
+// transformed indicates whether the CoreEntity or any
+// ancestor has a rotation, scale or alpha.
+func (e *CoreEntity) transformed() bool {
+	for n := e; n != nil; n = n.parent {
+		if n.transform != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateImages moves the images of the CoreEntity and its
+// descendants to their world transforms.
+func (e *CoreEntity) updateImages() {
+	t := e.WorldTransform()
+	transformed := e.transformed()
+
+	for _, img := range e.images {
+		img.Translate(t.Position.X, t.Position.Y)
+
+		if transformed {
+			img.Rotate(t.Rotation)
+			img.Scale(t.Scale.X, t.Scale.Y)
+			img.Alpha(t.Alpha)
+		}
+	}
+
+	for _, child := range e.children {
+		child.node().updateImages()
+	}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+type testNode struct {
+	CoreEntity
+	disposed int
+}
+
+func (n *testNode) Class() string {
+	return "node"
+}
+
+func (n *testNode) Dispose() {
+	n.disposed++
+	n.CoreEntity.Dispose()
+}
+
+func TestWorldTransform(t *testing.T) {
+
+	parent := &testNode{CoreEntity: CoreEntity{Vec2: Vec2{X: 10, Y: 10}}}
+	parent.SetRotation(math.Pi / 2)
+	parent.SetScale(2, 2)
+	parent.SetAlpha(0.5)
+
+	child := &testNode{CoreEntity: CoreEntity{Vec2: Vec2{X: 5}}}
+	child.SetAlpha(0.5)
+	parent.AddChild(child)
+
+	world := child.WorldTransform()
+
+	expected := Vec2{X: 10, Y: 20}
+	if math.Abs(world.Position.X-expected.X) > 1e-9 || math.Abs(world.Position.Y-expected.Y) > 1e-9 {
+		t.Fatalf("Expected %v got %v", expected, world.Position)
+	}
+
+	if world.Rotation != math.Pi/2 || world.Scale != (Vec2{X: 2, Y: 2}) || world.Alpha != 0.25 {
+		t.Fatalf("Expected rotation, scale and alpha to be inherited. Got %+v", world)
+	}
+
+	if child.Position() != world.Position {
+		t.Fatalf("Expected %v got %v", world.Position, child.Position())
+	}
+
+	// detaching keeps the world position
+	parent.RemoveChild(child)
+	if child.Parent() != nil || len(parent.Children()) != 0 {
+		t.Fatal("Expected child to be detached.")
+	}
+	if math.Abs(child.X-expected.X) > 1e-9 || math.Abs(child.Y-expected.Y) > 1e-9 {
+		t.Fatalf("Expected %v got %v", expected, child.Vec2)
+	}
+}
+
+func TestAddChildCycle(t *testing.T) {
+
+	parent := new(testNode)
+	child := new(testNode)
+	grandchild := new(testNode)
+
+	parent.AddChild(child)
+	child.AddChild(grandchild)
+
+	for _, n := range []*testNode{grandchild, child, parent} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatal("Expected panic adding an ancestor as a child.")
+				}
+			}()
+
+			grandchild.AddChild(n)
+		}()
+	}
+
+	if grandchild.Parent() != &child.CoreEntity || len(grandchild.Children()) != 0 {
+		t.Fatal("Expected hierarchy to be unchanged.")
+	}
+}
+
+func TestHierarchyContext(t *testing.T) {
+
+	ctx := NewContext(&testRenderer{viewport: image.Rect(0, 0, 200, 200)}, nil, nil)
+
+	parent := &testNode{CoreEntity: CoreEntity{Vec2: Vec2{X: 50, Y: 50}}}
+	child := &testNode{CoreEntity: CoreEntity{Vec2: Vec2{X: 5}}}
+	grandchild := &testNode{CoreEntity: CoreEntity{Vec2: Vec2{Y: 5}}}
+
+	parent.AddChild(child)
+	child.AddChild(grandchild)
+
+	ctx.AddEntity(parent)
+	ctx.Tick()
+
+	if n := len(ctx.QueryRadius(Vec2{X: 50, Y: 50}, 10, nil)); n != 3 {
+		t.Fatalf("Expected 3 entities got %d", n)
+	}
+
+	// children added later join the context
+	late := &testNode{}
+	parent.AddChild(late)
+	ctx.Tick()
+
+	if n := len(ctx.QueryRadius(Vec2{X: 50, Y: 50}, 10, nil)); n != 4 {
+		t.Fatalf("Expected 4 entities got %d", n)
+	}
+
+	parent.Dispose()
+	if child.disposed != 1 || grandchild.disposed != 1 || late.disposed != 1 {
+		t.Fatal("Expected children to be disposed once.")
+	}
+
+	if n := len(ctx.QueryRadius(Vec2{X: 50, Y: 50}, 10, nil)); n != 0 {
+		t.Fatalf("Expected 0 entities got %d", n)
+	}
+}
@@ -1,5 +1,11 @@
 package engine
 
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
 // PartitionMap handles spatial partitioning of PartitionEntry.
 type PartitionMap struct {
 	partitions    map[[2]int][]PartitionEntry
@@ -7,6 +13,9 @@ type PartitionMap struct {
 
 	buffer       map[string][]PartitionEntry
 	linearBuffer []PartitionEntry
+	moved        []PartitionEntry
+
+	onDispose func(PartitionEntry)
 }
 
 // PartitionEntry is a type that can be used in a PartitionMap.
@@ -16,6 +25,18 @@ type PartitionEntry interface {
 	Class() string
 }
 
+// EntryFilter reports whether a PartitionEntry
+// should be included in the results of a query.
+type EntryFilter func(PartitionEntry) bool
+
+// RayHit is a PartitionEntry intersected by a raycast.
+type RayHit struct {
+	Entry PartitionEntry
+	// Distance is the distance along the ray
+	// to the point closest to the entry.
+	Distance float64
+}
+
 // NewPartitionMap returns a PartitionMap with a given range interval, and initial map bucket count.
 func NewPartitionMap(partitionSize, bucketCount int) *PartitionMap {
 	return &PartitionMap{
@@ -37,37 +58,92 @@ func (pm *PartitionMap) Add(e PartitionEntry) [2]int {
 	return key
 }
 
+// Remove removes a PartitionEntry from the PartitionMap,
+// and indicates whether it was found. Entries are searched
+// for in the partition of their current position first.
+func (pm *PartitionMap) Remove(e PartitionEntry) bool {
+	key := pm.positionToKey(e.Position())
+	if pm.removeFrom(key, e) {
+		return true
+	}
+
+	// the entry has moved since its partition was updated
+	for k := range pm.partitions {
+		if k != key && pm.removeFrom(k, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pm *PartitionMap) removeFrom(key [2]int, e PartitionEntry) bool {
+	entries := pm.partitions[key]
+
+	for i, entry := range entries {
+		if entry != e {
+			continue
+		}
+
+		copy(entries[i:], entries[i+1:])
+		entries[len(entries)-1] = nil
+
+		if len(entries) == 1 {
+			delete(pm.partitions, key)
+		} else {
+			pm.partitions[key] = entries[:len(entries)-1]
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// OnDispose sets a function called with every disposed
+// entry as it is dropped from the PartitionMap.
+func (pm *PartitionMap) OnDispose(fn func(PartitionEntry)) {
+	pm.onDispose = fn
+}
+
 // Tick updates partitions around a position.
+// Entries remain queryable while tickFunc is called,
+// and are moved to the partition matching their
+// position afterwards. Disposed entries are dropped.
 func (pm *PartitionMap) Tick(
 	pos Vec2,
 	size int,
 	tickFunc func([]PartitionEntry),
 ) {
-	px, py := int(pos.X)/pm.partitionSize, int(pos.Y)/pm.partitionSize
+	center := pm.positionToKey(pos)
 
-	// update buffer and clear partitions
-	for x := px - size; x <= px+size; x++ {
-		for y := py - size; y <= py+size; y++ {
-			key := [2]int{x, y}
-			entries := pm.partitions[key]
-
-			for _, e := range entries {
-				if e.IsDisposed() {
-					continue
-				}
+	var keys [][2]int
+	for x := center[0] - size; x <= center[0]+size; x++ {
+		for y := center[1] - size; y <= center[1]+size; y++ {
+			keys = append(keys, [2]int{x, y})
+		}
+	}
 
-				pm.buffer[e.Class()] = append(
-					pm.buffer[e.Class()],
-					e,
-				)
-			}
+	pm.TickKeys(keys, tickFunc)
+}
 
-			// clear partition
-			for i := 0; i < len(entries); i++ {
-				entries[i] = nil
+// TickKeys updates the partitions with the given keys,
+// as Tick does. Keys must not contain duplicates.
+func (pm *PartitionMap) TickKeys(
+	keys [][2]int,
+	tickFunc func([]PartitionEntry),
+) {
+	// update buffer
+	for _, key := range keys {
+		for _, e := range pm.partitions[key] {
+			if e.IsDisposed() {
+				continue
 			}
 
-			pm.partitions[key] = entries[:0]
+			pm.buffer[e.Class()] = append(
+				pm.buffer[e.Class()],
+				e,
+			)
 		}
 	}
 
@@ -80,24 +156,304 @@ func (pm *PartitionMap) Tick(
 		tickFunc(pm.linearBuffer)
 	}
 
+	// move entries to their new partitions
+	for _, key := range keys {
+		entries := pm.partitions[key]
+
+		n := 0
+		for _, e := range entries {
+			if e.IsDisposed() {
+				if pm.onDispose != nil {
+					pm.onDispose(e)
+				}
+				continue
+			}
+
+			if pm.positionToKey(e.Position()) != key {
+				pm.moved = append(pm.moved, e)
+				continue
+			}
+
+			entries[n] = e
+			n++
+		}
+
+		// clear partition
+		for i := n; i < len(entries); i++ {
+			entries[i] = nil
+		}
+
+		if n == 0 {
+			delete(pm.partitions, key)
+		} else {
+			pm.partitions[key] = entries[:n]
+		}
+	}
+
+	for i, e := range pm.moved {
+		pm.Add(e)
+		pm.moved[i] = nil
+	}
+	pm.moved = pm.moved[:0]
+
 	// Clear buffers
 	for class, entries := range pm.buffer {
-		pm.buffer[class] = pm.buffer[class][:0]
-
-		// return entry to partition map
-		for _, entry := range entries {
-			pm.Add(entry)
+		for i := range entries {
+			entries[i] = nil
 		}
+
+		pm.buffer[class] = entries[:0]
 	}
 
+	for i := range pm.linearBuffer {
+		pm.linearBuffer[i] = nil
+	}
 	pm.linearBuffer = pm.linearBuffer[:0]
 }
 
+// Entries returns every entry that is not disposed,
+// ordered by partition, then by insertion.
+func (pm *PartitionMap) Entries() []PartitionEntry {
+	keys := make([][2]int, 0, len(pm.partitions))
+	for key := range pm.partitions {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][1] != keys[j][1] {
+			return keys[i][1] < keys[j][1]
+		}
+		return keys[i][0] < keys[j][0]
+	})
+
+	var entries []PartitionEntry
+	for _, key := range keys {
+		for _, e := range pm.partitions[key] {
+			if !e.IsDisposed() {
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	return entries
+}
+
 // Class returns all entries of a given class in the current buffer.
 func (pm *PartitionMap) Class(class string) []PartitionEntry {
 	return pm.buffer[class]
 }
 
+// QueryRect returns all entries positioned within a region.
+// A nil filter includes every entry.
+func (pm *PartitionMap) QueryRect(r AABB, filter EntryFilter) []PartitionEntry {
+	var entries []PartitionEntry
+
+	min, max := pm.positionToKey(r.Min), pm.positionToKey(r.Max)
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			for _, e := range pm.partitions[[2]int{x, y}] {
+				if pm.include(e, filter) && r.Contains(e.Position()) {
+					entries = append(entries, e)
+				}
+			}
+		}
+	}
+
+	return entries
+}
+
+// QueryRadius returns all entries positioned within
+// a radius of a position. A nil filter includes every entry.
+func (pm *PartitionMap) QueryRadius(pos Vec2, radius float64, filter EntryFilter) []PartitionEntry {
+	var entries []PartitionEntry
+
+	min := pm.positionToKey(Vec2{X: pos.X - radius, Y: pos.Y - radius})
+	max := pm.positionToKey(Vec2{X: pos.X + radius, Y: pos.Y + radius})
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			for _, e := range pm.partitions[[2]int{x, y}] {
+				if pm.include(e, filter) && pos.Distance(e.Position()) <= radius {
+					entries = append(entries, e)
+				}
+			}
+		}
+	}
+
+	return entries
+}
+
+// Nearest returns up to n entries closest to a position within
+// a maximum distance, ordered by distance. The maximum distance
+// must be finite. A nil filter includes every entry.
+func (pm *PartitionMap) Nearest(pos Vec2, n int, maxDist float64, filter EntryFilter) []PartitionEntry {
+	if n <= 0 {
+		return nil
+	}
+
+	// the n closest entries found, furthest first
+	candidates := &nearestHeap{}
+	seq := 0
+
+	center := pm.positionToKey(pos)
+	size := float64(pm.partitionSize)
+	visited := 0
+
+	// search rings of partitions outwards, until no unsearched
+	// partition can contain a closer entry, or every populated
+	// partition has been searched
+	for ring := 0; float64(ring-1)*size <= maxDist && visited < len(pm.partitions); ring++ {
+		for x := center[0] - ring; x <= center[0]+ring; x++ {
+			for y := center[1] - ring; y <= center[1]+ring; y++ {
+				if x != center[0]-ring && x != center[0]+ring &&
+					y != center[1]-ring && y != center[1]+ring {
+					continue
+				}
+
+				entries, ok := pm.partitions[[2]int{x, y}]
+				if !ok {
+					continue
+				}
+				visited++
+
+				for _, e := range entries {
+					if !pm.include(e, filter) {
+						continue
+					}
+
+					d := pos.Distance(e.Position())
+					if d > maxDist {
+						continue
+					}
+
+					c := nearestCandidate{e: e, d: d, seq: seq}
+					seq++
+
+					if candidates.Len() < n {
+						heap.Push(candidates, c)
+					} else if c.less((*candidates)[0]) {
+						(*candidates)[0] = c
+						heap.Fix(candidates, 0)
+					}
+				}
+			}
+		}
+
+		if candidates.Len() == n && (*candidates)[0].d <= float64(ring)*size {
+			break
+		}
+	}
+
+	sort.Slice(*candidates, func(i, j int) bool {
+		return (*candidates)[i].less((*candidates)[j])
+	})
+
+	entries := make([]PartitionEntry, candidates.Len())
+	for i, c := range *candidates {
+		entries[i] = c.e
+	}
+
+	return entries
+}
+
+type nearestCandidate struct {
+	e PartitionEntry
+	d float64
+	// seq orders candidates of equal distance by discovery
+	seq int
+}
+
+func (c nearestCandidate) less(o nearestCandidate) bool {
+	if c.d != o.d {
+		return c.d < o.d
+	}
+
+	return c.seq < o.seq
+}
+
+// nearestHeap is a max-heap of nearestCandidates.
+type nearestHeap []nearestCandidate
+
+func (h nearestHeap) Len() int { return len(h) }
+
+func (h nearestHeap) Less(i, j int) bool { return h[j].less(h[i]) }
+
+func (h nearestHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *nearestHeap) Push(x interface{}) { *h = append(*h, x.(nearestCandidate)) }
+
+func (h *nearestHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	old[len(old)-1] = nearestCandidate{}
+	*h = old[:len(old)-1]
+
+	return c
+}
+
+// Raycast returns all entries within a radius of a ray
+// cast from an origin along an angle in radians, up to a
+// maximum distance. Hits are ordered by distance along the ray.
+// A nil filter includes every entry.
+func (pm *PartitionMap) Raycast(origin Vec2, angle, dist, radius float64, filter EntryFilter) []RayHit {
+	var hits []RayHit
+
+	end := origin.Translate(angle, dist)
+	dir := Vec2{}.Translate(angle, 1)
+
+	min := pm.positionToKey(Vec2{
+		X: math.Min(origin.X, end.X) - radius,
+		Y: math.Min(origin.Y, end.Y) - radius,
+	})
+	max := pm.positionToKey(Vec2{
+		X: math.Max(origin.X, end.X) + radius,
+		Y: math.Max(origin.Y, end.Y) + radius,
+	})
+
+	size := float64(pm.partitionSize)
+	reach := radius + size*math.Sqrt2/2
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			// skip partitions far from the ray
+			c := Vec2{X: (float64(x) + 0.5) * size, Y: (float64(y) + 0.5) * size}
+			if _, d := rayDistance(origin, dir, dist, c); d > reach {
+				continue
+			}
+
+			for _, e := range pm.partitions[[2]int{x, y}] {
+				if !pm.include(e, filter) {
+					continue
+				}
+
+				if t, d := rayDistance(origin, dir, dist, e.Position()); d <= radius {
+					hits = append(hits, RayHit{Entry: e, Distance: t})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+
+	return hits
+}
+
+// rayDistance returns the distance along a ray to the point
+// closest to p, and the distance from that point to p.
+func rayDistance(origin, dir Vec2, dist float64, p Vec2) (float64, float64) {
+	t := math.Max(0, math.Min(dist, p.Sub(origin).Dot(dir)))
+	return t, origin.Add(dir.Scale(t)).Distance(p)
+}
+
+func (pm *PartitionMap) include(e PartitionEntry, filter EntryFilter) bool {
+	return !e.IsDisposed() && (filter == nil || filter(e))
+}
+
 func (pm *PartitionMap) positionToKey(pos Vec2) [2]int {
-	return [2]int{int(pos.X) / pm.partitionSize, int(pos.Y) / pm.partitionSize}
+	return [2]int{
+		int(math.Floor(pos.X / float64(pm.partitionSize))),
+		int(math.Floor(pos.Y / float64(pm.partitionSize))),
+	}
 }
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+type testEntry struct {
+	Vec2
+	class    string
+	disposed bool
+}
+
+func (e *testEntry) IsDisposed() bool { return e.disposed }
+func (e *testEntry) Position() Vec2   { return e.Vec2 }
+func (e *testEntry) Class() string    { return e.class }
+
+func newTestPartitionMap() (*PartitionMap, []*testEntry) {
+	pm := NewPartitionMap(100, 10)
+
+	entries := []*testEntry{
+		{Vec2: Vec2{X: 10, Y: 10}, class: "enemy"},
+		{Vec2: Vec2{X: 150, Y: 10}, class: "enemy"},
+		{Vec2: Vec2{X: -60, Y: -20}, class: "enemy"},
+		{Vec2: Vec2{X: 40, Y: 30}, class: "item"},
+		{Vec2: Vec2{X: 500, Y: 500}, class: "enemy"},
+	}
+
+	for _, e := range entries {
+		pm.Add(e)
+	}
+
+	return pm, entries
+}
+
+func TestPartitionQueries(t *testing.T) {
+
+	pm, entries := newTestPartitionMap()
+	enemies := func(e PartitionEntry) bool {
+		return e.Class() == "enemy"
+	}
+
+	if r := pm.QueryRadius(Vec2{}, 100, enemies); len(r) != 2 {
+		t.Fatalf("Expected 2 entries got %d", len(r))
+	}
+
+	if r := pm.QueryRadius(Vec2{}, 100, nil); len(r) != 3 {
+		t.Fatalf("Expected 3 entries got %d", len(r))
+	}
+
+	if r := pm.QueryRect(AABB{Max: Vec2{X: 200, Y: 200}}, nil); len(r) != 3 {
+		t.Fatalf("Expected 3 entries got %d", len(r))
+	}
+
+	r := pm.Nearest(Vec2{X: 140}, 2, 1000, enemies)
+	if len(r) != 2 || r[0] != entries[1] || r[1] != entries[0] {
+		t.Fatalf("Expected %v got %v", []PartitionEntry{entries[1], entries[0]}, r)
+	}
+
+	if r := pm.Nearest(Vec2{}, 10, 1000, nil); len(r) != len(entries) {
+		t.Fatalf("Expected %d entries got %d", len(entries), len(r))
+	}
+
+	// the search ends once every populated partition is searched
+	if r := pm.Nearest(Vec2{}, 10, 1e12, nil); len(r) != len(entries) {
+		t.Fatalf("Expected %d entries got %d", len(entries), len(r))
+	}
+
+	hits := pm.Raycast(Vec2{X: -100, Y: 10}, 0, 300, 5, nil)
+	if len(hits) != 2 || hits[0].Entry != entries[0] || hits[1].Entry != entries[1] {
+		t.Fatalf("Expected hits on %v and %v got %v", entries[0], entries[1], hits)
+	}
+	if math.Abs(hits[0].Distance-110) > 1e-9 {
+		t.Fatalf("Expected distance 110 got %f", hits[0].Distance)
+	}
+
+	entries[0].disposed = true
+	if r := pm.QueryRadius(Vec2{}, 100, enemies); len(r) != 1 {
+		t.Fatalf("Expected 1 entry got %d", len(r))
+	}
+}
+
+func TestPartitionTick(t *testing.T) {
+
+	pm, entries := newTestPartitionMap()
+
+	pm.Tick(Vec2{}, 1, func(active []PartitionEntry) {
+		if len(active) != 4 {
+			t.Fatalf("Expected 4 active entries got %d", len(active))
+		}
+
+		// entries are queryable while ticking
+		if r := pm.QueryRadius(Vec2{}, 100, nil); len(r) != 3 {
+			t.Fatalf("Expected 3 entries got %d", len(r))
+		}
+
+		entries[1].X = 480
+		entries[1].Y = 480
+	})
+
+	if r := pm.QueryRadius(Vec2{X: 500, Y: 500}, 50, nil); len(r) != 2 {
+		t.Fatalf("Expected 2 entries got %d", len(r))
+	}
+
+	if r := pm.QueryRadius(Vec2{}, 1000, nil); len(r) != len(entries) {
+		t.Fatalf("Expected %d entries got %d", len(entries), len(r))
+	}
+}
@@ -0,0 +1,514 @@
+package engine
+
+import (
+	"container/heap"
+	"image"
+	"math"
+)
+
+// Connectivity indicates which adjacent tiles
+// are considered neighbors during pathfinding.
+type Connectivity byte
+
+const (
+	// Connect4 connects tiles orthogonally.
+	Connect4 Connectivity = 4
+
+	// Connect8 connects tiles orthogonally and diagonally.
+	// Diagonal moves may not cut the corner of a solid tile.
+	Connect8 Connectivity = 8
+)
+
+// maxCachedPaths is the number of paths a Pathfinder
+// retains before its path cache is cleared.
+const maxCachedPaths = 1024
+
+// maxCachedFields is the number of flow fields a Pathfinder
+// retains. The least recently used field is discarded first.
+const maxCachedFields = 16
+
+var pdirs = [8]image.Point{
+	image.Pt(1, 0),
+	image.Pt(0, 1),
+	image.Pt(-1, 0),
+	image.Pt(0, -1),
+	image.Pt(1, 1),
+	image.Pt(-1, 1),
+	image.Pt(-1, -1),
+	image.Pt(1, -1),
+}
+
+// Pathfinder finds paths between tiles of a Tilemap.
+// Tiles are traversable if they are not solid, and
+// tile definition costs are used as movement costs.
+//
+// Results are cached. Cached paths are invalidated when any
+// region of the Tilemap is marked dirty, and flow fields
+// when an overlapping region is marked dirty.
+type Pathfinder struct {
+	tmap         *Tilemap
+	connectivity Connectivity
+
+	rev    uint64
+	paths  map[pathKey]cachedPath
+	fields map[fieldKey]*FlowField
+	// fieldOrder holds the keys of fields,
+	// from least to most recently used
+	fieldOrder []fieldKey
+}
+
+type pathKey struct {
+	start, goal image.Point
+	size        int
+}
+
+type fieldKey struct {
+	goal         image.Point
+	size, radius int
+}
+
+type cachedPath struct {
+	path []image.Point
+}
+
+// NewPathfinder returns an instantiated *Pathfinder.
+func NewPathfinder(tmap *Tilemap, connectivity Connectivity) *Pathfinder {
+	return &Pathfinder{
+		tmap:         tmap,
+		connectivity: connectivity,
+		rev:          tmap.Revision(),
+		paths:        make(map[pathKey]cachedPath),
+		fields:       make(map[fieldKey]*FlowField),
+	}
+}
+
+// Invalidate clears all cached results.
+func (pf *Pathfinder) Invalidate() {
+	pf.paths = make(map[pathKey]cachedPath)
+	pf.fields = make(map[fieldKey]*FlowField)
+	pf.fieldOrder = nil
+	pf.rev = pf.tmap.Revision()
+}
+
+// sync discards cached results affected by tilemap edits.
+// An edit anywhere may open a shorter route, or a failed
+// one, so every cached path is discarded.
+func (pf *Pathfinder) sync() {
+	regions, rev := pf.tmap.DirtySince(pf.rev)
+	pf.rev = rev
+
+	if len(regions) > 0 && len(pf.paths) > 0 {
+		pf.paths = make(map[pathKey]cachedPath)
+	}
+
+	for _, region := range regions {
+		for k, ff := range pf.fields {
+			if ff.bounds.Overlaps(region) {
+				pf.removeField(k)
+			}
+		}
+	}
+}
+
+// FindPath returns the lowest cost path from start to goal using A*,
+// for an agent occupying size by size tiles. The path includes
+// both the start and goal. False is returned if no path exists.
+func (pf *Pathfinder) FindPath(start, goal image.Point, size int) ([]image.Point, bool) {
+
+	if size < 1 {
+		panic("invalid size")
+	}
+
+	pf.sync()
+
+	key := pathKey{start: start, goal: goal, size: size}
+	if c, ok := pf.paths[key]; ok {
+		return append([]image.Point(nil), c.path...), c.path != nil
+	}
+
+	path := pf.astar(start, goal, size)
+
+	if len(pf.paths) >= maxCachedPaths {
+		pf.paths = make(map[pathKey]cachedPath)
+	}
+
+	pf.paths[key] = cachedPath{path: path}
+
+	return append([]image.Point(nil), path...), path != nil
+}
+
+func (pf *Pathfinder) astar(start, goal image.Point, size int) []image.Point {
+
+	if !pf.tmap.IsPassable(start, size) || !pf.tmap.IsPassable(goal, size) {
+		return nil
+	}
+
+	open := &pathQueue{}
+	heap.Push(open, &pathNode{p: start, f: pf.heuristic(start, goal)})
+
+	cost := map[image.Point]float64{start: 0}
+	from := make(map[image.Point]image.Point)
+	closed := make(map[image.Point]bool)
+
+	for open.Len() > 0 {
+		n := heap.Pop(open).(*pathNode)
+
+		if n.p == goal {
+			path := []image.Point{goal}
+			for p := goal; p != start; {
+				p = from[p]
+				path = append(path, p)
+			}
+
+			// reverse
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+
+			return path
+		}
+
+		if closed[n.p] {
+			continue
+		}
+		closed[n.p] = true
+
+		pf.neighbors(n.p, size, func(np image.Point, step float64) {
+			c := cost[n.p] + step
+			if prev, ok := cost[np]; ok && prev <= c {
+				return
+			}
+
+			cost[np] = c
+			from[np] = n.p
+			heap.Push(open, &pathNode{p: np, f: c + pf.heuristic(np, goal)})
+		})
+	}
+
+	return nil
+}
+
+// neighbors calls fn for every traversable neighbor of p,
+// along with the cost of moving to it.
+func (pf *Pathfinder) neighbors(p image.Point, size int, fn func(image.Point, float64)) {
+
+	for i := 0; i < int(pf.connectivity) && i < len(pdirs); i++ {
+		d := pdirs[i]
+		np := p.Add(d)
+
+		if !pf.tmap.IsPassable(np, size) {
+			continue
+		}
+
+		dist := 1.0
+		if d.X != 0 && d.Y != 0 {
+			// prevent cutting corners
+			if !pf.tmap.IsPassable(image.Pt(np.X, p.Y), size) ||
+				!pf.tmap.IsPassable(image.Pt(p.X, np.Y), size) {
+				continue
+			}
+			dist = math.Sqrt2
+		}
+
+		fn(np, dist*pf.cost(np, size))
+	}
+}
+
+// cost returns the highest movement cost within an area.
+func (pf *Pathfinder) cost(p image.Point, size int) float64 {
+	cost := 1.0
+
+	for x := p.X; x < p.X+size; x++ {
+		for y := p.Y; y < p.Y+size; y++ {
+			cost = math.Max(cost, pf.tmap.MoveCost(x, y))
+		}
+	}
+
+	return cost
+}
+
+func (pf *Pathfinder) heuristic(a, b image.Point) float64 {
+	dx := math.Abs(float64(a.X - b.X))
+	dy := math.Abs(float64(a.Y - b.Y))
+
+	if pf.connectivity == Connect4 {
+		return dx + dy
+	}
+
+	// octile distance
+	return dx + dy + (math.Sqrt2-2)*math.Min(dx, dy)
+}
+
+// NextWaypoint returns the isometric world position an agent
+// at a world position should move towards in order to reach a target.
+// The result can be used as an excite input of a ContextMap.
+// False is returned if no path exists.
+func (pf *Pathfinder) NextWaypoint(from, to Vec2, size int) (Vec2, bool) {
+
+	path, ok := pf.FindPath(
+		pf.tmap.WorldToTile(from),
+		pf.tmap.WorldToTile(to),
+		size,
+	)
+	if !ok {
+		return from, false
+	}
+
+	path = pf.Smooth(path, size)
+	if len(path) < 2 {
+		return to, true
+	}
+
+	return pf.tmap.TileToWorld(path[1]), true
+}
+
+// Smooth removes redundant waypoints from a path, keeping
+// only the points required to walk between them in straight lines.
+func (pf *Pathfinder) Smooth(path []image.Point, size int) []image.Point {
+
+	if len(path) < 3 {
+		return append([]image.Point(nil), path...)
+	}
+
+	smoothed := []image.Point{path[0]}
+
+	anchor := 0
+	for anchor < len(path)-1 {
+		next := anchor + 1
+		for i := len(path) - 1; i > next; i-- {
+			if pf.walkable(path[anchor], path[i], size) {
+				next = i
+				break
+			}
+		}
+
+		smoothed = append(smoothed, path[next])
+		anchor = next
+	}
+
+	return smoothed
+}
+
+// walkable indicates whether a straight line between
+// two tiles only crosses traversable tiles.
+func (pf *Pathfinder) walkable(a, b image.Point, size int) bool {
+
+	dx, dy := b.X-a.X, b.Y-a.Y
+	nx, ny := abs(dx), abs(dy)
+	sx, sy := sign(dx), sign(dy)
+
+	p := a
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		// supercover traversal, visiting both tiles at corners
+		switch cmp := (1+2*ix)*ny - (1+2*iy)*nx; {
+		case cmp == 0:
+			if !pf.tmap.IsPassable(image.Pt(p.X+sx, p.Y), size) ||
+				!pf.tmap.IsPassable(image.Pt(p.X, p.Y+sy), size) {
+				return false
+			}
+			p.X += sx
+			p.Y += sy
+			ix++
+			iy++
+		case cmp < 0:
+			p.X += sx
+			ix++
+		default:
+			p.Y += sy
+			iy++
+		}
+
+		if !pf.tmap.IsPassable(p, size) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FlowField returns a field directing agents of a given size towards goal
+// from every reachable tile within radius tiles of the goal.
+// A radius less than 1 covers the entire tilemap.
+func (pf *Pathfinder) FlowField(goal image.Point, size, radius int) *FlowField {
+
+	if size < 1 {
+		panic("invalid size")
+	}
+
+	pf.sync()
+
+	key := fieldKey{goal: goal, size: size, radius: radius}
+	if ff, ok := pf.fields[key]; ok {
+		pf.removeField(key)
+		pf.addField(key, ff)
+
+		return ff
+	}
+
+	bounds := pf.tmap.Bounds()
+	if radius > 0 {
+		bounds = image.Rect(
+			goal.X-radius, goal.Y-radius,
+			goal.X+radius+1, goal.Y+radius+1,
+		).Intersect(bounds)
+	}
+
+	ff := &FlowField{
+		goal:   goal,
+		bounds: bounds,
+		dist:   make([]float64, bounds.Dx()*bounds.Dy()),
+		pf:     pf,
+		size:   size,
+	}
+
+	for i := range ff.dist {
+		ff.dist[i] = math.Inf(1)
+	}
+
+	if goal.In(bounds) && pf.tmap.IsPassable(goal, size) {
+		ff.dist[ff.index(goal)] = 0
+
+		// dijkstra outwards from the goal
+		open := &pathQueue{}
+		heap.Push(open, &pathNode{p: goal})
+
+		for open.Len() > 0 {
+			n := heap.Pop(open).(*pathNode)
+			if n.f > ff.dist[ff.index(n.p)] {
+				continue
+			}
+
+			pf.neighbors(n.p, size, func(np image.Point, step float64) {
+				if !np.In(bounds) {
+					return
+				}
+
+				d := n.f + step
+				if d < ff.dist[ff.index(np)] {
+					ff.dist[ff.index(np)] = d
+					heap.Push(open, &pathNode{p: np, f: d})
+				}
+			})
+		}
+	}
+
+	pf.addField(key, ff)
+
+	return ff
+}
+
+// addField caches a flow field as the most recently used,
+// discarding the least recently used if the cache is full.
+func (pf *Pathfinder) addField(key fieldKey, ff *FlowField) {
+	if len(pf.fieldOrder) >= maxCachedFields {
+		pf.removeField(pf.fieldOrder[0])
+	}
+
+	pf.fields[key] = ff
+	pf.fieldOrder = append(pf.fieldOrder, key)
+}
+
+func (pf *Pathfinder) removeField(key fieldKey) {
+	delete(pf.fields, key)
+
+	for i, k := range pf.fieldOrder {
+		if k == key {
+			pf.fieldOrder = append(pf.fieldOrder[:i], pf.fieldOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// FlowField contains the distance to a goal from
+// every tile within a region of a Tilemap.
+type FlowField struct {
+	goal   image.Point
+	bounds image.Rectangle
+	dist   []float64
+
+	pf   *Pathfinder
+	size int
+}
+
+func (ff *FlowField) index(p image.Point) int {
+	return (p.Y-ff.bounds.Min.Y)*ff.bounds.Dx() + (p.X - ff.bounds.Min.X)
+}
+
+// Goal returns the goal of the FlowField.
+func (ff *FlowField) Goal() image.Point {
+	return ff.goal
+}
+
+// Distance returns the path cost from a tile to the goal.
+// False is returned if the goal cannot be reached.
+func (ff *FlowField) Distance(p image.Point) (float64, bool) {
+	if !p.In(ff.bounds) {
+		return 0, false
+	}
+
+	d := ff.dist[ff.index(p)]
+	return d, !math.IsInf(d, 1)
+}
+
+// Next returns the neighboring tile to move to from
+// a given tile in order to approach the goal.
+// False is returned if the goal cannot be reached.
+func (ff *FlowField) Next(p image.Point) (image.Point, bool) {
+	best, ok := ff.Distance(p)
+	if !ok {
+		return p, false
+	}
+
+	next := p
+	ff.pf.neighbors(p, ff.size, func(np image.Point, _ float64) {
+		if d, ok := ff.Distance(np); ok && d < best {
+			best, next = d, np
+		}
+	})
+
+	return next, true
+}
+
+type pathNode struct {
+	p image.Point
+	f float64
+}
+
+// pathQueue is a min-heap of pathNodes.
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+
+func (q pathQueue) Less(i, j int) bool { return q[i].f < q[j].f }
+
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(*pathNode)) }
+
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+
+	return n
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// 0 is open, 1 is a wall
+var testPathData = [][]int{
+	{0, 0, 0, 0, 0, 0},
+	{0, 1, 1, 1, 1, 0},
+	{0, 0, 0, 0, 1, 0},
+	{1, 1, 1, 0, 1, 0},
+	{0, 0, 0, 0, 1, 0},
+	{0, 1, 1, 1, 1, 0},
+}
+
+func newTestPathTilemap() *Tilemap {
+	var data [2][][]int
+	for _, row := range testPathData {
+		data[0] = append(data[0], make([]int, len(row)))
+		data[1] = append(data[1], append([]int(nil), row...))
+	}
+
+	return NewTilemap(128, data, nil, nil)
+}
+
+func TestFindPath(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+
+	// 4 neighbor connectivity
+	pf := NewPathfinder(tmap, Connect4)
+	path, ok := pf.FindPath(image.Pt(0, 2), image.Pt(0, 4), 1)
+	if !ok {
+		t.Fatal("Expected path from 0 2 to 0 4. Found none.")
+	}
+
+	expected := []image.Point{
+		image.Pt(0, 2),
+		image.Pt(1, 2),
+		image.Pt(2, 2),
+		image.Pt(3, 2),
+		image.Pt(3, 3),
+		image.Pt(3, 4),
+		image.Pt(2, 4),
+		image.Pt(1, 4),
+		image.Pt(0, 4),
+	}
+	if !reflect.DeepEqual(expected, path) {
+		t.Fatalf("Expected path %v got %v", expected, path)
+	}
+
+	// solid goal
+	if _, ok := pf.FindPath(image.Pt(0, 2), image.Pt(1, 1), 1); ok {
+		t.Fatal("Expected no path from 0 2 to 1 1. Found one.")
+	}
+
+	// size
+	if _, ok := pf.FindPath(image.Pt(0, 0), image.Pt(5, 5), 2); ok {
+		t.Fatal("Expected no path for size 2. Found one.")
+	}
+
+	// invalidated by edits
+	tmap.SetTile(3, 3, 1, 1)
+	if _, ok := pf.FindPath(image.Pt(0, 2), image.Pt(0, 4), 1); ok {
+		t.Fatal("Expected no path after closing 3 3. Found one.")
+	}
+
+	tmap.SetTile(3, 3, 1, 0)
+	if _, ok := pf.FindPath(image.Pt(0, 2), image.Pt(0, 4), 1); !ok {
+		t.Fatal("Expected path after opening 3 3. Found none.")
+	}
+
+	// edits off the cached path may open a shorter one
+	tmap.SetTile(0, 3, 1, 0)
+	path, _ = pf.FindPath(image.Pt(0, 2), image.Pt(0, 4), 1)
+	expected = []image.Point{image.Pt(0, 2), image.Pt(0, 3), image.Pt(0, 4)}
+	if !reflect.DeepEqual(expected, path) {
+		t.Fatalf("Expected path %v got %v", expected, path)
+	}
+	tmap.SetTile(0, 3, 1, 1)
+
+	// 8 neighbor connectivity does not cut corners
+	pf = NewPathfinder(tmap, Connect8)
+	path, ok = pf.FindPath(image.Pt(0, 0), image.Pt(5, 5), 1)
+	if !ok {
+		t.Fatal("Expected path from 0 0 to 5 5. Found none.")
+	}
+	if len(path) != 11 {
+		t.Fatalf("Expected path of length 11. Got %v", path)
+	}
+}
+
+func TestSmooth(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+	pf := NewPathfinder(tmap, Connect4)
+
+	path, _ := pf.FindPath(image.Pt(0, 2), image.Pt(0, 4), 1)
+
+	expected := []image.Point{
+		image.Pt(0, 2),
+		image.Pt(3, 2),
+		image.Pt(3, 4),
+		image.Pt(0, 4),
+	}
+	actual := pf.Smooth(path, 1)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected smoothed path %v got %v", expected, actual)
+	}
+}
+
+func TestFlowField(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+	pf := NewPathfinder(tmap, Connect4)
+
+	ff := pf.FlowField(image.Pt(0, 4), 1, 0)
+
+	// following the field reaches the goal
+	p := image.Pt(0, 2)
+	for i := 0; i < 8; i++ {
+		p, _ = ff.Next(p)
+	}
+	if p != image.Pt(0, 4) {
+		t.Fatalf("Expected to reach 0 4. Got %v", p)
+	}
+
+	if d, ok := ff.Distance(image.Pt(0, 2)); !ok || d != 8 {
+		t.Fatalf("Expected distance 8 from 0 2. Got %f", d)
+	}
+
+	if _, ok := ff.Distance(image.Pt(1, 1)); ok {
+		t.Fatal("Expected 1 1 to be unreachable.")
+	}
+
+	// cached until edited
+	if pf.FlowField(image.Pt(0, 4), 1, 0) != ff {
+		t.Fatal("Expected cached flow field.")
+	}
+
+	tmap.SetTile(0, 5, 1, 1)
+	if pf.FlowField(image.Pt(0, 4), 1, 0) == ff {
+		t.Fatal("Expected flow field to be invalidated.")
+	}
+
+	// a moving goal only retains the most recent fields
+	for i := 0; i < maxCachedFields*2; i++ {
+		pf.FlowField(image.Pt(0, 4), 1, i+1)
+	}
+	if len(pf.fields) != maxCachedFields || len(pf.fieldOrder) != maxCachedFields {
+		t.Fatalf("Expected %v cached fields got %v", maxCachedFields, len(pf.fields))
+	}
+	if _, ok := pf.fields[fieldKey{goal: image.Pt(0, 4), size: 1, radius: maxCachedFields * 2}]; !ok {
+		t.Fatal("Expected most recent flow field to be cached.")
+	}
+}
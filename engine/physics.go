@@ -0,0 +1,466 @@
+package engine
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// maxPhysicsSubsteps limits the number of steps a single
+	// move is divided into. Very fast bodies may still tunnel.
+	maxPhysicsSubsteps = 1024
+
+	// maxPhysicsIterations limits the number of contacts
+	// resolved for each step of a move.
+	maxPhysicsIterations = 4
+)
+
+// BodyType determines how a Body responds to collisions.
+type BodyType int
+
+const (
+	// BodyKinematic bodies are moved by Move, and are
+	// blocked by tiles, static bodies and other kinematic bodies.
+	BodyKinematic BodyType = iota
+
+	// BodyStatic bodies block other bodies, but are never
+	// blocked themselves.
+	BodyStatic
+
+	// BodyTrigger bodies never block or get blocked,
+	// but report overlaps through OnTrigger and OnTriggerEnd.
+	BodyTrigger
+)
+
+// Body is a collision body in a PhysicsWorld.
+type Body struct {
+	// Shape is the collision shape, relative
+	// to the position of the body.
+	Shape Shape
+	// Type is the collision response of the body.
+	Type BodyType
+	// Layer is the set of layers the body is on.
+	Layer uint32
+	// Mask is the set of layers the body collides with.
+	Mask uint32
+
+	// OnCollide is called when a move is blocked.
+	// The other body is nil when blocked by a tile.
+	OnCollide func(other *Body, normal Vec2)
+	// OnTrigger is called when a body begins overlapping
+	// a trigger, for both bodies involved.
+	OnTrigger func(other *Body)
+	// OnTriggerEnd is called when a body stops overlapping a
+	// trigger, or either is removed, for both bodies involved.
+	OnTriggerEnd func(other *Body)
+
+	// Data is arbitrary user data, such as the owning Entity.
+	Data interface{}
+
+	pos   Vec2
+	world *PhysicsWorld
+	cells [][2]int
+	query uint64
+
+	// triggers are the bodies overlapping the body,
+	// where at least one of each pair is a trigger
+	triggers map[*Body]struct{}
+}
+
+// NewBody returns a Body on the first layer,
+// colliding with every layer.
+func NewBody(shape Shape, bodyType BodyType) *Body {
+	return &Body{
+		Shape: shape,
+		Type:  bodyType,
+		Layer: 1,
+		Mask:  math.MaxUint32,
+	}
+}
+
+// Position returns the position of the Body.
+func (b *Body) Position() Vec2 {
+	return b.pos
+}
+
+// Bounds returns the world space bounding box of the Body.
+func (b *Body) Bounds() AABB {
+	return b.Shape.Bounds().Translate(b.pos)
+}
+
+// World returns the PhysicsWorld the Body was added to, if any.
+func (b *Body) World() *PhysicsWorld {
+	return b.world
+}
+
+// Physical is an Entity with a collision Body.
+// Bodies of Physical entities added to a Context
+// are added to the Context's PhysicsWorld.
+type Physical interface {
+	Body() *Body
+}
+
+// PhysicsWorld moves Bodies, resolving collisions against
+// each other and the solid tiles of a Tilemap.
+type PhysicsWorld struct {
+	// Tilemap is optionally used for tile collisions.
+	Tilemap *Tilemap
+	// TileLayer is the layer solid tiles are on.
+	TileLayer uint32
+
+	cellSize float64
+	cells    map[[2]int][]*Body
+	query    uint64
+}
+
+// NewPhysicsWorld returns an instantiated *PhysicsWorld.
+// Bodies are indexed in a spatial hash of the given cell size.
+func NewPhysicsWorld(tilemap *Tilemap, cellSize float64) *PhysicsWorld {
+	return &PhysicsWorld{
+		Tilemap:   tilemap,
+		TileLayer: 1,
+		cellSize:  cellSize,
+		cells:     make(map[[2]int][]*Body),
+	}
+}
+
+// Add adds a Body to the world at a given position.
+// A Body can only be in one world at a time.
+func (w *PhysicsWorld) Add(b *Body, pos Vec2) {
+	if b.world != nil {
+		b.world.Remove(b)
+	}
+
+	b.world = w
+	b.pos = pos
+	w.insert(b)
+	w.trigger(b)
+}
+
+// Remove removes a Body from the world,
+// ending any trigger overlaps it is part of.
+func (w *PhysicsWorld) Remove(b *Body) {
+	if b.world != w {
+		return
+	}
+
+	w.unlink(b)
+	b.world = nil
+
+	for o := range b.triggers {
+		endTrigger(b, o)
+	}
+}
+
+// SetPosition moves a Body without resolving collisions.
+func (w *PhysicsWorld) SetPosition(b *Body, pos Vec2) {
+	if b.world != w {
+		return
+	}
+
+	w.unlink(b)
+	b.pos = pos
+	w.insert(b)
+	w.trigger(b)
+}
+
+// QueryRect returns all bodies whose bounds overlap a
+// region, and whose layer is included in a mask.
+func (w *PhysicsWorld) QueryRect(r AABB, mask uint32) []*Body {
+	var bodies []*Body
+
+	w.query++
+	min, max := w.cellKey(r.Min), w.cellKey(r.Max)
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			for _, b := range w.cells[[2]int{x, y}] {
+				if b.query == w.query || b.Layer&mask == 0 {
+					continue
+				}
+				b.query = w.query
+
+				if b.Bounds().Overlaps(r) {
+					bodies = append(bodies, b)
+				}
+			}
+		}
+	}
+
+	return bodies
+}
+
+// Move moves a Body by delta and returns its new position.
+// Kinematic bodies are swept along the movement, sliding
+// along any tiles or bodies blocking them. Static bodies
+// and triggers are moved without resolving collisions.
+func (w *PhysicsWorld) Move(b *Body, delta Vec2) Vec2 {
+	if b.world != w {
+		return b.pos.Add(delta)
+	}
+
+	if b.Type != BodyKinematic {
+		w.unlink(b)
+		b.pos = b.pos.Add(delta)
+		w.insert(b)
+		w.trigger(b)
+
+		return b.pos
+	}
+
+	steps := int(math.Min(
+		math.Ceil(delta.Length()/w.stepSize(b, delta)),
+		maxPhysicsSubsteps,
+	))
+
+	w.unlink(b)
+
+	contacts := make(map[*Body]Vec2)
+
+	remaining := delta
+	for i := 0; i < steps; i++ {
+		step := remaining.Scale(1 / float64(steps-i))
+		b.pos = b.pos.Add(step)
+		remaining = remaining.Sub(step)
+
+		for j := 0; j < maxPhysicsIterations; j++ {
+			mtv, other, ok := w.deepest(b)
+			if !ok {
+				break
+			}
+
+			b.pos = b.pos.Add(mtv)
+
+			// slide along the contact
+			normal := mtv.Normalize()
+			if d := remaining.Dot(normal); d < 0 {
+				remaining = remaining.Sub(normal.Scale(d))
+			}
+
+			if _, ok := contacts[other]; !ok {
+				contacts[other] = normal
+			}
+		}
+	}
+
+	w.insert(b)
+
+	if b.OnCollide != nil {
+		for other, normal := range contacts {
+			b.OnCollide(other, normal)
+		}
+	}
+
+	w.trigger(b)
+
+	return b.pos
+}
+
+// stepSize returns the max distance a Body may move in a
+// single step without passing through anything in its path,
+// which is half the smallest extent of the Body and of the
+// tiles and bodies it could collide with.
+func (w *PhysicsWorld) stepSize(b *Body, delta Vec2) float64 {
+	extent := func(r AABB) float64 {
+		return math.Min(r.Dx(), r.Dy())
+	}
+
+	size := extent(b.Shape.Bounds())
+
+	bounds, moved := b.Bounds(), b.Bounds().Translate(delta)
+	swept := AABB{
+		Min: Vec2{X: math.Min(bounds.Min.X, moved.Min.X), Y: math.Min(bounds.Min.Y, moved.Min.Y)},
+		Max: Vec2{X: math.Max(bounds.Max.X, moved.Max.X), Y: math.Max(bounds.Max.Y, moved.Max.Y)},
+	}
+
+	for _, o := range w.QueryRect(swept, b.Mask) {
+		if o != b && o.Type != BodyTrigger {
+			size = math.Min(size, extent(o.Shape.Bounds()))
+		}
+	}
+
+	// the height of a tile diamond
+	if w.Tilemap != nil && b.Mask&w.TileLayer != 0 {
+		size = math.Min(size, float64(w.Tilemap.TileWidth)/2)
+	}
+
+	return math.Max(size/2, 1)
+}
+
+// deepest returns the largest translation needed to move
+// a Body out of a blocking tile or body.
+func (w *PhysicsWorld) deepest(b *Body) (Vec2, *Body, bool) {
+	var (
+		mtv   Vec2
+		other *Body
+		found bool
+	)
+
+	consider := func(v Vec2, o *Body) {
+		if !found || v.Dot(v) > mtv.Dot(mtv) {
+			mtv, other, found = v, o, true
+		}
+	}
+
+	bounds := b.Bounds()
+
+	for _, o := range w.QueryRect(bounds, b.Mask) {
+		if o == b || o.Type == BodyTrigger {
+			continue
+		}
+
+		if v, ok := Overlap(b.Shape, b.pos, o.Shape, o.pos); ok {
+			consider(v, o)
+		}
+	}
+
+	if w.Tilemap == nil || b.Mask&w.TileLayer == 0 {
+		return mtv, other, found
+	}
+
+	tw := float64(w.Tilemap.TileWidth)
+	diamond := Polygon{
+		Points: []Vec2{
+			{X: -tw / 2},
+			{Y: -tw / 4},
+			{X: tw / 2},
+			{Y: tw / 4},
+		},
+	}
+
+	// tiles covered by the corners of the bounds
+	var tiles image.Rectangle
+	for _, v := range []Vec2{
+		bounds.Min,
+		{X: bounds.Max.X, Y: bounds.Min.Y},
+		bounds.Max,
+		{X: bounds.Min.X, Y: bounds.Max.Y},
+	} {
+		p := w.Tilemap.WorldToTile(v)
+		tiles = tiles.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+	}
+
+	for x := tiles.Min.X - 1; x <= tiles.Max.X; x++ {
+		for y := tiles.Min.Y - 1; y <= tiles.Max.Y; y++ {
+			if !w.Tilemap.IsSolid(x, y) {
+				continue
+			}
+
+			center := w.Tilemap.TileToWorld(image.Pt(x, y))
+			if v, ok := Overlap(b.Shape, b.pos, diamond, center); ok {
+				consider(v, nil)
+			}
+		}
+	}
+
+	return mtv, other, found
+}
+
+// trigger reports the overlaps between a Body and
+// triggers which began or ended since it last moved.
+func (w *PhysicsWorld) trigger(b *Body) {
+	overlaps := make(map[*Body]struct{})
+
+	for _, o := range w.QueryRect(b.Bounds(), b.Mask) {
+		if o == b {
+			continue
+		}
+
+		// at least one side must be a trigger
+		if b.Type != BodyTrigger && o.Type != BodyTrigger {
+			continue
+		}
+
+		if _, ok := Overlap(b.Shape, b.pos, o.Shape, o.pos); !ok {
+			continue
+		}
+
+		overlaps[o] = struct{}{}
+
+		if _, ok := b.triggers[o]; !ok {
+			beginTrigger(b, o)
+		}
+	}
+
+	for o := range b.triggers {
+		if _, ok := overlaps[o]; !ok {
+			endTrigger(b, o)
+		}
+	}
+}
+
+func beginTrigger(b, o *Body) {
+	for _, pair := range [2][2]*Body{{b, o}, {o, b}} {
+		if pair[0].triggers == nil {
+			pair[0].triggers = make(map[*Body]struct{})
+		}
+
+		pair[0].triggers[pair[1]] = struct{}{}
+	}
+
+	if b.OnTrigger != nil {
+		b.OnTrigger(o)
+	}
+
+	if o.OnTrigger != nil {
+		o.OnTrigger(b)
+	}
+}
+
+func endTrigger(b, o *Body) {
+	delete(b.triggers, o)
+	delete(o.triggers, b)
+
+	if b.OnTriggerEnd != nil {
+		b.OnTriggerEnd(o)
+	}
+
+	if o.OnTriggerEnd != nil {
+		o.OnTriggerEnd(b)
+	}
+}
+
+func (w *PhysicsWorld) insert(b *Body) {
+	bounds := b.Bounds()
+	min, max := w.cellKey(bounds.Min), w.cellKey(bounds.Max)
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			key := [2]int{x, y}
+			w.cells[key] = append(w.cells[key], b)
+			b.cells = append(b.cells, key)
+		}
+	}
+}
+
+func (w *PhysicsWorld) unlink(b *Body) {
+	for _, key := range b.cells {
+		cell := w.cells[key]
+
+		for i, o := range cell {
+			if o != b {
+				continue
+			}
+
+			cell[i] = cell[len(cell)-1]
+			cell[len(cell)-1] = nil
+			cell = cell[:len(cell)-1]
+			break
+		}
+
+		if len(cell) == 0 {
+			delete(w.cells, key)
+		} else {
+			w.cells[key] = cell
+		}
+	}
+
+	b.cells = b.cells[:0]
+}
+
+func (w *PhysicsWorld) cellKey(v Vec2) [2]int {
+	return [2]int{
+		int(math.Floor(v.X / w.cellSize)),
+		int(math.Floor(v.Y / w.cellSize)),
+	}
+}
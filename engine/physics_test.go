@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestOverlap(t *testing.T) {
+
+	box := AABB{Min: Vec2{X: -1, Y: -1}, Max: Vec2{X: 1, Y: 1}}
+	circle := Circle{Radius: 1}
+	triangle := Polygon{Points: []Vec2{{X: -1, Y: 1}, {Y: -1}, {X: 1, Y: 1}}}
+
+	cases := []struct {
+		a, b     Shape
+		pa, pb   Vec2
+		expected Vec2
+		ok       bool
+	}{
+		{box, box, Vec2{}, Vec2{X: 1.5}, Vec2{X: -0.5}, true},
+		{box, box, Vec2{}, Vec2{X: 2}, Vec2{}, false},
+		{box, box, Vec2{}, Vec2{Y: -1.5}, Vec2{Y: 0.5}, true},
+		{circle, circle, Vec2{}, Vec2{X: 1.5}, Vec2{X: -0.5}, true},
+		{circle, box, Vec2{X: 1.5}, Vec2{}, Vec2{X: 0.5}, true},
+		{circle, box, Vec2{X: 1.9, Y: 1.9}, Vec2{}, Vec2{}, false},
+		{triangle, box, Vec2{}, Vec2{X: 3}, Vec2{}, false},
+		{box, triangle, Vec2{Y: 1.5}, Vec2{}, Vec2{Y: 0.5}, true},
+	}
+
+	for i, c := range cases {
+		mtv, ok := Overlap(c.a, c.pa, c.b, c.pb)
+		if ok != c.ok {
+			t.Fatalf("Expected %v got %v for case %d", c.ok, ok, i)
+		}
+
+		if math.Abs(mtv.X-c.expected.X) > 1e-9 || math.Abs(mtv.Y-c.expected.Y) > 1e-9 {
+			t.Fatalf("Expected %v got %v for case %d", c.expected, mtv, i)
+		}
+	}
+}
+
+func TestPhysicsWorldBodies(t *testing.T) {
+
+	w := NewPhysicsWorld(nil, 32)
+
+	wall := NewBody(AABB{Min: Vec2{X: -5, Y: -50}, Max: Vec2{X: 5, Y: 50}}, BodyStatic)
+	w.Add(wall, Vec2{X: 50})
+
+	var hits int
+	mover := NewBody(Circle{Radius: 5}, BodyKinematic)
+	mover.OnCollide = func(other *Body, normal Vec2) {
+		if other != wall {
+			t.Fatalf("Expected collision with %p got %p", wall, other)
+		}
+		hits++
+	}
+	w.Add(mover, Vec2{})
+
+	// swept movement does not tunnel, and slides along the wall
+	pos := w.Move(mover, Vec2{X: 100, Y: 20})
+	if math.Abs(pos.X-40) > 1e-6 || math.Abs(pos.Y-20) > 1e-6 {
+		t.Fatalf("Expected %v got %v", Vec2{X: 40, Y: 20}, pos)
+	}
+	if hits != 1 {
+		t.Fatalf("Expected 1 collision got %d", hits)
+	}
+
+	// masks
+	mover.Mask = 2
+	pos = w.Move(mover, Vec2{X: 20})
+	if pos.X != 60 {
+		t.Fatalf("Expected 60 got %f", pos.X)
+	}
+
+	// triggers
+	var triggered bool
+	zone := NewBody(AABB{Min: Vec2{X: -10, Y: -10}, Max: Vec2{X: 10, Y: 10}}, BodyTrigger)
+	zone.Layer = 2
+	zone.OnTrigger = func(other *Body) {
+		triggered = other == mover
+	}
+	w.Add(zone, Vec2{X: 100, Y: 20})
+
+	var begins, ends int
+	zone.OnTriggerEnd = func(other *Body) {
+		ends++
+	}
+	mover.OnTrigger = func(other *Body) {
+		begins++
+	}
+
+	pos = w.Move(mover, Vec2{X: 40})
+	if pos.X != 100 || !triggered {
+		t.Fatalf("Expected trigger at 100 got %f %v", pos.X, triggered)
+	}
+
+	// triggers fire on begin and end, not on every move
+	w.Move(mover, Vec2{X: 2})
+	if begins != 1 || ends != 0 {
+		t.Fatalf("Expected %v begin and %v ends got %v %v", 1, 0, begins, ends)
+	}
+
+	w.Move(mover, Vec2{X: 40})
+	if begins != 1 || ends != 1 {
+		t.Fatalf("Expected %v begin and %v end got %v %v", 1, 1, begins, ends)
+	}
+}
+
+func TestPhysicsWorldThinShapes(t *testing.T) {
+
+	w := NewPhysicsWorld(nil, 32)
+
+	wall := NewBody(AABB{Min: Vec2{X: -1, Y: -50}, Max: Vec2{X: 1, Y: 50}}, BodyStatic)
+	w.Add(wall, Vec2{X: 500})
+
+	mover := NewBody(Circle{Radius: 5}, BodyKinematic)
+	w.Add(mover, Vec2{})
+
+	// steps are limited by the extent of the wall, not the mover
+	pos := w.Move(mover, Vec2{X: 1000})
+	if math.Abs(pos.X-494) > 1e-6 {
+		t.Fatalf("Expected %v got %v", 494, pos.X)
+	}
+}
+
+func TestPhysicsWorldTiles(t *testing.T) {
+
+	tmap := newTestPathTilemap()
+	w := NewPhysicsWorld(tmap, 64)
+
+	body := NewBody(Circle{Radius: 8}, BodyKinematic)
+	w.Add(body, tmap.TileToWorld(image.Pt(0, 0)))
+
+	// move straight into the wall at 1 1
+	target := tmap.TileToWorld(image.Pt(1, 1))
+	pos := w.Move(body, target.Sub(body.Position()))
+
+	if p := tmap.WorldToTile(pos); tmap.IsSolid(p.X, p.Y) {
+		t.Fatalf("Expected open tile got %v", p)
+	}
+
+	// entity integration
+	e := new(CoreEntity)
+	e.SetBody(body)
+	e.Vec2 = pos
+	e.Tick()
+
+	e.Vec2 = target
+	e.Tick()
+	if p := tmap.WorldToTile(e.Position()); tmap.IsSolid(p.X, p.Y) {
+		t.Fatalf("Expected open tile got %v", p)
+	}
+	if e.Position() != body.Position() {
+		t.Fatalf("Expected %v got %v", body.Position(), e.Position())
+	}
+
+	e.Dispose()
+	if body.World() != nil {
+		t.Fatal("Expected body to be removed from world.")
+	}
+}
@@ -8,6 +8,10 @@ type Renderer interface {
 	// Images are drawn in the order they are added.
 	AddImage(...Image)
 
+	// RemoveImage removes one or more images from the renderer's
+	// draw stack, without disposing them.
+	RemoveImage(...Image)
+
 	SetCamera(*Camera)
 
 	ScreenToWorld(Vec2) Vec2
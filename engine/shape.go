@@ -0,0 +1,249 @@
+package engine
+
+import "math"
+
+// overlapEpsilon is the minimum penetration depth
+// considered an overlap, so that shapes resting
+// against one another are not treated as colliding.
+const overlapEpsilon = 1e-9
+
+// Shape is a collision shape, positioned relative to
+// the Vec2 it is tested at. Shapes are implemented
+// by AABB, Circle and Polygon.
+type Shape interface {
+	// Bounds returns the bounding box of the shape.
+	Bounds() AABB
+
+	// vertices returns the corners of the shape at a position,
+	// or nil for shapes without corners.
+	vertices(pos Vec2) []Vec2
+
+	// axes returns the separating axes of the shape.
+	axes() []Vec2
+
+	// project returns the extent of the shape at
+	// a position along a normalized axis.
+	project(pos, axis Vec2) (float64, float64)
+}
+
+// AABB is an axis aligned bounding box.
+type AABB struct {
+	Min, Max Vec2
+}
+
+// Bounds implements Shape.
+func (a AABB) Bounds() AABB {
+	return a
+}
+
+// Translate returns the box moved by v.
+func (a AABB) Translate(v Vec2) AABB {
+	return AABB{
+		Min: a.Min.Add(v),
+		Max: a.Max.Add(v),
+	}
+}
+
+// Overlaps indicates whether two boxes intersect.
+// Boxes that only share an edge do not overlap.
+func (a AABB) Overlaps(b AABB) bool {
+	return a.Min.X < b.Max.X && b.Min.X < a.Max.X &&
+		a.Min.Y < b.Max.Y && b.Min.Y < a.Max.Y
+}
+
+// Contains indicates whether a point is within the box.
+func (a AABB) Contains(v Vec2) bool {
+	return v.X >= a.Min.X && v.X < a.Max.X &&
+		v.Y >= a.Min.Y && v.Y < a.Max.Y
+}
+
+// Center returns the center of the box.
+func (a AABB) Center() Vec2 {
+	return a.Min.Lerp(a.Max, 0.5)
+}
+
+// Dx returns the width of the box.
+func (a AABB) Dx() float64 {
+	return a.Max.X - a.Min.X
+}
+
+// Dy returns the height of the box.
+func (a AABB) Dy() float64 {
+	return a.Max.Y - a.Min.Y
+}
+
+func (a AABB) vertices(pos Vec2) []Vec2 {
+	return []Vec2{
+		pos.Add(a.Min),
+		pos.Add(Vec2{X: a.Max.X, Y: a.Min.Y}),
+		pos.Add(a.Max),
+		pos.Add(Vec2{X: a.Min.X, Y: a.Max.Y}),
+	}
+}
+
+func (a AABB) axes() []Vec2 {
+	return []Vec2{{X: 1}, {Y: 1}}
+}
+
+func (a AABB) project(pos, axis Vec2) (float64, float64) {
+	return projectVertices(a.vertices(pos), axis)
+}
+
+// Circle is a circle shape.
+type Circle struct {
+	// Offset is the center of the circle.
+	Offset Vec2
+	Radius float64
+}
+
+// Bounds implements Shape.
+func (c Circle) Bounds() AABB {
+	return AABB{
+		Min: Vec2{X: c.Offset.X - c.Radius, Y: c.Offset.Y - c.Radius},
+		Max: Vec2{X: c.Offset.X + c.Radius, Y: c.Offset.Y + c.Radius},
+	}
+}
+
+func (c Circle) vertices(Vec2) []Vec2 {
+	return nil
+}
+
+func (c Circle) axes() []Vec2 {
+	return nil
+}
+
+func (c Circle) project(pos, axis Vec2) (float64, float64) {
+	d := pos.Add(c.Offset).Dot(axis)
+	return d - c.Radius, d + c.Radius
+}
+
+// Polygon is a convex polygon shape.
+// Points may be in either winding order.
+type Polygon struct {
+	Points []Vec2
+}
+
+// Bounds implements Shape.
+func (p Polygon) Bounds() AABB {
+	if len(p.Points) == 0 {
+		return AABB{}
+	}
+
+	b := AABB{Min: p.Points[0], Max: p.Points[0]}
+	for _, v := range p.Points[1:] {
+		b.Min.X = math.Min(b.Min.X, v.X)
+		b.Min.Y = math.Min(b.Min.Y, v.Y)
+		b.Max.X = math.Max(b.Max.X, v.X)
+		b.Max.Y = math.Max(b.Max.Y, v.Y)
+	}
+
+	return b
+}
+
+func (p Polygon) vertices(pos Vec2) []Vec2 {
+	v := make([]Vec2, len(p.Points))
+	for i, point := range p.Points {
+		v[i] = pos.Add(point)
+	}
+
+	return v
+}
+
+func (p Polygon) axes() []Vec2 {
+	axes := make([]Vec2, 0, len(p.Points))
+	for i, a := range p.Points {
+		b := p.Points[(i+1)%len(p.Points)]
+
+		edge := b.Sub(a)
+		if edge.Length() == 0 {
+			continue
+		}
+
+		axes = append(axes, Vec2{X: -edge.Y, Y: edge.X}.Normalize())
+	}
+
+	return axes
+}
+
+func (p Polygon) project(pos, axis Vec2) (float64, float64) {
+	return projectVertices(p.vertices(pos), axis)
+}
+
+func projectVertices(vertices []Vec2, axis Vec2) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range vertices {
+		d := v.Dot(axis)
+		min = math.Min(min, d)
+		max = math.Max(max, d)
+	}
+
+	return min, max
+}
+
+// Overlap tests two shapes at the given positions for intersection
+// using the separating axis theorem. If the shapes intersect, the
+// minimum translation vector that moves a out of b is returned.
+func Overlap(a Shape, pa Vec2, b Shape, pb Vec2) (Vec2, bool) {
+
+	if !a.Bounds().Translate(pa).Overlaps(b.Bounds().Translate(pb)) {
+		return Vec2{}, false
+	}
+
+	axes := append(a.axes(), b.axes()...)
+
+	// circles are separated along the axis to the nearest vertex,
+	// or to the center of the other circle
+	ca, aCircle := a.(Circle)
+	cb, bCircle := b.(Circle)
+
+	switch {
+	case aCircle && bCircle:
+		axes = append(axes, circleAxis(pa.Add(ca.Offset), []Vec2{pb.Add(cb.Offset)}))
+	case aCircle:
+		axes = append(axes, circleAxis(pa.Add(ca.Offset), b.vertices(pb)))
+	case bCircle:
+		axes = append(axes, circleAxis(pb.Add(cb.Offset), a.vertices(pa)))
+	}
+
+	var mtv Vec2
+	depth := math.Inf(1)
+
+	for _, axis := range axes {
+		amin, amax := a.project(pa, axis)
+		bmin, bmax := b.project(pb, axis)
+
+		// push a towards whichever side is closer
+		low, high := amax-bmin, bmax-amin
+		if low <= overlapEpsilon || high <= overlapEpsilon {
+			return Vec2{}, false
+		}
+
+		if low < depth {
+			depth = low
+			mtv = axis.Scale(-low)
+		}
+		if high < depth {
+			depth = high
+			mtv = axis.Scale(high)
+		}
+	}
+
+	return mtv, true
+}
+
+// circleAxis returns the normalized axis from
+// a center to the nearest of a set of points.
+func circleAxis(center Vec2, points []Vec2) Vec2 {
+	axis := Vec2{X: 1}
+	min := math.Inf(1)
+
+	for _, p := range points {
+		d := p.Sub(center)
+		if l := d.Length(); l > 0 && l < min {
+			min = l
+			axis = d.Scale(1 / l)
+		}
+	}
+
+	return axis
+}
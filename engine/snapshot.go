@@ -0,0 +1,402 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// SnapshotSignature is the signature prepended to all Ardent snapshot files.
+const SnapshotSignature = "ArdentSave"
+
+// SnapshotFormat is the current snapshot file format version.
+// It is independent of the game defined Snapshot.Version.
+const SnapshotFormat = 1
+
+var (
+	// ErrInvalidSnapshot occurs when data is not a valid snapshot file.
+	ErrInvalidSnapshot = errors.New("invalid snapshot file")
+
+	// ErrSnapshotTilemap occurs when a snapshot with a
+	// Tilemap is restored to a Context without one.
+	ErrSnapshotTilemap = errors.New("snapshot tilemap without a context tilemap")
+)
+
+// InvalidSnapshotVersion occurs when a snapshot is newer
+// than the version of its SnapshotRegistry.
+type InvalidSnapshotVersion int
+
+// Error implements error.
+func (i InvalidSnapshotVersion) Error() string {
+	return fmt.Sprintf("invalid snapshot version: %d", int(i))
+}
+
+// UnknownSnapshotClass occurs when a snapshot contains an
+// entity class without a factory in the SnapshotRegistry.
+type UnknownSnapshotClass string
+
+// Error implements error.
+func (u UnknownSnapshotClass) Error() string {
+	return fmt.Sprintf("unknown snapshot class: %s", string(u))
+}
+
+// Snapshot is the saved state of a Context.
+type Snapshot struct {
+	// Version is the game defined version of the snapshot.
+	Version int
+	// Ticks is the tick count of the Context.
+	Ticks int
+	// Tilemap is the binary encoded Tilemap of the Context.
+	// It is empty for chunked tilemaps, which are
+	// persisted through their ChunkProvider.
+	Tilemap []byte
+	// Entities are the saved entities, in update order.
+	Entities []EntitySnapshot
+}
+
+// EntitySnapshot is the saved state of an Entity.
+type EntitySnapshot struct {
+	Class    string
+	Position Vec2
+	// State is the bitmask of a Stateful entity.
+	State uint64
+	// Animations are the animations among the entity's images.
+	Animations []AnimationSnapshot
+	// Fields are the custom fields of a Persistent entity.
+	Fields map[string]interface{}
+}
+
+// AnimationSnapshot is the saved state of an Animation.
+type AnimationSnapshot struct {
+	// Image is the index of the Animation in the entity's images.
+	Image int
+	State string
+	Tick  int
+}
+
+// Stateful is implemented by entities with a StateMachine,
+// such as those embedding a *StateMachine.
+type Stateful interface {
+	State() uint64
+	SetState(uint64, bool)
+}
+
+// Placeable is implemented by entities that are not Nodes,
+// so that their position can be restored from a snapshot.
+type Placeable interface {
+	SetPosition(Vec2)
+}
+
+// Persistent is implemented by entities with custom fields
+// to save. Field values are gob encoded, so custom types must
+// be registered with gob.Register.
+type Persistent interface {
+	SaveFields() map[string]interface{}
+	LoadFields(map[string]interface{}) error
+}
+
+// EntityFactory creates an Entity to restore from a snapshot.
+// The position of Node and Placeable entities, the state of
+// Stateful entities, animations and custom fields are
+// restored after the Entity is created.
+type EntityFactory func(EntitySnapshot) (Entity, error)
+
+// Migration upgrades a Snapshot from one version to the next.
+type Migration func(*Snapshot) error
+
+// SnapshotRegistry creates snapshots of the current
+// version, and restores entities by class.
+type SnapshotRegistry struct {
+	// Version is the current snapshot version of the game.
+	Version int
+
+	factories  map[string]EntityFactory
+	migrations map[int]Migration
+}
+
+// NewSnapshotRegistry returns an instantiated
+// *SnapshotRegistry with the given current version.
+func NewSnapshotRegistry(version int) *SnapshotRegistry {
+	return &SnapshotRegistry{
+		Version:    version,
+		factories:  make(map[string]EntityFactory),
+		migrations: make(map[int]Migration),
+	}
+}
+
+// Register sets the factory used to restore entities of a class.
+func (r *SnapshotRegistry) Register(class string, factory EntityFactory) {
+	r.factories[class] = factory
+}
+
+// AddMigration sets the migration that upgrades snapshots
+// of a version to the next version. Versions without a
+// migration are upgraded unchanged.
+func (r *SnapshotRegistry) AddMigration(from int, m Migration) {
+	r.migrations[from] = m
+}
+
+// Migrate upgrades a Snapshot to the current version.
+func (r *SnapshotRegistry) Migrate(s *Snapshot) error {
+	if s.Version > r.Version {
+		return InvalidSnapshotVersion(s.Version)
+	}
+
+	for s.Version < r.Version {
+		if m, ok := r.migrations[s.Version]; ok {
+			if err := m(s); err != nil {
+				return fmt.Errorf("migrating snapshot version %d: %w", s.Version, err)
+			}
+		}
+
+		s.Version++
+	}
+
+	return nil
+}
+
+// Snapshot saves the state of the Context and its entities.
+// Children are not saved, and should be recreated by
+// the factory of their parent. Entities pending
+// addition are saved after all others.
+func (c *Context) Snapshot(r *SnapshotRegistry) (*Snapshot, error) {
+	s := &Snapshot{
+		Version: r.Version,
+		Ticks:   c.ticks,
+	}
+
+	if c.Tilemap != nil && !c.IsChunked() {
+		data, err := c.Tilemap.MarshalMap()
+		if err != nil {
+			return nil, err
+		}
+
+		s.Tilemap = data
+	}
+
+	entities := toEntities(c.partitionMap.Entries())
+	entities = append(entities, c.entitySwap...)
+
+	for _, e := range entities {
+		if e.IsDisposed() {
+			continue
+		}
+
+		if n, ok := e.(Node); ok && n.node().parent != nil {
+			continue
+		}
+
+		s.Entities = append(s.Entities, snapshotEntity(e))
+	}
+
+	return s, nil
+}
+
+func snapshotEntity(e Entity) EntitySnapshot {
+	es := EntitySnapshot{
+		Class:    e.Class(),
+		Position: e.Position(),
+	}
+
+	if st, ok := e.(Stateful); ok {
+		es.State = st.State()
+	}
+
+	for i, img := range e.Images() {
+		if anim, ok := img.(Animation); ok {
+			es.Animations = append(es.Animations, AnimationSnapshot{
+				Image: i,
+				State: anim.State(),
+				Tick:  anim.TickCount(),
+			})
+		}
+	}
+
+	if p, ok := e.(Persistent); ok {
+		es.Fields = p.SaveFields()
+	}
+
+	return es
+}
+
+// Restore replaces the state of the Context with a Snapshot,
+// migrating it to the current version first. Current entities
+// and pending additions are disposed, and pending removals,
+// hit contacts and published events are discarded. Chunked
+// tilemaps discard their loaded chunks, including unsaved
+// changes, to reload them from their ChunkProvider. Restored
+// entities are added immediately, in the order they were saved.
+func (c *Context) Restore(r *SnapshotRegistry, s *Snapshot) error {
+	if err := r.Migrate(s); err != nil {
+		return err
+	}
+
+	if len(s.Tilemap) > 0 && c.Tilemap == nil {
+		return ErrSnapshotTilemap
+	}
+
+	entities := make([]Entity, len(s.Entities))
+	for i, es := range s.Entities {
+		e, err := restoreEntity(r, es)
+		if err != nil {
+			return err
+		}
+
+		entities[i] = e
+	}
+
+	switch {
+	case len(s.Tilemap) > 0:
+		if err := c.Tilemap.UnmarshalMap(s.Tilemap); err != nil {
+			return err
+		}
+	case c.Tilemap != nil && c.IsChunked():
+		c.Tilemap.ResetChunks()
+	}
+
+	c.disposeAll()
+
+	c.ticks = s.Ticks
+
+	c.AddEntity(entities...)
+	c.addPending()
+
+	return nil
+}
+
+// disposeAll disposes every entity of the Context, including
+// pending additions, notifying each DisposedHook of the
+// entities that were added. It discards pending removals, hit
+// contacts and published events.
+func (c *Context) disposeAll() {
+	c.hits = newHitTracker()
+	c.Events.Clear()
+
+	for i := range c.removeSwap {
+		c.removeSwap[i] = nil
+	}
+	c.removeSwap = c.removeSwap[:0]
+
+	entities := toEntities(c.partitionMap.Entries())
+	for _, e := range entities {
+		c.partitionMap.Remove(e)
+		c.detach(e)
+	}
+
+	for _, e := range entities {
+		if !e.IsDisposed() {
+			e.Dispose()
+		}
+
+		if hook, ok := e.(DisposedHook); ok {
+			hook.OnDisposed(c)
+		}
+	}
+
+	for i, e := range c.entitySwap {
+		if !e.IsDisposed() {
+			e.Dispose()
+		}
+
+		c.entitySwap[i] = nil
+	}
+	c.entitySwap = c.entitySwap[:0]
+}
+
+func restoreEntity(r *SnapshotRegistry, es EntitySnapshot) (Entity, error) {
+	factory, ok := r.factories[es.Class]
+	if !ok {
+		return nil, UnknownSnapshotClass(es.Class)
+	}
+
+	e, err := factory(es)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, ok := e.(Node); ok {
+		ce := n.node()
+		ce.Vec2 = es.Position
+		ce.prevPos = es.Position
+		ce.updateImages()
+	} else if p, ok := e.(Placeable); ok {
+		p.SetPosition(es.Position)
+	}
+
+	if st, ok := e.(Stateful); ok {
+		st.SetState(es.State, true)
+	}
+
+	images := e.Images()
+	for _, as := range es.Animations {
+		if as.Image < 0 || as.Image >= len(images) {
+			continue
+		}
+
+		if anim, ok := images[as.Image].(Animation); ok {
+			anim.SetState(as.State)
+			anim.SetTickCount(as.Tick)
+		}
+	}
+
+	if p, ok := e.(Persistent); ok && es.Fields != nil {
+		if err := p.LoadFields(es.Fields); err != nil {
+			return nil, fmt.Errorf("loading %s fields: %w", es.Class, err)
+		}
+	}
+
+	return e, nil
+}
+
+// snapshotData has the fields of Snapshot without its
+// methods, so gob does not use its BinaryMarshaler.
+type snapshotData Snapshot
+
+// snapshotFile is the serialized form of a Snapshot.
+type snapshotFile struct {
+	Format   int
+	Snapshot snapshotData
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// The format is the snapshot signature, a null byte, then gob-encoded data.
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(SnapshotSignature)
+	buf.WriteByte(0)
+
+	f := snapshotFile{
+		Format:   SnapshotFormat,
+		Snapshot: snapshotData(*s),
+	}
+
+	if err := gob.NewEncoder(buf).Encode(f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+
+	magic, err := buf.ReadString(0)
+	if err != nil || magic[:len(magic)-1] != SnapshotSignature {
+		return ErrInvalidSnapshot
+	}
+
+	var f snapshotFile
+	if err := gob.NewDecoder(buf).Decode(&f); err != nil {
+		return err
+	}
+
+	if f.Format < 1 || f.Format > SnapshotFormat {
+		return ErrInvalidSnapshot
+	}
+
+	*s = Snapshot(f.Snapshot)
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package engine
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+)
+
+type testAnimation struct {
+	testImage
+	*AnimationPlayer
+}
+
+func newTestAnimation() *testAnimation {
+	return &testAnimation{AnimationPlayer: NewAnimationPlayer()}
+}
+
+func (a *testAnimation) SetState(state string) {
+	if a.State() != state {
+		a.SetSequence(state, a.sequence(state))
+	}
+}
+
+func (a *testAnimation) Crossfade(state string, ticks int) {
+	if a.State() != state {
+		a.CrossfadeSequence(state, a.sequence(state), ticks)
+	}
+}
+
+// sequence returns a sequence of four frames, each
+// lasting two ticks, which loops unless it is an attack.
+func (a *testAnimation) sequence(state string) AnimationSequence {
+	return AnimationSequence{Frames: 4, Fps: 30, Loop: state != "attack"}
+}
+
+type testSaveEntity struct {
+	CoreEntity
+	*StateMachine
+	hp int
+}
+
+func newTestSaveEntity() *testSaveEntity {
+	e := &testSaveEntity{StateMachine: NewStateMachine()}
+	e.AddImage(newTestAnimation())
+	return e
+}
+
+func (e *testSaveEntity) Class() string {
+	return "save"
+}
+
+func (e *testSaveEntity) SaveFields() map[string]interface{} {
+	return map[string]interface{}{"hp": e.hp}
+}
+
+func (e *testSaveEntity) LoadFields(fields map[string]interface{}) error {
+	e.hp = fields["hp"].(int)
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+
+	r := &testRenderer{viewport: image.Rect(0, 0, 200, 200)}
+	tmap := newTestPathTilemap()
+	ctx := NewContext(r, nil, tmap)
+
+	registry := NewSnapshotRegistry(1)
+	registry.Register("save", func(EntitySnapshot) (Entity, error) {
+		return newTestSaveEntity(), nil
+	})
+
+	var saved []*testSaveEntity
+	for i := 0; i < 3; i++ {
+		e := newTestSaveEntity()
+		e.Vec2 = Vec2{X: float64(i * 300), Y: 10}
+		e.hp = i + 1
+		e.SetState(1<<uint(i), true)
+
+		anim := e.Images()[0].(*testAnimation)
+		anim.SetState("walk")
+		anim.SetTickCount(i * 7)
+
+		saved = append(saved, e)
+		ctx.AddEntity(e)
+	}
+
+	ctx.Tick()
+	ctx.Tick()
+
+	s, err := ctx.Snapshot(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// modify the context after saving
+	tmap.SetTile(0, 0, 1, 1)
+	added, pending := newTestSaveEntity(), newTestSaveEntity()
+	ctx.AddEntity(added)
+	ctx.Tick()
+	ctx.AddEntity(pending)
+
+	var loaded Snapshot
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctx.Restore(registry, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if ctx.ticks != 2 {
+		t.Fatalf("Expected %d got %d", 2, ctx.ticks)
+	}
+
+	for _, e := range append(saved, added, pending) {
+		if !e.IsDisposed() {
+			t.Fatalf("Expected %v to be disposed", e)
+		}
+	}
+
+	if tmap.GetTileValue(0, 0, 1) != 0 {
+		t.Fatal("Expected tilemap to be restored.")
+	}
+
+	if r.images != 3 {
+		t.Fatalf("Expected %d images got %d", 3, r.images)
+	}
+
+	ctx.Tick()
+	restored := toEntities(ctx.partitionMap.Entries())
+
+	if len(restored) != 3 {
+		t.Fatalf("Expected %d entities got %d", 3, len(restored))
+	}
+
+	for i, e := range restored {
+		expected, got := saved[i], e.(*testSaveEntity)
+
+		if expected.Position() != got.Position() {
+			t.Fatalf("Expected %v got %v", expected.Position(), got.Position())
+		}
+
+		if expected.hp != got.hp || expected.State() != got.State() {
+			t.Fatalf("Expected %v got %v", expected, got)
+		}
+
+		expectedAnim := expected.Images()[0].(*testAnimation).AnimationPlayer
+		gotAnim := got.Images()[0].(*testAnimation).AnimationPlayer
+
+		if !reflect.DeepEqual(expectedAnim, gotAnim) {
+			t.Fatalf("Expected %v got %v", expectedAnim, gotAnim)
+		}
+	}
+}
+
+func TestSnapshotMigration(t *testing.T) {
+	registry := NewSnapshotRegistry(3)
+	registry.AddMigration(1, func(s *Snapshot) error {
+		for i := range s.Entities {
+			s.Entities[i].Fields["hp"] = s.Entities[i].Fields["health"]
+			delete(s.Entities[i].Fields, "health")
+		}
+		return nil
+	})
+
+	s := &Snapshot{
+		Version: 1,
+		Entities: []EntitySnapshot{
+			{Class: "save", Fields: map[string]interface{}{"health": 5}},
+		},
+	}
+
+	if err := registry.Migrate(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Version != 3 {
+		t.Fatalf("Expected %d got %d", 3, s.Version)
+	}
+
+	expected := map[string]interface{}{"hp": 5}
+	if !reflect.DeepEqual(expected, s.Entities[0].Fields) {
+		t.Fatalf("Expected %v got %v", expected, s.Entities[0].Fields)
+	}
+
+	s.Version = 4
+	if err := registry.Migrate(s); !errors.Is(err, InvalidSnapshotVersion(4)) {
+		t.Fatalf("Expected %v got %v", InvalidSnapshotVersion(4), err)
+	}
+
+	ctx := NewContext(&testRenderer{}, nil, nil)
+	s = &Snapshot{Version: 3, Entities: []EntitySnapshot{{Class: "unknown"}}}
+
+	if err := ctx.Restore(registry, s); !errors.Is(err, UnknownSnapshotClass("unknown")) {
+		t.Fatalf("Expected %v got %v", UnknownSnapshotClass("unknown"), err)
+	}
+
+	if err := s.UnmarshalBinary([]byte("ArdentMap\x00")); err != ErrInvalidSnapshot {
+		t.Fatalf("Expected %v got %v", ErrInvalidSnapshot, err)
+	}
+}
+
+type testPlaceEntity struct {
+	pos      Vec2
+	disposed bool
+}
+
+func (e *testPlaceEntity) Tick()                 {}
+func (e *testPlaceEntity) SetCollider(*Collider) {}
+func (e *testPlaceEntity) Position() Vec2        { return e.pos }
+func (e *testPlaceEntity) SetPosition(v Vec2)    { e.pos = v }
+func (e *testPlaceEntity) AddImage(...Image)     {}
+func (e *testPlaceEntity) Images() []Image       { return nil }
+func (e *testPlaceEntity) Class() string         { return "place" }
+func (e *testPlaceEntity) Dispose()              { e.disposed = true }
+func (e *testPlaceEntity) IsDisposed() bool      { return e.disposed }
+
+func TestSnapshotRestore(t *testing.T) {
+	registry := NewSnapshotRegistry(1)
+	registry.Register("place", func(EntitySnapshot) (Entity, error) {
+		return &testPlaceEntity{}, nil
+	})
+
+	ctx := NewContext(&testRenderer{viewport: image.Rect(0, 0, 200, 200)}, nil, nil)
+
+	if err := ctx.Restore(registry, &Snapshot{Version: 1, Tilemap: []byte{0}}); err != ErrSnapshotTilemap {
+		t.Fatalf("Expected %v got %v", ErrSnapshotTilemap, err)
+	}
+
+	// hit contacts and published events are discarded
+	a := &testHitEntity{shapes: []HitShape{{Name: "a", Shape: Circle{Radius: 5}, Layer: 1, Mask: 1}}}
+	b := &testHitEntity{shapes: []HitShape{{Name: "b", Shape: Circle{Radius: 5}, Layer: 1, Mask: 1}}}
+	ctx.AddEntity(a, b)
+	ctx.Tick()
+
+	if len(ctx.hits.active) == 0 {
+		t.Fatal("Expected hit contacts before restoring.")
+	}
+
+	var events int
+	ctx.Events.Subscribe(func(string) { events++ })
+	ctx.Events.Publish("saved")
+
+	s := &Snapshot{
+		Version:  1,
+		Entities: []EntitySnapshot{{Class: "place", Position: Vec2{X: 5, Y: 7}}},
+	}
+
+	if err := ctx.Restore(registry, s); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx.Tick()
+
+	if events != 0 {
+		t.Fatalf("Expected %d events got %d", 0, events)
+	}
+
+	if len(ctx.hits.active) != 0 || len(a.events) != 1 || len(b.events) != 1 {
+		t.Fatalf("Expected no hit contacts got %v %v", a.events, b.events)
+	}
+
+	restored := toEntities(ctx.partitionMap.Entries())
+	if len(restored) != 1 || restored[0].Position() != (Vec2{X: 5, Y: 7}) {
+		t.Fatalf("Expected %v got %v", Vec2{X: 5, Y: 7}, restored)
+	}
+
+	// chunked tilemaps reload unsaved chunks
+	provider := &testChunkProvider{
+		loads: make(map[image.Point]int),
+		saves: make(map[image.Point]int),
+	}
+	tmap := NewChunkedTilemap(128, 4, 2, provider, nil, nil)
+	ctx = NewContext(&testRenderer{}, nil, tmap)
+
+	tmap.SetTile(0, 0, 0, 1)
+
+	if err := ctx.Restore(registry, &Snapshot{Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := tmap.GetTileValue(0, 0, 0); v != 808 || provider.saves[image.Pt(0, 0)] != 0 {
+		t.Fatalf("Expected %d got %d", 808, v)
+	}
+}
@@ -0,0 +1,272 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+)
+
+// UnknownSteeringBehavior occurs when a steering
+// behavior name is not registered.
+type UnknownSteeringBehavior string
+
+// Error implements error.
+func (u UnknownSteeringBehavior) Error() string {
+	return fmt.Sprintf("unknown steering behavior: %s", string(u))
+}
+
+// SteeringFactory creates a SteeringBehavior from named parameters.
+type SteeringFactory func(params map[string]float64) SteeringBehavior
+
+var steeringFactories = map[string]SteeringFactory{
+	"approach": func(p map[string]float64) SteeringBehavior {
+		return &SteerApproach{ContextMapBehavior{Distance: p["distance"]}}
+	},
+	"avoid": func(p map[string]float64) SteeringBehavior {
+		return &SteerAvoid{ContextMapBehavior{Distance: p["distance"]}}
+	},
+	"walls": func(p map[string]float64) SteeringBehavior {
+		return &SteerWalls{ContextMapBehavior{Distance: p["distance"]}}
+	},
+	"flee": func(p map[string]float64) SteeringBehavior {
+		return &SteerFlee{ContextMapBehavior{Distance: p["distance"]}}
+	},
+	"orbit": func(p map[string]float64) SteeringBehavior {
+		return &SteerOrbit{
+			Radius:    p["radius"],
+			Distance:  p["distance"],
+			Clockwise: p["clockwise"] != 0,
+			Weight:    p["weight"],
+		}
+	},
+	"wander": func(p map[string]float64) SteeringBehavior {
+		return &SteerWander{Weight: p["weight"], Jitter: p["jitter"]}
+	},
+	"align": func(p map[string]float64) SteeringBehavior {
+		return &SteerAlign{Distance: p["distance"], Weight: p["weight"]}
+	},
+	"leader": func(p map[string]float64) SteeringBehavior {
+		return &SteerLeader{Distance: p["distance"], Weight: p["weight"]}
+	},
+}
+
+// RegisterSteeringBehavior registers a SteeringFactory by name,
+// replacing any existing factory. The built-in behaviors are
+// approach, avoid, walls and flee with a distance parameter,
+// orbit with radius, distance, clockwise and weight, wander with
+// weight and jitter, and align and leader with distance
+// and weight.
+func RegisterSteeringBehavior(name string, factory SteeringFactory) {
+	steeringFactories[name] = factory
+}
+
+// NewSteeringBehavior creates a registered SteeringBehavior.
+func NewSteeringBehavior(name string, params map[string]float64) (SteeringBehavior, error) {
+	factory, ok := steeringFactories[name]
+	if !ok {
+		return nil, UnknownSteeringBehavior(name)
+	}
+
+	return factory(params), nil
+}
+
+// SteerApproach adds interest towards excite positions,
+// weighted by how close they are.
+type SteerApproach struct {
+	ContextMapBehavior
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerApproach) Steer(cm *ContextMap, in *SteeringInput) {
+	for _, v := range in.Excite {
+		dist := in.Origin.Distance(v)
+		if dist == 0 {
+			continue
+		}
+
+		cm.add(cm.interest, in.Origin.AngleTo(v), falloff(dist, b.Distance), false, b.Mod)
+	}
+}
+
+// SteerAvoid adds danger towards inhibit positions,
+// weighted by how close they are.
+type SteerAvoid struct {
+	ContextMapBehavior
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerAvoid) Steer(cm *ContextMap, in *SteeringInput) {
+	for _, v := range in.Inhibit {
+		dist := in.Origin.Distance(v)
+		if dist == 0 {
+			continue
+		}
+
+		cm.add(cm.danger, in.Origin.AngleTo(v), falloff(dist, b.Distance), true, b.Mod)
+	}
+}
+
+// SteerFlee adds interest away from inhibit positions,
+// weighted by how close they are.
+type SteerFlee struct {
+	ContextMapBehavior
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerFlee) Steer(cm *ContextMap, in *SteeringInput) {
+	for _, v := range in.Inhibit {
+		dist := in.Origin.Distance(v)
+		if dist == 0 {
+			continue
+		}
+
+		cm.add(cm.interest, v.AngleTo(in.Origin), falloff(dist, b.Distance), false, b.Mod)
+	}
+}
+
+// SteerWalls adds danger towards the walls of the
+// ContextMap's Tilemap around the agent.
+type SteerWalls struct {
+	ContextMapBehavior
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerWalls) Steer(cm *ContextMap, in *SteeringInput) {
+	if cm.tmap == nil {
+		return
+	}
+
+	x, y := cm.tmap.IsoToIndex(in.Origin.X, in.Origin.Y)
+
+	for _, wall := range cm.tmap.WallsAround(image.Pt(x+1, y+1), 1) {
+		wx, wy := cm.tmap.IndexToIso(wall.X, wall.Y)
+		wy -= float64(cm.tmap.TileWidth - cm.tmap.TileWidth/4)
+
+		v := Vec2{X: wx, Y: wy}
+
+		dist := in.Origin.Distance(v)
+		if dist == 0 {
+			continue
+		}
+
+		cm.add(cm.danger, in.Origin.AngleTo(v), falloff(dist, b.Distance), true, b.Mod)
+	}
+}
+
+// SteerOrbit adds interest tangential to excite positions
+// within Distance, turning inwards outside of Radius
+// and outwards inside of it. Interest is weighted by
+// how close the positions are, scaled by Weight.
+type SteerOrbit struct {
+	Radius    float64
+	Distance  float64
+	Clockwise bool
+	// Weight scales the interest, and defaults to 1.
+	Weight float64
+	// Mod is an optional CMapModFunc applied to the interest.
+	Mod CMapModFunc
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerOrbit) Steer(cm *ContextMap, in *SteeringInput) {
+	for _, v := range in.Excite {
+		dist := in.Origin.Distance(v)
+		if dist == 0 || dist > b.Distance {
+			continue
+		}
+
+		// turn up to a right angle towards the orbit
+		correction := 0.0
+		if b.Radius > 0 {
+			correction = math.Max(-1, math.Min(1, (dist-b.Radius)/b.Radius)) * math.Pi / 2
+		}
+
+		tangent := math.Pi/2 - correction
+		if b.Clockwise {
+			tangent = -tangent
+		}
+
+		weight := b.Weight
+		if weight == 0 {
+			weight = 1
+		}
+
+		cm.add(cm.interest, in.Origin.AngleTo(v)+tangent, weight*falloff(dist, b.Distance), false, b.Mod)
+	}
+}
+
+// SteerWander adds interest around the agent's heading,
+// drifting randomly by up to Jitter radians per update.
+type SteerWander struct {
+	Weight float64
+	Jitter float64
+	// Rand is an optional source of randomness,
+	// for deterministic wandering.
+	Rand *rand.Rand
+
+	offset float64
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerWander) Steer(cm *ContextMap, in *SteeringInput) {
+	r := rand.Float64
+	if b.Rand != nil {
+		r = b.Rand.Float64
+	}
+
+	b.offset += (r()*2 - 1) * b.Jitter
+	b.offset = math.Max(-math.Pi/2, math.Min(math.Pi/2, b.offset))
+
+	cm.AddInterest(in.Heading+b.offset, b.Weight)
+}
+
+// SteerAlign adds interest in the average
+// heading of allies within Distance.
+type SteerAlign struct {
+	Distance float64
+	Weight   float64
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerAlign) Steer(cm *ContextMap, in *SteeringInput) {
+	var heading Vec2
+	for _, a := range in.Allies {
+		if in.Origin.Distance(a.Position) <= b.Distance {
+			heading = heading.Translate(a.Heading, 1)
+		}
+	}
+
+	if heading == (Vec2{}) {
+		return
+	}
+
+	cm.AddInterest(heading.Angle(), b.Weight)
+}
+
+// SteerLeader adds interest towards the leader
+// when it is further away than Distance, increasing
+// up to Weight at twice the distance.
+type SteerLeader struct {
+	Distance float64
+	Weight   float64
+}
+
+// Steer implements SteeringBehavior.
+func (b *SteerLeader) Steer(cm *ContextMap, in *SteeringInput) {
+	if in.Leader == nil {
+		return
+	}
+
+	dist := in.Origin.Distance(in.Leader.Position)
+	if dist <= b.Distance {
+		return
+	}
+
+	weight := b.Weight
+	if b.Distance > 0 {
+		weight *= math.Min(1, (dist-b.Distance)/b.Distance)
+	}
+
+	cm.AddInterest(in.Origin.AngleTo(in.Leader.Position), weight)
+}
@@ -0,0 +1,145 @@
+package engine
+
+import "sort"
+
+// TileShape describes the collision shape of a tile.
+type TileShape byte
+
+const (
+	// TileShapeNone indicates a tile without collision.
+	TileShapeNone TileShape = iota
+
+	// TileShapeFull indicates a tile that blocks its entire diamond.
+	TileShapeFull
+)
+
+// TileFlag is a bitmask of tile properties.
+type TileFlag uint32
+
+const (
+	// TileFlagOpaque indicates that a tile blocks line of sight.
+	TileFlagOpaque TileFlag = 1 << iota
+
+	// TileFlagSlippery indicates that a tile has reduced friction.
+	TileFlagSlippery
+
+	// TileFlagHazard indicates that a tile deals damage.
+	TileFlagHazard
+
+	// TileFlagUser is the first flag available for game specific use.
+	// Additional flags can be declared as TileFlagUser << n.
+	TileFlagUser TileFlag = 1 << 16
+)
+
+// TileDef describes the properties of a tile value.
+type TileDef struct {
+	// Name is a human readable identifier for the tile.
+	Name string
+	// Shape is the collision shape of the tile.
+	Shape TileShape
+	// Flags is a bitmask of tile properties.
+	Flags TileFlag
+	// Cost is the movement cost multiplier used by pathfinding.
+	// A cost of 0 is treated as 1.
+	Cost float64
+	// Props contains arbitrary custom properties.
+	Props map[string]interface{}
+}
+
+// Has indicates whether all of the given flags are set.
+func (d TileDef) Has(flag TileFlag) bool {
+	return d.Flags&flag == flag
+}
+
+// Prop returns a custom property.
+func (d TileDef) Prop(key string) (interface{}, bool) {
+	v, ok := d.Props[key]
+	return v, ok
+}
+
+// PropString returns a custom string property.
+func (d TileDef) PropString(key string) (string, bool) {
+	v, ok := d.Props[key].(string)
+	return v, ok
+}
+
+// PropFloat returns a custom numeric property as a float64.
+func (d TileDef) PropFloat(key string) (float64, bool) {
+	switch v := d.Props[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+
+	return 0, false
+}
+
+// PropInt returns a custom integer property.
+func (d TileDef) PropInt(key string) (int, bool) {
+	v, ok := d.Props[key].(int)
+	return v, ok
+}
+
+// PropBool returns a custom boolean property.
+func (d TileDef) PropBool(key string) (bool, bool) {
+	v, ok := d.Props[key].(bool)
+	return v, ok
+}
+
+// TileRegistry maps tile values to their TileDef.
+type TileRegistry struct {
+	defs map[int]TileDef
+}
+
+// NewTileRegistry returns an empty *TileRegistry.
+func NewTileRegistry() *TileRegistry {
+	return &TileRegistry{
+		defs: make(map[int]TileDef),
+	}
+}
+
+// Register sets the TileDef for a tile value.
+func (r *TileRegistry) Register(tile int, def TileDef) {
+	r.defs[tile] = def
+}
+
+// Get returns the TileDef for a tile value.
+func (r *TileRegistry) Get(tile int) (TileDef, bool) {
+	if r == nil {
+		return TileDef{}, false
+	}
+
+	def, ok := r.defs[tile]
+	return def, ok
+}
+
+// Lookup returns the tile value registered with a given name.
+func (r *TileRegistry) Lookup(name string) (int, bool) {
+	if r == nil {
+		return 0, false
+	}
+
+	for tile, def := range r.defs {
+		if def.Name == name {
+			return tile, true
+		}
+	}
+
+	return 0, false
+}
+
+// Tiles returns all registered tile values in ascending order.
+func (r *TileRegistry) Tiles() []int {
+	if r == nil {
+		return nil
+	}
+
+	tiles := make([]int, 0, len(r.defs))
+	for tile := range r.defs {
+		tiles = append(tiles, tile)
+	}
+	sort.Ints(tiles)
+
+	return tiles
+}
@@ -0,0 +1,193 @@
+package engine
+
+import "image"
+
+// maxDirtyRegions is the number of dirty regions retained
+// by a Tilemap. Consumers that fall further behind are
+// asked to refresh the entire tilemap.
+const maxDirtyRegions = 256
+
+type dirtyRegion struct {
+	rev  uint64
+	rect image.Rectangle
+}
+
+// SetTile sets the value of a single tile, marking it as dirty.
+// False is returned if the tile is out of bounds.
+func (t *Tilemap) SetTile(x, y, z, tile int) bool {
+
+	if z < 0 || z > 1 || !t.InBounds(image.Pt(x, y), 1) {
+		return false
+	}
+
+	if t.get(x, y, z) == tile {
+		return true
+	}
+
+	t.set(x, y, z, tile)
+	t.MarkDirty(image.Rect(x, y, x+1, y+1))
+
+	return true
+}
+
+// FillRect sets tile values for a given region,
+// marking the region as dirty.
+func (t *Tilemap) FillRect(tile int, r image.Rectangle, z int) {
+
+	if z < 0 || z > 1 {
+		return
+	}
+
+	r = r.Intersect(t.bounds)
+	if r.Empty() {
+		return
+	}
+
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			t.set(x, y, z, tile)
+		}
+	}
+
+	t.MarkDirty(r)
+}
+
+// MarkDirty marks a region of the tilemap as changed.
+// This is done automatically by the edit methods of Tilemap,
+// but must be called manually after writing to Data directly.
+func (t *Tilemap) MarkDirty(r image.Rectangle) {
+
+	t.rev++
+	t.dirty = append(t.dirty, dirtyRegion{
+		rev:  t.rev,
+		rect: r,
+	})
+
+	if len(t.dirty) > maxDirtyRegions {
+		t.dirtyFloor = t.dirty[0].rev
+		t.dirty[0] = dirtyRegion{}
+		t.dirty = t.dirty[1:]
+	}
+}
+
+// Revision returns the current edit revision of the tilemap.
+// The revision is incremented every time a region is marked dirty.
+func (t *Tilemap) Revision() uint64 {
+	return t.rev
+}
+
+// DirtySince returns the regions that have changed after
+// a given revision, along with the current revision.
+// If the requested revision is too old, the full tilemap
+// bounds are returned instead.
+func (t *Tilemap) DirtySince(rev uint64) ([]image.Rectangle, uint64) {
+
+	if rev >= t.rev {
+		return nil, t.rev
+	}
+
+	if rev < t.dirtyFloor {
+		return []image.Rectangle{t.bounds}, t.rev
+	}
+
+	var regions []image.Rectangle
+	for _, d := range t.dirty {
+		if d.rev > rev {
+			regions = append(regions, d.rect)
+		}
+	}
+
+	return regions, t.rev
+}
+
+// tileCache is a lookup of tile values to the
+// positions containing them on either layer.
+type tileCache struct {
+	points map[int][]image.Point
+	// index maps each position to its index in points.
+	// It is only built once the cache is first updated.
+	index map[int]map[image.Point]int
+
+	rev uint64
+}
+
+func newTileCache(rev uint64) *tileCache {
+	return &tileCache{
+		points: make(map[int][]image.Point),
+		rev:    rev,
+	}
+}
+
+// add adds the values at a position while the cache is built.
+func (c *tileCache) add(x, y, floor, wall int) {
+	p := image.Pt(x, y)
+
+	c.points[floor] = append(c.points[floor], p)
+	if wall != floor {
+		c.points[wall] = append(c.points[wall], p)
+	}
+}
+
+// update replaces any cached values at an edited position.
+func (c *tileCache) update(x, y, floor, wall int) {
+	if c.index == nil {
+		c.buildIndex()
+	}
+
+	p := image.Pt(x, y)
+
+	for tile := range c.index {
+		if tile != floor && tile != wall {
+			c.remove(tile, p)
+		}
+	}
+
+	c.insert(floor, p)
+	c.insert(wall, p)
+}
+
+func (c *tileCache) buildIndex() {
+	c.index = make(map[int]map[image.Point]int, len(c.points))
+
+	for tile, points := range c.points {
+		index := make(map[image.Point]int, len(points))
+		for i, p := range points {
+			index[p] = i
+		}
+
+		c.index[tile] = index
+	}
+}
+
+func (c *tileCache) insert(tile int, p image.Point) {
+	index := c.index[tile]
+	if index == nil {
+		index = make(map[image.Point]int)
+		c.index[tile] = index
+	}
+
+	if _, ok := index[p]; ok {
+		return
+	}
+
+	index[p] = len(c.points[tile])
+	c.points[tile] = append(c.points[tile], p)
+}
+
+func (c *tileCache) remove(tile int, p image.Point) {
+	index := c.index[tile]
+
+	i, ok := index[p]
+	if !ok {
+		return
+	}
+
+	points := c.points[tile]
+	last := points[len(points)-1]
+
+	points[i] = last
+	index[last] = i
+
+	c.points[tile] = points[:len(points)-1]
+	delete(index, p)
+}
@@ -13,16 +13,36 @@ type Tilemap struct {
 	// TileWidth is the tiles width in pixels.
 	TileWidth int
 	// Data contains values representing tiles.
+	// Data is unused by chunked tilemaps.
 	Data [2][][]int
 	// Mapper maps data values to tile images.
+	// Values may also be Animations, in which case every
+	// tile of that value shares the same animation clock.
 	Mapper map[int]Image
+	// Phase optionally offsets the animation clock of
+	// individual animated tiles.
+	Phase TilePhaseFunc
 	// OverlapEvent is for each tile, allowing custom
 	// overlap behavior (Alpha transitions, events, etc).
 	OverlapEvent TileOverlapEvent
+	// Defs optionally describes the properties of tile values.
+	// Without a TileDef, any non-zero tile on layer 1 is
+	// treated as a solid and opaque wall.
+	Defs *TileRegistry
+	// Fog optionally hides unexplored tiles and
+	// darkens tiles that are not currently visible.
+	Fog *FogOfWar
 
 	bounds image.Rectangle
 
-	cache map[int][]image.Point
+	chunks *chunkCache
+
+	cache *tileCache
+
+	// dirty region tracking
+	rev        uint64
+	dirtyFloor uint64
+	dirty      []dirtyRegion
 }
 
 // TileOverlapEvent updates renderer state in the case of a tile overlap.
@@ -30,6 +50,24 @@ type Tilemap struct {
 // Image is passed, along with arbitrary data returned from the previous call for state.
 type TileOverlapEvent func(bool, Image, interface{}) interface{}
 
+// TilePhaseFunc returns an animation phase offset in ticks
+// for the tile at a given position and layer. This allows animated
+// tiles of the same type to play out of sync with each other.
+type TilePhaseFunc func(x, y, z int) int
+
+// HashTilePhase returns a TilePhaseFunc that assigns each tile
+// a stable pseudo-random phase offset in the range [0, max).
+func HashTilePhase(max int) TilePhaseFunc {
+	return func(x, y, z int) int {
+		if max < 1 {
+			return 0
+		}
+
+		h := uint32(x)*73856093 ^ uint32(y)*19349663 ^ uint32(z)*83492791
+		return int(h % uint32(max))
+	}
+}
+
 // NewTilemap returns an instantiated *Tilemap.
 // All parameters are required except for overlapEvent.
 func NewTilemap(
@@ -47,6 +85,119 @@ func NewTilemap(
 	}
 }
 
+// NewChunkedTilemap returns an instantiated *Tilemap whose data
+// is streamed in square chunks of chunkSize tiles from a ChunkProvider.
+// At most capacity chunks are kept in memory, evicting the
+// least recently used chunk first. The capacity should cover
+// at least the area visible around the camera.
+func NewChunkedTilemap(
+	tileWidth, chunkSize, capacity int,
+	provider ChunkProvider,
+	mapper map[int]Image,
+	overlapEvent TileOverlapEvent,
+) *Tilemap {
+	if chunkSize < 1 || capacity < 1 {
+		panic("invalid chunk configuration")
+	}
+
+	return &Tilemap{
+		TileWidth:    tileWidth,
+		Mapper:       mapper,
+		OverlapEvent: overlapEvent,
+		bounds:       provider.Bounds(),
+		chunks:       newChunkCache(provider, chunkSize, capacity),
+	}
+}
+
+// IsChunked indicates whether the tilemap streams its data in chunks.
+func (t *Tilemap) IsChunked() bool {
+	return t.chunks != nil
+}
+
+// Bounds returns the bounds of the tilemap in tiles.
+func (t *Tilemap) Bounds() image.Rectangle {
+	return t.bounds
+}
+
+// Stream loads all chunks within a given radius in tiles
+// around an isometric position, marking them as recently used.
+// It has no effect on tilemaps that are not chunked.
+func (t *Tilemap) Stream(pos Vec2, radius int) error {
+	if t.chunks == nil {
+		return nil
+	}
+
+	x, y := t.IsoToIndex(pos.X, pos.Y)
+	r := image.Rect(x-radius, y-radius, x+radius+1, y+radius+1).Intersect(t.bounds)
+	if r.Empty() {
+		return nil
+	}
+
+	min, _, _ := t.chunks.key(r.Min.X, r.Min.Y)
+	max, _, _ := t.chunks.key(r.Max.X-1, r.Max.Y-1)
+
+	for cx := min.X; cx <= max.X; cx++ {
+		for cy := min.Y; cy <= max.Y; cy++ {
+			if t.chunks.entry(image.Pt(cx, cy)) == nil {
+				return t.chunks.takeErr()
+			}
+		}
+	}
+
+	return nil
+}
+
+// FlushChunks saves all modified chunks if
+// the ChunkProvider implements ChunkSaver.
+func (t *Tilemap) FlushChunks() error {
+	if t.chunks == nil {
+		return nil
+	}
+
+	return t.chunks.flush()
+}
+
+// ResetChunks discards all loaded chunks without saving them,
+// so that they are reloaded from the ChunkProvider. It has
+// no effect on tilemaps that are not chunked.
+func (t *Tilemap) ResetChunks() {
+	if t.chunks == nil {
+		return
+	}
+
+	t.chunks.reset()
+	t.MarkDirty(t.bounds)
+}
+
+// ChunkErr returns and clears the most recent error that
+// occurred while implicitly loading or saving chunks.
+func (t *Tilemap) ChunkErr() error {
+	if t.chunks == nil {
+		return nil
+	}
+
+	return t.chunks.takeErr()
+}
+
+// get returns the value of an in bounds tile.
+func (t *Tilemap) get(x, y, z int) int {
+	if t.chunks != nil {
+		return t.chunks.get(x, y, z)
+	}
+
+	return t.Data[z][y][x]
+}
+
+// set sets the value of an in bounds tile.
+func (t *Tilemap) set(x, y, z, tile int) {
+	if t.chunks != nil {
+		t.chunks.set(x, y, z, tile)
+		return
+	}
+
+	t.Data[z][y][x] = tile
+}
+
 // IsoToIndex converts isometric coordinates to a tile index.
 func (t *Tilemap) IsoToIndex(x, y float64) (int, int) {
 	ix := int(math.Ceil((x/float64(t.TileWidth/2) + y/float64(t.TileWidth/4)) / 2))
@@ -63,6 +214,23 @@ func (t *Tilemap) IndexToIso(i, j int) (float64, float64) {
 	return float64(x), float64(y)
 }
 
+// WorldToTile returns the index of the tile
+// containing an isometric world position.
+func (t *Tilemap) WorldToTile(v Vec2) image.Point {
+	x, y := t.IsoToIndex(v.X, v.Y)
+	return image.Pt(x+1, y+1)
+}
+
+// TileToWorld returns the isometric world position
+// of the center of a tile.
+func (t *Tilemap) TileToWorld(p image.Point) Vec2 {
+	x, y := t.IndexToIso(p.X, p.Y)
+	return Vec2{
+		X: x,
+		Y: y - float64(t.TileWidth-t.TileWidth/4),
+	}
+}
+
 // GetTileValue returns the value associated with a tile.
 func (t *Tilemap) GetTileValue(x, y, z int) int {
 
@@ -70,7 +238,104 @@ func (t *Tilemap) GetTileValue(x, y, z int) int {
 		return 0
 	}
 
-	return t.Data[z][y][x]
+	return t.get(x, y, z)
+}
+
+// TileDef returns the TileDef associated with a tile.
+func (t *Tilemap) TileDef(x, y, z int) (TileDef, bool) {
+	return t.Defs.Get(t.GetTileValue(x, y, z))
+}
+
+// HasFlag indicates whether a tile on either layer has the given flags.
+func (t *Tilemap) HasFlag(x, y int, flag TileFlag) bool {
+	for z := 0; z < 2; z++ {
+		if def, ok := t.TileDef(x, y, z); ok && def.Has(flag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsSolid indicates whether a tile blocks movement.
+func (t *Tilemap) IsSolid(x, y int) bool {
+	for z := 0; z < 2; z++ {
+		tile := t.GetTileValue(x, y, z)
+		if tile == 0 {
+			continue
+		}
+
+		def, ok := t.Defs.Get(tile)
+		if !ok {
+			if z == 1 {
+				return true
+			}
+			continue
+		}
+
+		if def.Shape != TileShapeNone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOpaque indicates whether a tile blocks line of sight.
+func (t *Tilemap) IsOpaque(x, y int) bool {
+	for z := 0; z < 2; z++ {
+		tile := t.GetTileValue(x, y, z)
+		if tile == 0 {
+			continue
+		}
+
+		def, ok := t.Defs.Get(tile)
+		if !ok {
+			if z == 1 {
+				return true
+			}
+			continue
+		}
+
+		if def.Has(TileFlagOpaque) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MoveCost returns the movement cost multiplier of a tile.
+// The highest cost of both layers is used, defaulting to 1.
+func (t *Tilemap) MoveCost(x, y int) float64 {
+	cost := 1.0
+
+	for z := 0; z < 2; z++ {
+		if def, ok := t.TileDef(x, y, z); ok && def.Cost > cost {
+			cost = def.Cost
+		}
+	}
+
+	return cost
+}
+
+// IsPassable indicates whether a point of a given size
+// is within the tilemap and contains no solid tiles.
+func (t *Tilemap) IsPassable(p image.Point, size int) bool {
+
+	if !t.InBounds(p, size) {
+		return false
+	}
+
+	for x := p.X; x < p.X+size; x++ {
+		for y := p.Y; y < p.Y+size; y++ {
+			if t.IsSolid(x, y) {
+				return false
+			}
+		}
+	}
+
+	return true
 }
 
 var ndirs = [4]image.Point{
@@ -103,15 +368,14 @@ func (t *Tilemap) Neighbors(p image.Point, size int) (c []image.Point) {
 	return
 }
 
-// WallsAround returns positions of wall tiles around a given position
+// WallsAround returns positions of solid tiles around a given position
 // within a given range.
 func (t *Tilemap) WallsAround(p image.Point, dist int) (c []image.Point) {
 
 	for x := p.X - dist; x < p.X+dist; x++ {
 		for y := p.Y - dist; y < p.Y+dist; y++ {
 
-			tile := t.GetTileValue(x, y, 1)
-			if tile != 0 {
+			if t.IsSolid(x, y) {
 				c = append(c, image.Pt(x, y))
 			}
 		}
@@ -150,7 +414,7 @@ func (t *Tilemap) ContainsAll(tile int, p image.Point, z, size int) bool {
 
 	for x := p.X; x < p.X+size; x++ {
 		for y := p.Y; y < p.Y+size; y++ {
-			if t.Data[z][y][x] != tile {
+			if t.get(x, y, z) != tile {
 				return false
 			}
 		}
@@ -171,7 +435,7 @@ func (t *Tilemap) ContainsAny(tile int, p image.Point, z, size int) bool {
 
 	for x := p.X; x < p.X+size; x++ {
 		for y := p.Y; y < p.Y+size; y++ {
-			if t.Data[z][y][x] == tile {
+			if t.get(x, y, z) == tile {
 				return true
 			}
 		}
@@ -180,7 +444,8 @@ func (t *Tilemap) ContainsAny(tile int, p image.Point, z, size int) bool {
 	return false
 }
 
-// Fill sets tile values for a given location and size.
+// Fill sets tile values for a given location and size,
+// marking the region as dirty.
 func (t *Tilemap) Fill(tile int, p image.Point, z, size int) []image.Point {
 
 	if size < 1 {
@@ -193,23 +458,28 @@ func (t *Tilemap) Fill(tile int, p image.Point, z, size int) []image.Point {
 		for y := p.Y; y < p.Y+size; y++ {
 			if t.InBounds(image.Pt(x, y), 1) {
 				points = append(points, image.Pt(x, y))
-				t.Data[z][y][x] = tile
+				t.set(x, y, z, tile)
 			}
 		}
 	}
 
+	if len(points) > 0 {
+		t.MarkDirty(image.Rect(p.X, p.Y, p.X+size, p.Y+size))
+	}
+
 	return points
 }
 
 // Image take a tile to color map and returns an image of the tilemap.
+// Chunked tilemaps will load every chunk to produce the image.
 func (t *Tilemap) Image(colors map[int]color.Color) image.Image {
 
 	img := image.NewRGBA(t.bounds)
 
-	for x := 0; x < t.bounds.Dx(); x++ {
-		for y := 0; y < t.bounds.Dy(); y++ {
+	for x := t.bounds.Min.X; x < t.bounds.Max.X; x++ {
+		for y := t.bounds.Min.Y; y < t.bounds.Max.Y; y++ {
 
-			v1, v2 := t.Data[0][y][x], t.Data[1][y][x]
+			v1, v2 := t.get(x, y, 0), t.get(x, y, 1)
 
 			if c, ok := colors[v1]; ok {
 				img.Set(x, y, c)
@@ -224,16 +494,50 @@ func (t *Tilemap) Image(colors map[int]color.Color) image.Image {
 }
 
 // BuildCache rebuilds a tile lookup cache.
+// If a cache already exists, only regions marked dirty
+// since the last build are updated.
+// Chunked tilemaps only cache tiles of currently loaded chunks,
+// and are always rebuilt in full.
 func (t *Tilemap) BuildCache() {
 
-	t.cache = map[int][]image.Point{}
+	if t.cache != nil && t.chunks == nil && t.cache.rev >= t.dirtyFloor {
+		regions, rev := t.DirtySince(t.cache.rev)
+		for _, r := range regions {
+			r = r.Intersect(t.bounds)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				for y := r.Min.Y; y < r.Max.Y; y++ {
+					t.cache.update(x, y, t.get(x, y, 0), t.get(x, y, 1))
+				}
+			}
+		}
+		t.cache.rev = rev
+
+		return
+	}
+
+	t.cache = newTileCache(t.rev)
+
+	if t.chunks == nil {
+		for x := t.bounds.Min.X; x < t.bounds.Max.X; x++ {
+			for y := t.bounds.Min.Y; y < t.bounds.Max.Y; y++ {
+				t.cache.add(x, y, t.get(x, y, 0), t.get(x, y, 1))
+			}
+		}
+		return
+	}
 
-	for x := 0; x < t.bounds.Dx(); x++ {
-		for y := 0; y < t.bounds.Dy(); y++ {
-			floor := t.Data[0][y][x]
-			wall := t.Data[1][y][x]
-			t.cache[floor] = append(t.cache[floor], image.Pt(x, y))
-			t.cache[wall] = append(t.cache[wall], image.Pt(x, y))
+	for key := range t.chunks.entries {
+		r := image.Rect(
+			key.X*t.chunks.size,
+			key.Y*t.chunks.size,
+			(key.X+1)*t.chunks.size,
+			(key.Y+1)*t.chunks.size,
+		).Intersect(t.bounds)
+
+		for x := r.Min.X; x < r.Max.X; x++ {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				t.cache.add(x, y, t.get(x, y, 0), t.get(x, y, 1))
+			}
 		}
 	}
 }
@@ -242,7 +546,7 @@ func (t *Tilemap) BuildCache() {
 // contained within a given tile.
 func (t *Tilemap) RandomPos(tile int) (float64, float64) {
 
-	tiles := t.cache[tile]
+	tiles := t.cache.points[tile]
 
 	tp := tiles[rand.Intn(len(tiles))]
 
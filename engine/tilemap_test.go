@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"encoding"
+	"encoding/json"
 	"image"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -294,3 +297,280 @@ func TestContainsAll(t *testing.T) {}
 func TestFill(t *testing.T) {}
 
 func TestWallsAround(t *testing.T) {}
+
+func TestIsSolid(t *testing.T) {
+
+	// walls without definitions are solid
+	for y := 0; y < len(testTilemapData[1]); y++ {
+		for x := 0; x < len(testTilemapData[1][y]); x++ {
+			expected := testTilemapData[1][y][x] != 0
+			actual := testTilemap.IsSolid(x, y)
+			if expected != actual {
+				t.Fatalf(
+					"Input %d %d, expected IsSolid %t. Declared as %t.",
+					x, y,
+					expected, actual,
+				)
+			}
+		}
+	}
+
+	// walls that only block vision
+	tmap := NewTilemap(128, testTilemapData, nil, nil)
+	tmap.Defs = NewTileRegistry()
+	tmap.Defs.Register(3, TileDef{Name: "glass", Flags: TileFlagOpaque})
+
+	if tmap.IsSolid(2, 0) {
+		t.Fatal("Input 2 0, expected IsSolid false. Declared as true.")
+	}
+	if !tmap.IsOpaque(2, 0) {
+		t.Fatal("Input 2 0, expected IsOpaque true. Declared as false.")
+	}
+
+	// solid floor tiles
+	tmap.Defs.Register(2, TileDef{Name: "water", Shape: TileShapeFull})
+
+	if !tmap.IsSolid(0, 0) {
+		t.Fatal("Input 0 0, expected IsSolid true. Declared as false.")
+	}
+	if tmap.IsPassable(image.Pt(0, 0), 2) {
+		t.Fatal("Input 0 0 with scale 2, expected IsPassable false. Declared as true.")
+	}
+}
+
+type testChunkProvider struct {
+	loads map[image.Point]int
+	saves map[image.Point]int
+}
+
+func (p *testChunkProvider) Bounds() image.Rectangle {
+	return image.Rect(-8, -8, 8, 8)
+}
+
+func (p *testChunkProvider) LoadChunk(cx, cy, size int) (*Chunk, error) {
+	p.loads[image.Pt(cx, cy)]++
+
+	c := NewChunk(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c.Data[0][y][x] = (cx+8)*100 + (cy + 8)
+		}
+	}
+
+	return c, nil
+}
+
+func (p *testChunkProvider) SaveChunk(cx, cy int, c *Chunk) error {
+	p.saves[image.Pt(cx, cy)]++
+	return nil
+}
+
+func TestChunkedTilemap(t *testing.T) {
+
+	provider := &testChunkProvider{
+		loads: make(map[image.Point]int),
+		saves: make(map[image.Point]int),
+	}
+	tmap := NewChunkedTilemap(128, 4, 2, provider, nil, nil)
+
+	// reads across chunk boundaries
+	for _, test := range []struct {
+		input    image.Point
+		expected int
+	}{
+		{image.Pt(0, 0), 808},
+		{image.Pt(-1, 0), 708},
+		{image.Pt(3, -1), 807},
+		{image.Pt(-5, -5), 606},
+		{image.Pt(7, 7), 909},
+	} {
+		actual := tmap.GetTileValue(test.input.X, test.input.Y, 0)
+		if actual != test.expected {
+			t.Fatalf(
+				"Input %v, expected %d got %d",
+				test.input,
+				test.expected,
+				actual,
+			)
+		}
+	}
+
+	// out of bounds
+	if v := tmap.GetTileValue(8, 0, 0); v != 0 {
+		t.Fatalf("Expected 0 for out of bounds. Got %d", v)
+	}
+
+	// edits are saved on eviction
+	tmap.Fill(1, image.Pt(0, 0), 1, 1)
+	tmap.GetTileValue(-8, -8, 0)
+	tmap.GetTileValue(-8, 4, 0)
+
+	if provider.saves[image.Pt(0, 0)] != 1 {
+		t.Fatalf("Expected modified chunk to be saved once. Got %d", provider.saves[image.Pt(0, 0)])
+	}
+
+	// evicted chunks are loaded again
+	loads := provider.loads[image.Pt(0, 0)]
+	tmap.GetTileValue(0, 0, 0)
+	if provider.loads[image.Pt(0, 0)] != loads+1 {
+		t.Fatal("Expected evicted chunk to be loaded again.")
+	}
+}
+
+func TestChunkSize(t *testing.T) {
+	dir, err := os.MkdirTemp("", "chunks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bounds := image.Rect(0, 0, 8, 8)
+	provider := NewFileChunkProvider(dir, bounds, nil)
+
+	// a chunk saved with a different chunk size
+	if err := provider.SaveChunk(0, 0, NewChunk(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.LoadChunk(0, 0, 4); err == nil {
+		t.Fatal("Expected error loading chunk of the wrong size")
+	}
+
+	tmap := NewChunkedTilemap(128, 4, 2, provider, nil, nil)
+	if v := tmap.GetTileValue(1, 1, 0); v != 0 {
+		t.Fatalf("Expected %v got %v", 0, v)
+	}
+
+	if err := tmap.ChunkErr(); err == nil {
+		t.Fatal("Expected chunk error")
+	}
+
+	// the error is cleared once returned
+	if err := tmap.ChunkErr(); err != nil {
+		t.Fatalf("Expected %v got %v", nil, err)
+	}
+}
+
+func newTestTilemap() *Tilemap {
+	var data [2][][]int
+	for z := range testTilemapData {
+		for _, row := range testTilemapData[z] {
+			data[z] = append(data[z], append([]int(nil), row...))
+		}
+	}
+
+	return NewTilemap(128, data, nil, nil)
+}
+
+func TestTilemapMarshal(t *testing.T) {
+
+	tmap := newTestTilemap()
+	tmap.Defs = NewTileRegistry()
+	tmap.Defs.Register(3, TileDef{Name: "tree", Shape: TileShapeFull})
+
+	bin, err := tmap.MarshalMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := tmap.MarshalMapJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// definitions are resolved by name
+	defs := NewTileRegistry()
+	defs.Register(4, TileDef{Name: "tree", Shape: TileShapeFull})
+
+	for name, unmarshal := range map[string]func(*Tilemap) error{
+		"binary": func(t *Tilemap) error { return t.UnmarshalMap(bin) },
+		"json":   func(t *Tilemap) error { return t.UnmarshalMapJSON(js) },
+	} {
+		actual := &Tilemap{Defs: defs}
+		if err := unmarshal(actual); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", name, err)
+		}
+
+		if actual.TileWidth != 128 || actual.Bounds() != tmap.Bounds() {
+			t.Fatalf(
+				"Incorrect %s tilemap. Expected width 128 bounds %v, got %d %v",
+				name,
+				tmap.Bounds(),
+				actual.TileWidth,
+				actual.Bounds(),
+			)
+		}
+
+		if v := actual.GetTileValue(2, 0, 1); v != 4 {
+			t.Fatalf("Expected %s tile definition to be remapped to 4. Got %d", name, v)
+		}
+		if v := actual.GetTileValue(0, 0, 0); v != 2 {
+			t.Fatalf("Expected %s tile value 2. Got %d", name, v)
+		}
+	}
+
+	// the encoding interfaces would be promoted through Context
+	for _, v := range []interface{}{tmap, &Context{}} {
+		if _, ok := v.(json.Marshaler); ok {
+			t.Fatalf("Expected %T to not implement json.Marshaler", v)
+		}
+
+		if _, ok := v.(encoding.BinaryMarshaler); ok {
+			t.Fatalf("Expected %T to not implement encoding.BinaryMarshaler", v)
+		}
+	}
+
+	// invalid signature
+	if err := new(Tilemap).UnmarshalMap([]byte("Ardent\x00")); err != ErrInvalidTilemap {
+		t.Fatalf("Expected ErrInvalidTilemap. Got %v", err)
+	}
+}
+
+func TestDirtySince(t *testing.T) {
+
+	tmap := newTestTilemap()
+	tmap.BuildCache()
+
+	rev := tmap.Revision()
+	tmap.SetTile(1, 1, 1, 5)
+	tmap.Fill(5, image.Pt(3, 3), 1, 2)
+
+	expected := []image.Rectangle{image.Rect(1, 1, 2, 2), image.Rect(3, 3, 5, 5)}
+	actual, _ := tmap.DirtySince(rev)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected dirty regions %v got %v", expected, actual)
+	}
+
+	// incremental cache update
+	tmap.BuildCache()
+	if n := len(tmap.cache.points[5]); n != 5 {
+		t.Fatalf("Expected 5 cached positions for tile 5. Got %d", n)
+	}
+
+	tmap.SetTile(1, 1, 1, 0)
+	tmap.BuildCache()
+	if n := len(tmap.cache.points[5]); n != 4 {
+		t.Fatalf("Expected 4 cached positions for tile 5. Got %d", n)
+	}
+
+	// too far behind
+	for i := 0; i < maxDirtyRegions+1; i++ {
+		tmap.SetTile(0, 0, 0, i+10)
+	}
+	actual, _ = tmap.DirtySince(rev)
+	if !reflect.DeepEqual([]image.Rectangle{tmap.Bounds()}, actual) {
+		t.Fatalf("Expected full bounds for stale revision. Got %v", actual)
+	}
+}
+
+func TestWorldToTile(t *testing.T) {
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			expected := image.Pt(x, y)
+			actual := testTilemap.WorldToTile(testTilemap.TileToWorld(expected))
+			if expected != actual {
+				t.Fatalf("Expected %v got %v", expected, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+)
+
+// TilemapSignature is the signature prepended to all Ardent tilemap files.
+const TilemapSignature = "ArdentMap"
+
+// TilemapVersion is the current tilemap file format version.
+const TilemapVersion = 1
+
+var (
+	// ErrInvalidTilemap occurs when tilemap data is not a valid tilemap file.
+	ErrInvalidTilemap = errors.New("invalid tilemap file")
+
+	// ErrChunkedTilemap occurs when attempting to marshal a chunked tilemap.
+	// Chunked tilemaps are persisted through their ChunkProvider.
+	ErrChunkedTilemap = errors.New("cannot marshal chunked tilemap")
+)
+
+// InvalidTilemapVersion occurs when a tilemap file has an unsupported version.
+type InvalidTilemapVersion int
+
+// Error implements error.
+func (i InvalidTilemapVersion) Error() string {
+	return fmt.Sprintf("invalid tilemap version: %d", int(i))
+}
+
+// UnknownTileDef occurs when a tilemap file references a
+// tile definition that is not in the Tilemap's TileRegistry.
+type UnknownTileDef string
+
+// Error implements error.
+func (u UnknownTileDef) Error() string {
+	return fmt.Sprintf("unknown tile definition: %s", string(u))
+}
+
+// tilemapFile is the serialized form of a Tilemap.
+//
+// Tilemap does not implement the encoding interfaces, as
+// they would be promoted through Context, which embeds it.
+type tilemapFile struct {
+	Signature string         `json:"signature"`
+	Version   int            `json:"version"`
+	TileWidth int            `json:"tileWidth"`
+	Layers    [2][][]int     `json:"layers"`
+	Defs      map[int]string `json:"defs,omitempty"`
+}
+
+// MarshalMap marshals the tilemap to a binary tilemap file.
+// The format is the tilemap signature, a null byte, then gob-encoded data.
+// Tile definitions are stored by name, and resolved against
+// the Tilemap's TileRegistry when unmarshalled.
+func (t *Tilemap) MarshalMap() ([]byte, error) {
+	f, err := t.toFile()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(TilemapSignature)
+	buf.WriteByte(0)
+
+	if err := gob.NewEncoder(buf).Encode(f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMap unmarshals a binary tilemap file.
+// The Defs field should be set beforehand for tile
+// definition references to be resolved.
+func (t *Tilemap) UnmarshalMap(data []byte) error {
+	buf := bytes.NewBuffer(data)
+
+	magic, err := buf.ReadString(0)
+	if err != nil {
+		return ErrInvalidTilemap
+	}
+
+	if magic[:len(magic)-1] != TilemapSignature {
+		return ErrInvalidTilemap
+	}
+
+	var f tilemapFile
+	if err := gob.NewDecoder(buf).Decode(&f); err != nil {
+		return err
+	}
+	f.Signature = TilemapSignature
+
+	return t.fromFile(f)
+}
+
+// MarshalMapJSON marshals the tilemap to a JSON tilemap file.
+func (t *Tilemap) MarshalMapJSON() ([]byte, error) {
+	f, err := t.toFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(f)
+}
+
+// UnmarshalMapJSON unmarshals a JSON tilemap file.
+// The Defs field should be set beforehand for tile
+// definition references to be resolved.
+func (t *Tilemap) UnmarshalMapJSON(data []byte) error {
+	var f tilemapFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	return t.fromFile(f)
+}
+
+func (t *Tilemap) toFile() (tilemapFile, error) {
+	if t.chunks != nil {
+		return tilemapFile{}, ErrChunkedTilemap
+	}
+
+	f := tilemapFile{
+		Signature: TilemapSignature,
+		Version:   TilemapVersion,
+		TileWidth: t.TileWidth,
+		Layers:    t.Data,
+	}
+
+	for _, tile := range t.Defs.Tiles() {
+		def, _ := t.Defs.Get(tile)
+		if def.Name == "" {
+			continue
+		}
+
+		if f.Defs == nil {
+			f.Defs = make(map[int]string)
+		}
+		f.Defs[tile] = def.Name
+	}
+
+	return f, nil
+}
+
+func (t *Tilemap) fromFile(f tilemapFile) error {
+	if f.Signature != TilemapSignature {
+		return ErrInvalidTilemap
+	}
+
+	if f.Version < 1 || f.Version > TilemapVersion {
+		return InvalidTilemapVersion(f.Version)
+	}
+
+	if len(f.Layers[0]) == 0 || len(f.Layers[0]) != len(f.Layers[1]) {
+		return ErrInvalidTilemap
+	}
+
+	w := len(f.Layers[0][0])
+	for z := range f.Layers {
+		for _, row := range f.Layers[z] {
+			if len(row) != w {
+				return ErrInvalidTilemap
+			}
+		}
+	}
+
+	// map stored tile values to the values of the current registry
+	remap := make(map[int]int)
+	if t.Defs != nil {
+		for tile, name := range f.Defs {
+			v, ok := t.Defs.Lookup(name)
+			if !ok {
+				return UnknownTileDef(name)
+			}
+
+			if v != tile {
+				remap[tile] = v
+			}
+		}
+	}
+
+	if len(remap) > 0 {
+		for z := range f.Layers {
+			for _, row := range f.Layers[z] {
+				for x, tile := range row {
+					if v, ok := remap[tile]; ok {
+						row[x] = v
+					}
+				}
+			}
+		}
+	}
+
+	t.TileWidth = f.TileWidth
+	t.Data = f.Layers
+	t.bounds = image.Rect(0, 0, w, len(f.Layers[0]))
+	t.chunks = nil
+	t.cache = nil
+	t.MarkDirty(t.bounds)
+
+	return nil
+}
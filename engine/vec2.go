@@ -61,6 +61,15 @@ func (v Vec2) Translate(d float64, m float64) Vec2 {
 	}
 }
 
+// Rotate returns v rotated about the origin by d radians.
+func (v Vec2) Rotate(d float64) Vec2 {
+	sin, cos := math.Sincos(d)
+	return Vec2{
+		X: v.X*cos - v.Y*sin,
+		Y: v.X*sin + v.Y*cos,
+	}
+}
+
 // Scale multiplies vector v by a scalar value s
 // and returns the result.
 func (v Vec2) Scale(s float64) Vec2 {
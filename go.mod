@@ -4,6 +4,7 @@ go 1.16
 
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.0.2
+	github.com/jfreymuth/oggvorbis v1.0.1
 	github.com/pkg/errors v0.9.1
 	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5
 	gopkg.in/yaml.v2 v2.3.0
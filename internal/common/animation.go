@@ -1,7 +1,64 @@
 package common
 
+import "github.com/split-cube-studios/ardent/engine"
+
 // Animation holds extra info for an animated Asset.
+// As in assets built before sequences were added,
+// looping animations cycle from Start to End-1,
+// whereas other animations play from Start to End,
+// and each frame is shown for 60/Fps+1 ticks.
 type Animation struct {
 	Fps, Start, End uint16
 	Loop            bool
+
+	// Durations optionally sets the duration
+	// of each frame in ticks, from Start to End.
+	Durations []uint16
+	// Playback is an engine.Playback.
+	Playback byte
+	// Events maps frames, relative to Start, to event names.
+	Events map[uint16][]string
+}
+
+// Frames returns the number of frames of the Animation.
+func (a Animation) Frames() int {
+	if a.End <= a.Start {
+		return 1
+	}
+
+	if a.Loop {
+		return int(a.End - a.Start)
+	}
+
+	return int(a.End-a.Start) + 1
+}
+
+// Sequence returns the engine.AnimationSequence of the Animation.
+func (a Animation) Sequence() engine.AnimationSequence {
+	seq := engine.AnimationSequence{
+		Frames:   a.Frames(),
+		Fps:      int(a.Fps),
+		Loop:     a.Loop,
+		Playback: engine.Playback(a.Playback),
+	}
+
+	if len(a.Durations) > 0 || a.Fps > 0 {
+		seq.Durations = make([]float64, seq.Frames)
+		for i := range seq.Durations {
+			if i < len(a.Durations) && a.Durations[i] > 0 {
+				seq.Durations[i] = float64(a.Durations[i])
+			} else if a.Fps > 0 {
+				seq.Durations[i] = float64(60/a.Fps + 1)
+			}
+		}
+	}
+
+	if len(a.Events) > 0 {
+		seq.Events = make(map[int][]string, len(a.Events))
+		for frame, events := range a.Events {
+			seq.Events[int(frame)] = events
+		}
+	}
+
+	return seq
 }
@@ -43,6 +43,9 @@ const (
 type Asset struct {
 	Img      Image
 	AtlasMap map[string]AtlasRegion
+	// Pages are the images of an atlas after Img,
+	// when its regions do not fit in one image.
+	Pages []Image
 
 	AnimationMap map[string]Animation
 	AnimWidth    uint16
@@ -3,4 +3,15 @@ package common
 // AtlasRegion describes a region of an Atlas.
 type AtlasRegion struct {
 	X, Y, W, H uint16
+
+	// Page is the index of the image containing
+	// the region, where 0 is the Img of the Asset
+	// and the rest are its Pages.
+	Page uint16
+
+	// TrimX and TrimY are the offset of a trimmed region
+	// within its untrimmed source image, whose size is
+	// SourceW and SourceH. The size is zero if untrimmed.
+	TrimX, TrimY     uint16
+	SourceW, SourceH uint16
 }
@@ -6,69 +6,37 @@ import (
 	"image"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/split-cube-studios/ardent/engine"
 	"github.com/split-cube-studios/ardent/internal/common"
 )
 
 // Animation is an engine.Animation.
 type Animation struct {
 	Image
-	state string
+	*engine.AnimationPlayer
 
-	w, h                     uint16
-	fpsCounter, frameCounter uint16
+	w, h uint16
 
 	anims map[string]common.Animation
 	cache map[uint16]*ebiten.Image
-
-	paused bool
 }
 
 // SetState implements engine.Animation.
 func (a *Animation) SetState(state string) {
-	if a.state == state {
+	if a.State() == state {
 		return
 	}
 
-	a.state = state
-	a.Reset()
-}
-
-// SetTickCount implements engine.Animation.
-func (a *Animation) SetTickCount(count int) {
-	fps := a.anims[a.state].Fps
-
-	a.frameCounter = uint16(count) / (60 / fps)
-}
-
-// Play implements engine.Animation.
-func (a *Animation) Play() {
-	a.paused = false
+	a.SetSequence(state, a.anims[state].Sequence())
 }
 
-// Pause implements engine.Animation.
-func (a *Animation) Pause() {
-	a.paused = true
-}
-
-// Reset implements engine.Animation.
-func (a *Animation) Reset() {
-	a.fpsCounter, a.frameCounter = 0, 0
-}
-
-func (a *Animation) tick() {
-	if a.paused {
+// Crossfade implements engine.Animation.
+func (a *Animation) Crossfade(state string, ticks int) {
+	if a.State() == state {
 		return
 	}
 
-	fps := a.anims[a.state].Fps
-	if fps != 0 && a.fpsCounter == 60/fps {
-		a.frameCounter++
-		a.fpsCounter = 0
-
-		return
-	}
-
-	a.fpsCounter++
+	a.CrossfadeSequence(state, a.anims[state].Sequence(), ticks)
 }
 
 // Size implements engine.Image.
@@ -77,23 +45,37 @@ func (a *Animation) Size() (int, int) {
 }
 
 func (a *Animation) getFrame() *ebiten.Image {
-	anim, ok := a.anims[a.state]
+	return a.getFrameOffset(0)
+}
+
+// getFrameOffset returns the frame that would be shown
+// after advancing the animation clock by a number of ticks.
+func (a *Animation) getFrameOffset(ticks int) *ebiten.Image {
+	anim, ok := a.anims[a.State()]
 	if !ok {
 		return nil
 	}
 
-	var frameKey uint16
-	if !anim.Loop && a.frameCounter >= anim.End-anim.Start {
-		frameKey = anim.End
-	} else {
-		length := anim.End - anim.Start
-		if length > 0 {
-			frameKey = (a.frameCounter % length) + anim.Start
-		} else {
-			frameKey = anim.Start
-		}
+	return a.getFrameKey(anim.Start + uint16(a.FrameOffset(ticks)))
+}
+
+// getFadeFrame returns the frame of the state
+// being faded out, and its weight.
+func (a *Animation) getFadeFrame() (*ebiten.Image, float64) {
+	state, frame, weight := a.Fade()
+	if weight <= 0 {
+		return nil, 0
 	}
 
+	anim, ok := a.anims[state]
+	if !ok {
+		return nil, 0
+	}
+
+	return a.getFrameKey(anim.Start + uint16(frame)), weight
+}
+
+func (a *Animation) getFrameKey(frameKey uint16) *ebiten.Image {
 	frame, ok := a.cache[frameKey]
 	if ok {
 		return frame
@@ -59,8 +59,13 @@ func (a *Asset) UnmarshalBinary(data []byte) error {
 		}
 
 	case common.AssetTypeAtlas:
+		pages := []*ebiten.Image{ebiten.NewImageFromImage(ca.Img)}
+		for _, page := range ca.Pages {
+			pages = append(pages, ebiten.NewImageFromImage(page))
+		}
+
 		a.atlas = Atlas{
-			img:     ebiten.NewImageFromImage(ca.Img),
+			pages:   pages,
 			regions: ca.AtlasMap,
 			cache:   make(map[string]Image),
 		}
@@ -78,10 +83,11 @@ func (a *Asset) UnmarshalBinary(data []byte) error {
 				renderable:        true,
 				roundTranslations: true,
 			},
-			w:     ca.AnimWidth,
-			h:     ca.AnimHeight,
-			anims: ca.AnimationMap,
-			cache: make(map[uint16]*ebiten.Image),
+			AnimationPlayer: engine.NewAnimationPlayer(),
+			w:               ca.AnimWidth,
+			h:               ca.AnimHeight,
+			anims:           ca.AnimationMap,
+			cache:           make(map[uint16]*ebiten.Image),
 		}
 
 	case common.AssetTypeSound:
@@ -12,7 +12,7 @@ import (
 
 // Atlas is an engine.Atlas.
 type Atlas struct {
-	img     *ebiten.Image
+	pages   []*ebiten.Image
 	regions map[string]common.AtlasRegion
 	cache   map[string]Image
 }
@@ -20,7 +20,7 @@ type Atlas struct {
 // GetImage implements engine.Atlas.
 func (a *Atlas) GetImage(k string) engine.Image {
 	region, ok := a.regions[k]
-	if !ok {
+	if !ok || int(region.Page) >= len(a.pages) {
 		return nil
 	}
 
@@ -29,7 +29,7 @@ func (a *Atlas) GetImage(k string) engine.Image {
 		return &eImg
 	}
 
-	img := a.img.SubImage(
+	img := a.pages[region.Page].SubImage(
 		image.Rect(
 			int(region.X),
 			int(region.Y),
@@ -48,7 +48,12 @@ func (a *Atlas) GetImage(k string) engine.Image {
 		alpha:             1,
 		renderable:        true,
 		roundTranslations: true,
+		trimX:             float64(region.TrimX),
+		trimY:             float64(region.TrimY),
+		sourceW:           float64(region.SourceW),
+		sourceH:           float64(region.SourceH),
 	}
+	cacheImg.Origin(0, 0)
 	a.cache[k] = cacheImg
 
 	return &cacheImg
@@ -18,6 +18,11 @@ type Image struct {
 
 	originX, originY float64
 
+	// the offset and size of a trimmed atlas
+	// image within its untrimmed source image
+	trimX, trimY     float64
+	sourceW, sourceH float64
+
 	r, g, b float64
 	alpha   float64
 
@@ -50,7 +55,15 @@ func (i *Image) Rotate(d float64) {
 }
 
 // Origin sets the image origin by percent ranging from 0.0 to 1.0
+// The origin of a trimmed atlas image is relative to its
+// untrimmed source image, so that it is drawn in place.
 func (i *Image) Origin(x, y float64) {
+	if i.sourceW > 0 && i.sourceH > 0 {
+		w, h := i.img.Size()
+		x = (x*i.sourceW - i.trimX) / float64(w)
+		y = (y*i.sourceH - i.trimY) / float64(h)
+	}
+
 	i.originX, i.originY = x, y
 }
 
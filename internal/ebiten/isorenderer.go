@@ -18,6 +18,7 @@ type IsoRenderer struct {
 	drawQueue []*isoRendererImage
 
 	tilemap         *engine.Tilemap
+	tilemapRev      uint64
 	tileEventStates map[[3]int]tileEventState
 }
 
@@ -47,6 +48,50 @@ func NewIsoRenderer() *IsoRenderer {
 func (r *IsoRenderer) SetTilemap(tilemap *engine.Tilemap) {
 	r.tilemap = tilemap
 	r.tileEventStates = make(map[[3]int]tileEventState)
+
+	if tilemap != nil {
+		r.tilemapRev = tilemap.Revision()
+	}
+}
+
+// Tick implements engine.Renderer. Animated tile types
+// share a clock, which is advanced once per tick.
+func (r *IsoRenderer) Tick() {
+	r.Renderer.Tick()
+
+	if r.tilemap == nil {
+		return
+	}
+
+	ticked := make(map[*Animation]struct{})
+	for _, img := range r.tilemap.Mapper {
+		a, ok := img.(*Animation)
+		if !ok {
+			continue
+		}
+
+		if _, ok := ticked[a]; ok {
+			continue
+		}
+
+		a.Tick()
+		ticked[a] = struct{}{}
+	}
+}
+
+// refreshTiles discards the overlap event state
+// of tiles that have been edited since the last draw.
+func (r *IsoRenderer) refreshTiles() {
+	regions, rev := r.tilemap.DirtySince(r.tilemapRev)
+	r.tilemapRev = rev
+
+	for _, region := range regions {
+		for key := range r.tileEventStates {
+			if image.Pt(key[1], key[2]).In(region) {
+				delete(r.tileEventStates, key)
+			}
+		}
+	}
 }
 
 func (r *IsoRenderer) tilemapToIsoLayers(cx, cy float64) [][]*isoRendererImage {
@@ -54,17 +99,20 @@ func (r *IsoRenderer) tilemapToIsoLayers(cx, cy float64) [][]*isoRendererImage {
 		return make([][]*isoRendererImage, 1)
 	}
 
+	r.refreshTiles()
+
 	tw := r.tilemap.TileWidth
-	data := r.tilemap.Data
+	bounds := r.tilemap.Bounds()
 	mapper := r.tilemap.Mapper
+	phase := r.tilemap.Phase
+	fog := r.tilemap.Fog
 
-	layers := make([][]*isoRendererImage, len(data))
+	layers := make([][]*isoRendererImage, 2)
 
 	centerX, centerY := r.tilemap.IsoToIndex(
 		cx+float64(r.w/2),
 		cy+float64(r.h/2),
 	)
-	centerX, centerY = int(math.Max(float64(centerX), 0)), int(math.Max(float64(centerY), 0))
 
 	vdim := math.Max(
 		float64(r.w),
@@ -72,36 +120,67 @@ func (r *IsoRenderer) tilemapToIsoLayers(cx, cy float64) [][]*isoRendererImage {
 	) / (float64(tw) * 0.55)
 
 	jmin := int(math.Max(
-		math.Max(float64(centerX), 0)-vdim,
-		0,
+		float64(centerX)-vdim,
+		float64(bounds.Min.X),
 	))
 	kmin := int(math.Max(
-		math.Max(float64(centerY), 0)-vdim,
-		0,
+		float64(centerY)-vdim,
+		float64(bounds.Min.Y),
+	))
+	jmax := int(math.Min(
+		float64(bounds.Max.X),
+		vdim+float64(centerX),
+	))
+	kmax := int(math.Min(
+		float64(bounds.Max.Y),
+		vdim+float64(centerY),
 	))
 
-	for i := 0; i < len(data); i++ {
-		jmax := int(math.Min(
-			float64(len(data[i])),
-			vdim+float64(centerX),
-		))
-
+	for i := 0; i < len(layers); i++ {
 		for j := jmin; j < jmax; j++ {
-			kmax := int(math.Min(
-				float64(len(data[i][j])),
-				vdim+float64(centerY),
-			))
-
 			for k := kmin; k < kmax; k++ {
+				tint := 1.0
+				if fog != nil {
+					switch fog.State(j, k) {
+					case engine.FogUnexplored:
+						continue
+					case engine.FogSeen:
+						tint = fog.SeenTint
+					}
+				}
+
 				x, y := r.tilemap.IndexToIso(j, k)
 				x -= float64(tw / 2)
 				y -= float64(tw)
 
-				img := mapper[data[i][k][j]]
+				img := mapper[r.tilemap.GetTileValue(j, k, i)]
 				if img == nil {
 					continue
 				}
 
+				var eimg *ebiten.Image
+
+				switch a := img.(type) {
+				case *Image:
+					eimg = a.img
+
+				case *Animation:
+					var offset int
+					if phase != nil {
+						offset = phase(j, k, i)
+					}
+
+					eimg = a.getFrameOffset(offset)
+
+				default:
+					panic("Invalid tile image type")
+				}
+
+				// typically if an animation frame was not found
+				if eimg == nil {
+					continue
+				}
+
 				if i != 0 {
 					_, h := img.Size()
 					y -= float64(h - tw/4)
@@ -109,14 +188,14 @@ func (r *IsoRenderer) tilemapToIsoLayers(cx, cy float64) [][]*isoRendererImage {
 
 				layers[i] = append(layers[i], &isoRendererImage{
 					img: &Image{
-						img:        img.(*Image).img,
+						img:        eimg,
 						tx:         x,
 						ty:         y,
 						sx:         1,
 						sy:         1,
-						r:          1,
-						g:          1,
-						b:          1,
+						r:          tint,
+						g:          tint,
+						b:          tint,
 						alpha:      1,
 						renderable: true,
 					},
@@ -153,6 +232,11 @@ func (r *IsoRenderer) draw(screen *ebiten.Image) {
 	layers := r.tilemapToIsoLayers(cx, cy)
 	topTiles := layers[len(layers)-1]
 
+	var fog *engine.FogOfWar
+	if r.tilemap != nil && r.tilemap.Fog != nil && r.tilemap.Fog.HideImages {
+		fog = r.tilemap.Fog
+	}
+
 	r.partitionMap.Tick(
 		pos,
 		pcells,
@@ -163,7 +247,14 @@ func (r *IsoRenderer) draw(screen *ebiten.Image) {
 					continue
 				}
 
-				var tmpImage *isoRendererImage
+				if fog != nil {
+					p := r.tilemap.WorldToTile(img.Position())
+					if !fog.IsVisible(p.X, p.Y) {
+						continue
+					}
+				}
+
+				var tmpImage, fadeImage *isoRendererImage
 
 				switch a := img.(type) {
 				case *Image:
@@ -192,7 +283,6 @@ func (r *IsoRenderer) draw(screen *ebiten.Image) {
 					}
 
 				case *Animation:
-					a.tick()
 					w, h := a.Size()
 					tmpImage = &isoRendererImage{
 						img: &Image{
@@ -217,6 +307,14 @@ func (r *IsoRenderer) draw(screen *ebiten.Image) {
 						},
 					}
 
+					// the state being crossfaded from fades out on top
+					if fade, weight := a.getFadeFrame(); fade != nil {
+						fadeImg := *tmpImage.img
+						fadeImg.img = fade
+						fadeImg.alpha *= weight
+						fadeImage = &isoRendererImage{img: &fadeImg}
+					}
+
 				default:
 					panic("Invalid image type")
 				}
@@ -281,6 +379,9 @@ func (r *IsoRenderer) draw(screen *ebiten.Image) {
 				}
 
 				r.drawQueue = append(r.drawQueue, tmpImage)
+				if fadeImage != nil {
+					r.drawQueue = append(r.drawQueue, fadeImage)
+				}
 			}
 
 			r.drawQueue = append(r.drawQueue, topTiles...)
@@ -334,11 +435,12 @@ func (r *IsoRenderer) draw(screen *ebiten.Image) {
 				op := new(ebiten.DrawImageOptions)
 				w, h := img.Size()
 
-				op.GeoM.Scale(img.sx, img.sy)
+				// scale about the origin, so that negative scales mirror in place
 				op.GeoM.Translate(
 					-img.originX*float64(w),
 					-img.originY*float64(h),
 				)
+				op.GeoM.Scale(img.sx, img.sy)
 				op.GeoM.Rotate(img.d)
 				op.GeoM.Translate(
 					img.originX*float64(w),
@@ -37,6 +37,13 @@ func (r *Renderer) AddImage(images ...engine.Image) {
 	}
 }
 
+// RemoveImage removes images from the draw stack.
+func (r *Renderer) RemoveImage(images ...engine.Image) {
+	for _, img := range images {
+		r.partitionMap.Remove(img)
+	}
+}
+
 // SetCamera implements engine.Renderer.
 func (r *Renderer) SetCamera(camera *engine.Camera) {
 	r.camera = camera
@@ -66,29 +73,15 @@ func (r *Renderer) ScreenToWorld(screen engine.Vec2) engine.Vec2 {
 	}
 }
 
-// Tick implements engine.Renderer.
+// Tick implements engine.Renderer. The clock of every
+// animation in the draw stack is advanced, whether or not
+// it is drawn, so that animation callbacks run once per tick.
 func (r *Renderer) Tick() {
-	/*
-		var i int
-		for _, img := range r.imgs {
-			if img.IsDisposed() {
-				continue
-			}
-
-			anim, ok := img.(*Animation)
-			if ok {
-				anim.tick()
-			}
-
-			r.imgs[i] = img
-			i++
+	for _, entry := range r.partitionMap.Entries() {
+		if a, ok := entry.(*Animation); ok && !a.IsDisposed() {
+			a.Tick()
 		}
-
-		for j := i; j < len(r.imgs); j++ {
-			r.imgs[j] = nil
-		}
-		r.imgs = r.imgs[:i]
-	*/
+	}
 }
 
 // draw renders all images in the draw stack.
@@ -103,6 +96,8 @@ func (r *Renderer) draw(screen *ebiten.Image) {
 		cx, cy           float64
 		red, green, blue float64
 		alpha            float64
+		fade             *ebiten.Image
+		weight           float64
 	)
 
 	if r.camera != nil {
@@ -130,6 +125,8 @@ func (r *Renderer) draw(screen *ebiten.Image) {
 					return
 				}
 
+				fade = nil
+
 				switch a := img.(type) {
 				case *Image:
 					eimg = a.img
@@ -142,8 +139,8 @@ func (r *Renderer) draw(screen *ebiten.Image) {
 					alpha = a.alpha
 
 				case *Animation:
-					a.tick()
 					eimg = a.getFrame()
+					fade, weight = a.getFadeFrame()
 					tx, ty = a.tx+a.ox, a.ty+a.oy
 					ox, oy = a.ox, a.oy
 					sx, sy = a.sx, a.sy
@@ -165,11 +162,12 @@ func (r *Renderer) draw(screen *ebiten.Image) {
 
 				w, h := eimg.Size()
 
-				op.GeoM.Scale(sx, sy)
+				// scale about the origin, so that negative scales mirror in place
 				op.GeoM.Translate(
 					-originX*float64(w),
 					-originY*float64(h),
 				)
+				op.GeoM.Scale(sx, sy)
 				op.GeoM.Rotate(d)
 				x, y := tx+ox, ty+oy
 				op.GeoM.Translate(
@@ -180,6 +178,14 @@ func (r *Renderer) draw(screen *ebiten.Image) {
 				op.ColorM.Scale(red, green, blue, alpha)
 
 				screen.DrawImage(eimg, op)
+
+				// the state being crossfaded from fades out on top
+				if fade != nil {
+					op.ColorM.Reset()
+					op.ColorM.Scale(red, green, blue, alpha*weight)
+
+					screen.DrawImage(fade, op)
+				}
 			}
 		})
 }
@@ -2,22 +2,35 @@
 
 package headless
 
+import "github.com/split-cube-studios/ardent/engine"
+
 // Animation is a headless engine.Animation.
+// It has no frames, so only its state and tick count are tracked.
 type Animation struct {
 	Image
+	*engine.AnimationPlayer
 }
 
-// SetState implements engine.Animation.
-func (a Animation) SetState(state string) {}
+func newAnimation() *Animation {
+	return &Animation{
+		AnimationPlayer: engine.NewAnimationPlayer(),
+	}
+}
 
-// SetTickCount implements engine.Animation.
-func (a Animation) SetTickCount(count int) {}
+// SetState implements engine.Animation.
+func (a *Animation) SetState(state string) {
+	if a.State() == state {
+		return
+	}
 
-// Play implements engine.Animation.
-func (a Animation) Play() {}
+	a.SetSequence(state, engine.AnimationSequence{})
+}
 
-// Pause implements engine.Animation.
-func (a Animation) Pause() {}
+// Crossfade implements engine.Animation.
+func (a *Animation) Crossfade(state string, ticks int) {
+	if a.State() == state {
+		return
+	}
 
-// Reset implements engine.Animation.
-func (a Animation) Reset() {}
+	a.CrossfadeSequence(state, engine.AnimationSequence{}, ticks)
+}
@@ -21,7 +21,7 @@ func (a Asset) ToAtlas() engine.Atlas {
 
 // ToAnimation implements the ToAnimation method of engine.Asset.
 func (a Asset) ToAnimation() engine.Animation {
-	return new(Animation)
+	return newAnimation()
 }
 
 // ToSound implements the ToSound method of engine.Asset.
@@ -37,7 +37,7 @@ func (c component) NewAtlasFromAssetPath(path string) (engine.Atlas, error) {
 }
 
 func (c component) NewAnimationFromAssetPath(path string) (engine.Animation, error) {
-	return new(Animation), nil
+	return newAnimation(), nil
 }
 
 func (c component) NewSoundFromAssetPath(path string) (engine.Sound, error) {
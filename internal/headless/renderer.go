@@ -16,6 +16,11 @@ func (r Renderer) AddImage(images ...engine.Image) {
 	// NOOP
 }
 
+// RemoveImage removes images from the draw stack.
+func (r Renderer) RemoveImage(images ...engine.Image) {
+	// NOOP
+}
+
 // SetCamera implements engine.Renderer.
 func (r Renderer) SetCamera(camera *engine.Camera) {
 }
@@ -23,14 +23,22 @@ func NewBasicPath(width, floorTile, wallTile int) *BasicPath {
 }
 
 func (bp *BasicPath) Flood(tmap *engine.Tilemap) []image.Point {
+	return bp.FloodRand(tmap, nil)
+}
+
+// FloodRand implements SeededPath.
+func (bp *BasicPath) FloodRand(tmap *engine.Tilemap, r *rand.Rand) []image.Point {
 
 	var stack []image.Point
 
+	// a BasicPath may be reused for several tilemaps
+	bp.coords, bp.points = nil, nil
+
 	// select random starting position
 	for {
 		pt := image.Pt(
-			rand.Intn(len(tmap.Data[0][0])/bp.width)*bp.width,
-			rand.Intn(len(tmap.Data[0])/bp.width)*bp.width,
+			intn(r, len(tmap.Data[0][0])/bp.width)*bp.width,
+			intn(r, len(tmap.Data[0])/bp.width)*bp.width,
 		)
 		// TODO check has valid neighbors?
 		if tmap.ContainsAll(bp.wallTile, pt, 1, bp.width) {
@@ -57,7 +65,7 @@ func (bp *BasicPath) Flood(tmap *engine.Tilemap) []image.Point {
 			stack = stack[:len(stack)-1]
 			continue
 		}
-		stack = append(stack, ns[rand.Intn(len(ns))])
+		stack = append(stack, ns[intn(r, len(ns))])
 	}
 
 	return bp.points
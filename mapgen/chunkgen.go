@@ -0,0 +1,49 @@
+package mapgen
+
+import (
+	"image"
+	"math/rand"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+// ChunkGenerator is an engine.ChunkProvider that generates
+// chunks on demand. Each chunk is generated independently
+// with a Generator, using a seed derived from the chunk
+// coordinates so that a chunk is identical every time it is loaded.
+// The global math/rand source is not used.
+type ChunkGenerator struct {
+	options GeneratorOptions
+	bounds  image.Rectangle
+	seed    int64
+}
+
+// NewChunkGenerator returns an instantiated *ChunkGenerator.
+// The Width and Height of the options are replaced by the chunk size.
+func NewChunkGenerator(options GeneratorOptions, bounds image.Rectangle, seed int64) *ChunkGenerator {
+	return &ChunkGenerator{
+		options: options,
+		bounds:  bounds,
+		seed:    seed,
+	}
+}
+
+// Bounds implements engine.ChunkProvider.
+func (cg *ChunkGenerator) Bounds() image.Rectangle {
+	return cg.bounds
+}
+
+// LoadChunk implements engine.ChunkProvider.
+func (cg *ChunkGenerator) LoadChunk(cx, cy, size int) (*engine.Chunk, error) {
+
+	options := cg.options
+	options.Width, options.Height = size, size
+	options.Rand = rand.New(rand.NewSource(cg.seed ^ int64(cx)*73856093 ^ int64(cy)*19349663))
+
+	tmap, err := NewGenerator(options).Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &engine.Chunk{Data: tmap.Data}, nil
+}
@@ -43,6 +43,11 @@ type GeneratorOptions struct {
 	Mapper map[int]engine.Image
 
 	OverlapEvent engine.TileOverlapEvent
+
+	// Rand is an optional source of randomness,
+	// for deterministic generation. It is passed
+	// to a PathAlg that implements SeededPath.
+	Rand *rand.Rand
 }
 
 // NewGenerator returns an instantiated *Generator
@@ -128,7 +133,14 @@ func (g *Generator) Generate() (*engine.Tilemap, error) {
 
 		// create set of exits for hallways
 		exits := make(map[image.Point]struct{})
-		for _, pt := range g.PathAlg.Flood(tmap) {
+		var points []image.Point
+		if sp, ok := g.PathAlg.(SeededPath); ok {
+			points = sp.FloodRand(tmap, g.Rand)
+		} else {
+			points = g.PathAlg.Flood(tmap)
+		}
+
+		for _, pt := range points {
 			exits[pt] = struct{}{}
 		}
 
@@ -164,15 +176,11 @@ tries:
 		} else {
 			// snap rooms to alignment
 			if g.RoomAlign > 1 {
-				x = rand.Intn(
-					(g.Width-bounds.Dx())/g.RoomAlign,
-				) * g.RoomAlign
-				y = rand.Intn(
-					(g.Height-bounds.Dy())/g.RoomAlign,
-				) * g.RoomAlign
+				x = intn(g.Rand, (g.Width-bounds.Dx())/g.RoomAlign) * g.RoomAlign
+				y = intn(g.Rand, (g.Height-bounds.Dy())/g.RoomAlign) * g.RoomAlign
 			} else {
-				x = rand.Intn(g.Width - bounds.Dx())
-				y = rand.Intn(g.Height - bounds.Dy())
+				x = intn(g.Rand, g.Width-bounds.Dx())
+				y = intn(g.Rand, g.Height-bounds.Dy())
 			}
 		}
 
@@ -305,3 +313,13 @@ func (g *Generator) placeHallways(tmap *engine.Tilemap, exits map[image.Point]st
 
 	return nil
 }
+
+// intn returns a random int in [0, n) from
+// r, or from the global source if r is nil.
+func intn(r *rand.Rand, n int) int {
+	if r != nil {
+		return r.Intn(n)
+	}
+
+	return rand.Intn(n)
+}
@@ -2,6 +2,7 @@ package mapgen
 
 import (
 	"image"
+	"math/rand"
 
 	"github.com/split-cube-studios/ardent/engine"
 )
@@ -21,3 +22,13 @@ type Path interface {
 	// to the tilemap after rooms and hallways have been placed.
 	PostProcess(*engine.Tilemap)
 }
+
+// SeededPath is a Path that floods a tilemap using
+// a given source of randomness. The Generator calls
+// FloodRand in place of Flood, with its Rand, which
+// is nil if the global source should be used.
+type SeededPath interface {
+	Path
+
+	FloodRand(*engine.Tilemap, *rand.Rand) []image.Point
+}
@@ -0,0 +1,291 @@
+// Package prefab instantiates entities from data defined prefabs.
+//
+// Prefabs are defined in YAML configs of type prefab:
+//
+//	version: 1.0
+//	type: prefab
+//	prefabs:
+//	  enemy:
+//	    class: enemy
+//	    animation:
+//	      asset: goblin.asset
+//	      state: sw
+//	    hitshapes:
+//	      - name: hurtbox
+//	        shape: {circle: 8}
+//	        layer: [enemy]
+//	    steering:
+//	      resolution: 16
+//	      approach: 300
+//	      avoid: 50
+//	      behaviors:
+//	        - {name: wander, params: {weight: 0.2, jitter: 0.1}}
+//	    props: {hp: 10}
+//	  archer:
+//	    extends: enemy
+//	    props: {range: 200}
+package prefab
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+// ConfigType is the config type of prefab configs.
+const ConfigType = "prefab"
+
+var (
+	// ErrInheritanceCycle occurs when a prefab extends itself.
+	ErrInheritanceCycle = errors.New("prefab inheritance cycle")
+
+	// ErrInvalidShape occurs when a shape does not
+	// define exactly one of circle, rect or polygon.
+	ErrInvalidShape = errors.New("invalid prefab shape")
+
+	// ErrNoFSM occurs when a prefab defines a state,
+	// but the Factory has no NewFSM to enter it in.
+	ErrNoFSM = errors.New("prefab state without an FSM")
+)
+
+// UnknownPrefab occurs when a prefab is not defined.
+type UnknownPrefab string
+
+// Error implements error.
+func (u UnknownPrefab) Error() string {
+	return fmt.Sprintf("unknown prefab: %s", string(u))
+}
+
+// UnknownLayer occurs when a layer name is not defined.
+type UnknownLayer string
+
+// Error implements error.
+func (u UnknownLayer) Error() string {
+	return fmt.Sprintf("unknown layer: %s", string(u))
+}
+
+// InvalidConfigType occurs when a config is not of ConfigType.
+type InvalidConfigType string
+
+// Error implements error.
+func (i InvalidConfigType) Error() string {
+	return fmt.Sprintf("invalid config type: %s", string(i))
+}
+
+// Def defines a prefab. Unset fields are inherited from the
+// prefab it extends, and props are merged by key.
+type Def struct {
+	// Extends is the name of the prefab to inherit from.
+	Extends string `yaml:"extends,omitempty"`
+	// Class is the class of instantiated entities.
+	Class string `yaml:"class,omitempty"`
+
+	// Image is the asset path of an image.
+	Image string `yaml:"image,omitempty"`
+	// Animation is the animation of the entity.
+	Animation *AnimationDef `yaml:"animation,omitempty"`
+	// Origin is the origin of the image or animation.
+	Origin []float64 `yaml:"origin,omitempty"`
+
+	// State is the initial FSM state of the entity.
+	State string `yaml:"state,omitempty"`
+
+	HitShapes []HitShapeDef `yaml:"hitshapes,omitempty"`
+	Body      *BodyDef      `yaml:"body,omitempty"`
+	Steering  *SteeringDef  `yaml:"steering,omitempty"`
+
+	// Props are arbitrary properties of the entity.
+	Props map[string]interface{} `yaml:"props,omitempty"`
+}
+
+// AnimationDef defines the animation of a prefab.
+type AnimationDef struct {
+	// Asset is the asset path of the animation.
+	Asset string `yaml:"asset,omitempty"`
+	// State is the initial animation state.
+	State string `yaml:"state,omitempty"`
+}
+
+// ShapeDef defines a collision shape.
+// Exactly one of Circle, Rect or Polygon must be set.
+type ShapeDef struct {
+	// Circle is the radius of a circle.
+	Circle float64 `yaml:"circle,omitempty"`
+	// Offset is the center of a circle.
+	Offset []float64 `yaml:"offset,omitempty"`
+	// Rect is the min x, min y, max x and max y of a box.
+	Rect []float64 `yaml:"rect,omitempty"`
+	// Polygon is the points of a convex polygon.
+	Polygon [][]float64 `yaml:"polygon,omitempty"`
+}
+
+// HitShapeDef defines an engine.HitShape.
+// Layers are referenced by name.
+type HitShapeDef struct {
+	Name  string   `yaml:"name"`
+	Shape ShapeDef `yaml:"shape"`
+	Layer []string `yaml:"layer,omitempty"`
+	Mask  []string `yaml:"mask,omitempty"`
+}
+
+// BodyDef defines an engine.Body.
+// Layers are referenced by name.
+type BodyDef struct {
+	Shape ShapeDef `yaml:"shape"`
+	// Type is one of kinematic, static or trigger.
+	Type  string   `yaml:"type,omitempty"`
+	Layer []string `yaml:"layer,omitempty"`
+	Mask  []string `yaml:"mask,omitempty"`
+}
+
+// SteeringDef defines an engine.ContextMap.
+// Behaviors with a distance of zero are disabled.
+type SteeringDef struct {
+	Resolution int     `yaml:"resolution,omitempty"`
+	Approach   float64 `yaml:"approach,omitempty"`
+	Avoid      float64 `yaml:"avoid,omitempty"`
+	Walls      float64 `yaml:"walls,omitempty"`
+
+	// Behaviors are additional behaviors registered
+	// with engine.RegisterSteeringBehavior.
+	Behaviors []SteeringBehaviorDef `yaml:"behaviors,omitempty"`
+}
+
+// SteeringBehaviorDef defines a registered engine.SteeringBehavior.
+type SteeringBehaviorDef struct {
+	Name   string             `yaml:"name"`
+	Params map[string]float64 `yaml:"params,omitempty"`
+}
+
+// merge returns d with every set field of top applied.
+func (d Def) merge(top Def) Def {
+	if top.Class != "" {
+		d.Class = top.Class
+	}
+
+	if top.Image != "" {
+		d.Image = top.Image
+	}
+
+	if top.Animation != nil {
+		anim := AnimationDef{}
+		if d.Animation != nil {
+			anim = *d.Animation
+		}
+
+		if top.Animation.Asset != "" {
+			anim.Asset = top.Animation.Asset
+		}
+
+		if top.Animation.State != "" {
+			anim.State = top.Animation.State
+		}
+
+		d.Animation = &anim
+	}
+
+	if top.Origin != nil {
+		d.Origin = top.Origin
+	}
+
+	if top.State != "" {
+		d.State = top.State
+	}
+
+	if top.HitShapes != nil {
+		d.HitShapes = top.HitShapes
+	}
+
+	if top.Body != nil {
+		d.Body = top.Body
+	}
+
+	if top.Steering != nil {
+		d.Steering = top.Steering
+	}
+
+	if top.Props != nil {
+		props := make(map[string]interface{}, len(d.Props)+len(top.Props))
+		for k, v := range d.Props {
+			props[k] = v
+		}
+		for k, v := range top.Props {
+			props[k] = v
+		}
+
+		d.Props = props
+	}
+
+	d.Extends = ""
+
+	return d
+}
+
+// toBody returns a body of the shape and type of b, without layers.
+func (b BodyDef) toBody() (*engine.Body, error) {
+	shape, err := b.Shape.toShape()
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+
+	bodyType, ok := bodyTypes[b.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid body type: %s", b.Type)
+	}
+
+	return engine.NewBody(shape, bodyType), nil
+}
+
+func (s ShapeDef) toShape() (engine.Shape, error) {
+	var (
+		shape engine.Shape
+		n     int
+	)
+
+	if s.Circle > 0 {
+		c := engine.Circle{Radius: s.Circle}
+		if len(s.Offset) == 2 {
+			c.Offset = engine.Vec2{X: s.Offset[0], Y: s.Offset[1]}
+		}
+
+		shape = c
+		n++
+	}
+
+	if s.Rect != nil {
+		if len(s.Rect) != 4 {
+			return nil, ErrInvalidShape
+		}
+
+		shape = engine.AABB{
+			Min: engine.Vec2{X: s.Rect[0], Y: s.Rect[1]},
+			Max: engine.Vec2{X: s.Rect[2], Y: s.Rect[3]},
+		}
+		n++
+	}
+
+	if s.Polygon != nil {
+		p := engine.Polygon{}
+		for _, point := range s.Polygon {
+			if len(point) != 2 {
+				return nil, ErrInvalidShape
+			}
+
+			p.Points = append(p.Points, engine.Vec2{X: point[0], Y: point[1]})
+		}
+
+		if len(p.Points) < 3 {
+			return nil, ErrInvalidShape
+		}
+
+		shape = p
+		n++
+	}
+
+	if n != 1 {
+		return nil, ErrInvalidShape
+	}
+
+	return shape, nil
+}
@@ -0,0 +1,72 @@
+package prefab
+
+import "github.com/split-cube-studios/ardent/engine"
+
+// Entity is an engine.Entity instantiated from a prefab.
+type Entity struct {
+	engine.CoreEntity
+
+	// Prefab is the name of the prefab the entity was created from.
+	Prefab string
+	// Def is the resolved definition of the entity,
+	// including inherited fields and overrides.
+	Def Def
+
+	// Animation is the animation of the entity, if any.
+	Animation engine.Animation
+	// ContextMap steers the entity, if the prefab defines steering.
+	ContextMap *engine.ContextMap
+
+	// FSM is the state machine of the entity, built by the
+	// Factory's NewFSM and entered in the prefab's state.
+	FSM *engine.FSM
+	// Props are the properties of the entity,
+	// copied from the prefab.
+	Props map[string]interface{}
+
+	// OnHit is called with the hit events of the entity.
+	OnHit func(engine.HitEvent)
+
+	hitShapes []engine.HitShape
+}
+
+// Class implements engine.Entity.
+func (e *Entity) Class() string {
+	return e.Def.Class
+}
+
+// HitShapes implements engine.HitShaper.
+func (e *Entity) HitShapes() []engine.HitShape {
+	return e.hitShapes
+}
+
+// HitEvent implements engine.HitShaper.
+func (e *Entity) HitEvent(event engine.HitEvent) {
+	if e.OnHit != nil {
+		e.OnHit(event)
+	}
+}
+
+// Prop returns a property of the entity.
+func (e *Entity) Prop(key string) (interface{}, bool) {
+	v, ok := e.Props[key]
+	return v, ok
+}
+
+// PropFloat returns a numeric property of the entity as a float64.
+func (e *Entity) PropFloat(key string) (float64, bool) {
+	switch v := e.Props[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// PropString returns a string property of the entity.
+func (e *Entity) PropString(key string) (string, bool) {
+	v, ok := e.Props[key].(string)
+	return v, ok
+}
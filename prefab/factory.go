@@ -0,0 +1,350 @@
+package prefab
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+
+	"github.com/split-cube-studios/ardent/engine"
+	"gopkg.in/yaml.v2"
+)
+
+var bodyTypes = map[string]engine.BodyType{
+	"":          engine.BodyKinematic,
+	"kinematic": engine.BodyKinematic,
+	"static":    engine.BodyStatic,
+	"trigger":   engine.BodyTrigger,
+}
+
+type config struct {
+	Version string         `yaml:"version"`
+	Type    string         `yaml:"type"`
+	Prefabs map[string]Def `yaml:"prefabs"`
+}
+
+// Factory instantiates entities from prefabs.
+type Factory struct {
+	// Layers maps layer names used by prefabs to layer bits.
+	Layers map[string]uint32
+	// Tilemap is used by the ContextMaps of steering prefabs.
+	Tilemap *engine.Tilemap
+	// NewFSM optionally builds the FSM of each entity,
+	// after its images, shapes, body and steering.
+	// The FSM is entered in the state of the prefab.
+	NewFSM func(*Entity) *engine.FSM
+
+	component engine.ImageComponent
+	defs      map[string]Def
+}
+
+// NewFactory returns an instantiated *Factory. Images and
+// animations are created through the given component.
+func NewFactory(component engine.ImageComponent) *Factory {
+	return &Factory{
+		Layers:    make(map[string]uint32),
+		component: component,
+		defs:      make(map[string]Def),
+	}
+}
+
+// Load loads every prefab from a config file.
+func (f *Factory) Load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Parse(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Parse loads every prefab from config data.
+func (f *Factory) Parse(data []byte) error {
+	var conf config
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return err
+	}
+
+	if conf.Type != ConfigType {
+		return InvalidConfigType(conf.Type)
+	}
+
+	for name, def := range conf.Prefabs {
+		f.Register(name, def)
+	}
+
+	return nil
+}
+
+// Register defines a prefab, replacing any existing prefab of the same name.
+func (f *Factory) Register(name string, def Def) {
+	f.defs[name] = def
+}
+
+// Resolve returns the definition of a prefab,
+// with all inherited fields applied.
+func (f *Factory) Resolve(name string) (Def, error) {
+	return f.resolve(name, make(map[string]bool))
+}
+
+func (f *Factory) resolve(name string, visited map[string]bool) (Def, error) {
+	def, ok := f.defs[name]
+	if !ok {
+		return Def{}, UnknownPrefab(name)
+	}
+
+	if visited[name] {
+		return Def{}, ErrInheritanceCycle
+	}
+	visited[name] = true
+
+	if def.Extends == "" {
+		return Def{}.merge(def), nil
+	}
+
+	base, err := f.resolve(def.Extends, visited)
+	if err != nil {
+		return Def{}, err
+	}
+
+	return base.merge(def), nil
+}
+
+// Validate checks that a prefab resolves, and that its shapes and
+// body type are valid. Images, layers, steering behaviors and states
+// are defined by a game at runtime, so they are not checked.
+func (f *Factory) Validate(name string) error {
+	def, err := f.Resolve(name)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range def.HitShapes {
+		if _, err := h.Shape.toShape(); err != nil {
+			return fmt.Errorf("hit shape %s: %w", h.Name, err)
+		}
+	}
+
+	if def.Body != nil {
+		if _, err := def.Body.toBody(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// New instantiates a prefab at a position. Overrides are applied
+// in order on top of the prefab, as if extending it.
+func (f *Factory) New(name string, pos engine.Vec2, overrides ...Def) (*Entity, error) {
+	def, err := f.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range overrides {
+		def = def.merge(o)
+	}
+
+	e := &Entity{
+		Prefab: name,
+		Def:    def,
+		Props:  make(map[string]interface{}, len(def.Props)),
+	}
+	e.Vec2 = pos
+
+	for k, v := range def.Props {
+		e.Props[k] = v
+	}
+
+	if err := f.addImages(e, def); err != nil {
+		return nil, err
+	}
+
+	for _, h := range def.HitShapes {
+		shape, err := h.Shape.toShape()
+		if err != nil {
+			return nil, fmt.Errorf("hit shape %s: %w", h.Name, err)
+		}
+
+		layer, err := f.layers(h.Layer)
+		if err != nil {
+			return nil, err
+		}
+
+		mask, err := f.layers(h.Mask)
+		if err != nil {
+			return nil, err
+		}
+
+		e.hitShapes = append(e.hitShapes, engine.HitShape{
+			Name:  h.Name,
+			Shape: shape,
+			Layer: layer,
+			Mask:  mask,
+		})
+	}
+
+	if def.Body != nil {
+		body, err := f.newBody(*def.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		e.SetBody(body)
+	}
+
+	if s := def.Steering; s != nil {
+		if e.ContextMap, err = f.newContextMap(*s); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := f.addFSM(e, def); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (f *Factory) addFSM(e *Entity, def Def) error {
+	if f.NewFSM != nil {
+		e.FSM = f.NewFSM(e)
+	}
+
+	if def.State == "" {
+		return nil
+	}
+
+	if e.FSM == nil {
+		return fmt.Errorf("state %s: %w", def.State, ErrNoFSM)
+	}
+
+	if e.FSM.Animation == nil && e.Animation != nil {
+		e.FSM.Animation = e.Animation
+	}
+
+	return e.FSM.SetState(def.State)
+}
+
+func (f *Factory) addImages(e *Entity, def Def) error {
+	var img engine.Image
+
+	switch {
+	case def.Animation != nil && def.Animation.Asset != "":
+		anim, err := f.component.NewAnimationFromAssetPath(def.Animation.Asset)
+		if err != nil {
+			return err
+		}
+
+		if def.Animation.State != "" {
+			anim.SetState(def.Animation.State)
+		}
+
+		e.Animation = anim
+		img = anim
+
+	case def.Image != "":
+		var err error
+		img, err = f.component.NewImageFromAssetPath(def.Image)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return nil
+	}
+
+	if len(def.Origin) == 2 {
+		img.Origin(def.Origin[0], def.Origin[1])
+	}
+
+	e.AddImage(img)
+
+	return nil
+}
+
+func (f *Factory) newBody(def BodyDef) (*engine.Body, error) {
+	body, err := def.toBody()
+	if err != nil {
+		return nil, err
+	}
+
+	if def.Layer != nil {
+		if body.Layer, err = f.layers(def.Layer); err != nil {
+			return nil, err
+		}
+	}
+
+	if def.Mask != nil {
+		if body.Mask, err = f.layers(def.Mask); err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+func (f *Factory) newContextMap(def SteeringDef) (*engine.ContextMap, error) {
+	behavior := func(dist float64) *engine.ContextMapBehavior {
+		if dist <= 0 {
+			return nil
+		}
+
+		return &engine.ContextMapBehavior{Distance: dist}
+	}
+
+	resolution := def.Resolution
+	if resolution <= 0 {
+		resolution = 16
+	}
+
+	walls := behavior(def.Walls)
+	if f.Tilemap == nil {
+		walls = nil
+	}
+
+	cm := engine.NewContextMap(
+		resolution,
+		f.Tilemap,
+		behavior(def.Approach),
+		behavior(def.Avoid),
+		walls,
+	)
+
+	for _, b := range def.Behaviors {
+		sb, err := engine.NewSteeringBehavior(b.Name, b.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		cm.AddBehavior(sb)
+	}
+
+	return cm, nil
+}
+
+// layers returns the union of named layers.
+// The name "all" includes every layer.
+func (f *Factory) layers(names []string) (uint32, error) {
+	var l uint32
+
+	for _, name := range names {
+		if name == "all" {
+			l |= math.MaxUint32
+			continue
+		}
+
+		v, ok := f.Layers[name]
+		if !ok {
+			return 0, UnknownLayer(name)
+		}
+
+		l |= v
+	}
+
+	return l, nil
+}
@@ -0,0 +1,184 @@
+package prefab
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+const testConfig = `
+version: 1.0
+type: prefab
+prefabs:
+  enemy:
+    class: enemy
+    state: idle
+    hitshapes:
+      - name: hurtbox
+        shape: {circle: 8}
+        layer: [enemy]
+        mask: [player]
+    body:
+      shape: {rect: [-4, -4, 4, 4]}
+      layer: [enemy]
+    steering:
+      resolution: 8
+      approach: 300
+      avoid: 50
+      behaviors:
+        - {name: orbit, params: {radius: 20, distance: 100}}
+    props: {hp: 10, name: goblin}
+  archer:
+    extends: enemy
+    state: patrol
+    props: {range: 200}
+`
+
+func newTestFactory(t *testing.T) *Factory {
+	f := NewFactory(nil)
+	f.Layers["enemy"] = 1
+	f.Layers["player"] = 2
+	f.NewFSM = func(e *Entity) *engine.FSM {
+		fsm := engine.NewFSM()
+		fsm.AddState("idle", "")
+		fsm.AddState("patrol", "")
+		fsm.AddState("sleep", "")
+
+		return fsm
+	}
+
+	if err := f.Parse([]byte(testConfig)); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func TestInheritance(t *testing.T) {
+	f := newTestFactory(t)
+
+	e, err := f.New("archer", engine.Vec2{X: 10, Y: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Class() != "enemy" {
+		t.Fatalf("Expected %v got %v", "enemy", e.Class())
+	}
+
+	if e.FSM.Current() != "patrol" {
+		t.Fatalf("Expected %v got %v", "patrol", e.FSM.Current())
+	}
+
+	if v, _ := e.PropFloat("hp"); v != 10 {
+		t.Fatalf("Expected %v got %v", 10, v)
+	}
+
+	if v, _ := e.PropFloat("range"); v != 200 {
+		t.Fatalf("Expected %v got %v", 200, v)
+	}
+
+	if e.Position() != (engine.Vec2{X: 10, Y: 20}) {
+		t.Fatalf("Expected %v got %v", engine.Vec2{X: 10, Y: 20}, e.Position())
+	}
+
+	shapes := e.HitShapes()
+	if len(shapes) != 1 || shapes[0].Layer != 1 || shapes[0].Mask != 2 {
+		t.Fatalf("Unexpected hit shapes %v", shapes)
+	}
+
+	if c, ok := shapes[0].Shape.(engine.Circle); !ok || c.Radius != 8 {
+		t.Fatalf("Expected circle got %v", shapes[0].Shape)
+	}
+
+	if e.Body() == nil || e.Body().Layer != 1 {
+		t.Fatalf("Unexpected body %v", e.Body())
+	}
+
+	if e.ContextMap == nil {
+		t.Fatal("Expected context map")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	f := newTestFactory(t)
+
+	e, err := f.New("enemy", engine.Vec2{}, Def{
+		State: "sleep",
+		Props: map[string]interface{}{"hp": 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.FSM.Current() != "sleep" {
+		t.Fatalf("Expected %v got %v", "sleep", e.FSM.Current())
+	}
+
+	if v, _ := e.PropFloat("hp"); v != 20 {
+		t.Fatalf("Expected %v got %v", 20, v)
+	}
+
+	if v, _ := e.PropString("name"); v != "goblin" {
+		t.Fatalf("Expected %v got %v", "goblin", v)
+	}
+
+	// overrides must not leak into the prefab
+	e, _ = f.New("enemy", engine.Vec2{})
+	if v, _ := e.PropFloat("hp"); v != 10 {
+		t.Fatalf("Expected %v got %v", 10, v)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	f := newTestFactory(t)
+
+	if _, err := f.New("dragon", engine.Vec2{}); !errors.Is(err, UnknownPrefab("dragon")) {
+		t.Fatalf("Expected %v got %v", UnknownPrefab("dragon"), err)
+	}
+
+	f.Register("a", Def{Extends: "b"})
+	f.Register("b", Def{Extends: "a"})
+
+	if _, err := f.Resolve("a"); !errors.Is(err, ErrInheritanceCycle) {
+		t.Fatalf("Expected %v got %v", ErrInheritanceCycle, err)
+	}
+
+	f.Register("boss", Def{Extends: "enemy", Body: &BodyDef{
+		Shape: ShapeDef{Circle: 4},
+		Layer: []string{"boss"},
+	}})
+
+	if _, err := f.New("boss", engine.Vec2{}); !errors.Is(err, UnknownLayer("boss")) {
+		t.Fatalf("Expected %v got %v", UnknownLayer("boss"), err)
+	}
+
+	f.Register("blob", Def{HitShapes: []HitShapeDef{{Name: "blob"}}})
+
+	if _, err := f.New("blob", engine.Vec2{}); !errors.Is(err, ErrInvalidShape) {
+		t.Fatalf("Expected %v got %v", ErrInvalidShape, err)
+	}
+
+	if _, err := f.New("enemy", engine.Vec2{}, Def{State: "chase"}); !errors.Is(err, engine.UnknownState("chase")) {
+		t.Fatalf("Expected %v got %v", engine.UnknownState("chase"), err)
+	}
+
+	f.NewFSM = nil
+
+	if _, err := f.New("enemy", engine.Vec2{}); !errors.Is(err, ErrNoFSM) {
+		t.Fatalf("Expected %v got %v", ErrNoFSM, err)
+	}
+
+	if err := f.Validate("enemy"); err != nil {
+		t.Fatalf("Expected %v got %v", nil, err)
+	}
+
+	if err := f.Validate("blob"); !errors.Is(err, ErrInvalidShape) {
+		t.Fatalf("Expected %v got %v", ErrInvalidShape, err)
+	}
+
+	if err := f.Parse([]byte("type: tiles")); !errors.Is(err, InvalidConfigType("tiles")) {
+		t.Fatalf("Expected %v got %v", InvalidConfigType("tiles"), err)
+	}
+}
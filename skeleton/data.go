@@ -0,0 +1,178 @@
+// Package skeleton provides bone based cutout animations, in
+// which atlas images attached to a hierarchy of bones are moved
+// by keyframed translations, rotations and scales.
+package skeleton
+
+import (
+	"fmt"
+	"math"
+)
+
+// UnknownBone occurs when a bone does not exist.
+type UnknownBone string
+
+// Error implements error.
+func (u UnknownBone) Error() string {
+	return fmt.Sprintf("unknown bone: %s", string(u))
+}
+
+// UnknownSlot occurs when a slot does not exist.
+type UnknownSlot string
+
+// Error implements error.
+func (u UnknownSlot) Error() string {
+	return fmt.Sprintf("unknown slot: %s", string(u))
+}
+
+// MissingImage occurs when the image of an
+// attachment is not in the atlas of a Skeleton.
+type MissingImage string
+
+// Error implements error.
+func (m MissingImage) Error() string {
+	return fmt.Sprintf("missing attachment image: %s", string(m))
+}
+
+// Data is the bones, slots, attachments and clips
+// shared by the Skeletons created from it.
+// Positions are in pixels with y pointing down,
+// and rotations are clockwise in radians.
+type Data struct {
+	// Bones are ordered so that parents precede their children.
+	Bones []Bone
+	// Slots are in draw order.
+	Slots []Slot
+	// Attachments maps attachment names to attachments of each slot.
+	Attachments []map[string]Attachment
+	// Clips are the animation states.
+	Clips map[string]*Clip
+}
+
+// Bone returns the index of a bone, or -1 if it does not exist.
+func (d *Data) Bone(name string) int {
+	for i, b := range d.Bones {
+		if b.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Slot returns the index of a slot, or -1 if it does not exist.
+func (d *Data) Slot(name string) int {
+	for i, s := range d.Slots {
+		if s.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Bone is a bone in the setup pose, relative to its parent.
+type Bone struct {
+	Name string
+	// Parent is the index of the parent bone, or -1 for a root bone.
+	Parent int
+
+	X, Y           float64
+	Rotation       float64
+	ScaleX, ScaleY float64
+}
+
+// Slot holds the attachments drawn for a bone.
+type Slot struct {
+	Name string
+	// Bone is the index of the bone.
+	Bone int
+	// Attachment is the attachment shown in the
+	// setup pose, or empty if none is shown.
+	Attachment string
+}
+
+// Attachment is an atlas image attached to the bone of a
+// slot. The center of the image is placed at its position.
+type Attachment struct {
+	// Image is the name of the image in the atlas.
+	Image string
+
+	X, Y           float64
+	Rotation       float64
+	ScaleX, ScaleY float64
+}
+
+// Clip is a keyframed animation of the bones and slots.
+type Clip struct {
+	// Duration is the length of the clip in ticks.
+	Duration float64
+	Loop     bool
+
+	// Bones maps bone indices to their timelines.
+	Bones map[int]*BoneTimeline
+	// Attachments maps slot indices to the
+	// keyframes of their shown attachment.
+	Attachments map[int][]AttachmentKeyframe
+	// Events maps ticks to the names of the events
+	// passed to the OnFrame callbacks of a Skeleton.
+	Events map[int][]string
+}
+
+// BoneTimeline holds the keyframes of a bone, in order of time.
+// Rotations and translations are added to the setup pose,
+// and scales multiply it. Rotations use the X value of keyframes.
+type BoneTimeline struct {
+	Rotate, Translate, Scale []Keyframe
+}
+
+// Keyframe is a value of a timeline at a tick.
+type Keyframe struct {
+	Tick float64
+	X, Y float64
+	// Stepped holds the value until the next
+	// keyframe, rather than interpolating it.
+	Stepped bool
+}
+
+// AttachmentKeyframe shows an attachment from a tick.
+// An empty attachment hides the slot.
+type AttachmentKeyframe struct {
+	Tick       float64
+	Attachment string
+}
+
+// sample returns the value of a timeline at a tick, or
+// false if it has no keyframes. Angles are interpolated
+// in the direction of the shortest rotation.
+func sample(keys []Keyframe, tick float64, angle bool) (float64, float64, bool) {
+	if len(keys) == 0 {
+		return 0, 0, false
+	}
+
+	if tick <= keys[0].Tick {
+		return keys[0].X, keys[0].Y, true
+	}
+
+	for i := 0; i < len(keys)-1; i++ {
+		k, next := keys[i], keys[i+1]
+		if tick >= next.Tick {
+			continue
+		}
+
+		if k.Stepped || next.Tick <= k.Tick {
+			return k.X, k.Y, true
+		}
+
+		t := (tick - k.Tick) / (next.Tick - k.Tick)
+
+		dx := next.X - k.X
+		if angle {
+			dx = math.Remainder(dx, math.Pi*2)
+		}
+
+		return k.X + dx*t, k.Y + (next.Y-k.Y)*t, true
+	}
+
+	last := keys[len(keys)-1]
+	return last.X, last.Y, true
+}
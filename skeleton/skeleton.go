@@ -0,0 +1,428 @@
+package skeleton
+
+import (
+	"math"
+	"sort"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+// Skeleton is an engine.Animation whose states are the clips
+// of its Data. It is an engine.ImageGroup, so when it is added
+// to an entity in a Context, its attachment images are drawn
+// and it is posed after the entity each tick.
+//
+// The Skeleton is positioned at its root, rather than by an
+// origin. Attachments are drawn in slot order through their
+// z depth, starting at the z depth set on the Skeleton.
+type Skeleton struct {
+	*engine.AnimationPlayer
+
+	data *Data
+
+	// parts holds the images of each slot by attachment name
+	parts  []map[string]engine.Image
+	images []engine.Image
+
+	local []transform
+	fade  []transform
+	world []affine
+
+	pos, offset engine.Vec2
+	rotation    float64
+	sx, sy      float64
+	z           int
+	alpha       float64
+	renderable  bool
+	disposed    bool
+}
+
+// New returns an instantiated *Skeleton, with the
+// images of its attachments taken from an atlas.
+func New(data *Data, atlas engine.Atlas) (*Skeleton, error) {
+	s := &Skeleton{
+		AnimationPlayer: engine.NewAnimationPlayer(),
+		data:            data,
+		parts:           make([]map[string]engine.Image, len(data.Slots)),
+		local:           make([]transform, len(data.Bones)),
+		fade:            make([]transform, len(data.Bones)),
+		world:           make([]affine, len(data.Bones)),
+		sx:              1,
+		sy:              1,
+		alpha:           1,
+		renderable:      true,
+	}
+
+	for i := range data.Slots {
+		s.parts[i] = make(map[string]engine.Image)
+
+		if i >= len(data.Attachments) {
+			continue
+		}
+
+		names := make([]string, 0, len(data.Attachments[i]))
+		for name := range data.Attachments[i] {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			a := data.Attachments[i][name]
+
+			img := atlas.GetImage(a.Image)
+			if img == nil {
+				return nil, MissingImage(a.Image)
+			}
+
+			img.Origin(0.5, 0.5)
+
+			s.parts[i][name] = img
+			s.images = append(s.images, img)
+		}
+	}
+
+	s.pose()
+
+	return s, nil
+}
+
+// Data returns the Data of the Skeleton.
+func (s *Skeleton) Data() *Data {
+	return s.data
+}
+
+// SetState implements engine.Animation.
+func (s *Skeleton) SetState(state string) {
+	if s.State() == state {
+		return
+	}
+
+	s.SetSequence(state, s.sequence(state))
+}
+
+// Crossfade implements engine.Animation. Bone
+// transforms are blended from the previous clip.
+func (s *Skeleton) Crossfade(state string, ticks int) {
+	if s.State() == state {
+		return
+	}
+
+	s.CrossfadeSequence(state, s.sequence(state), ticks)
+}
+
+// sequence returns a sequence with a frame for each tick of a clip.
+func (s *Skeleton) sequence(state string) engine.AnimationSequence {
+	clip, ok := s.data.Clips[state]
+	if !ok {
+		return engine.AnimationSequence{}
+	}
+
+	// the last keyframe of a loop is the first
+	frames := int(math.Round(clip.Duration))
+	if !clip.Loop {
+		frames++
+	}
+
+	if frames < 1 {
+		frames = 1
+	}
+
+	return engine.AnimationSequence{
+		Frames: frames,
+		Fps:    60,
+		Loop:   clip.Loop,
+		Events: clip.Events,
+	}
+}
+
+// Parts implements engine.ImageGroup.
+func (s *Skeleton) Parts() []engine.Image {
+	return s.images
+}
+
+// Tick implements engine.ImageGroup.
+// The clip is advanced and the attachments are posed.
+func (s *Skeleton) Tick() {
+	s.AnimationPlayer.Tick()
+	s.pose()
+}
+
+// pose sets the transforms of the attachment images.
+func (s *Skeleton) pose() {
+	s.sampleClip(s.local, s.State(), float64(s.Frame()))
+
+	if state, frame, weight := s.Fade(); weight > 0 {
+		s.sampleClip(s.fade, state, float64(frame))
+
+		for i := range s.local {
+			s.local[i] = s.fade[i].lerp(s.local[i], 1-weight)
+		}
+	}
+
+	root := newAffine(transform{
+		x:        s.pos.X + s.offset.X,
+		y:        s.pos.Y + s.offset.Y,
+		rotation: s.rotation,
+		sx:       s.sx,
+		sy:       s.sy,
+	})
+
+	for i, b := range s.data.Bones {
+		parent := root
+		if b.Parent >= 0 {
+			parent = s.world[b.Parent]
+		}
+
+		s.world[i] = parent.mul(newAffine(s.local[i]))
+	}
+
+	clip := s.data.Clips[s.State()]
+	tick := float64(s.Frame())
+
+	for i, slot := range s.data.Slots {
+		shown := slot.Attachment
+		if clip != nil {
+			for _, k := range clip.Attachments[i] {
+				if k.Tick > tick {
+					break
+				}
+
+				shown = k.Attachment
+			}
+		}
+
+		for name, img := range s.parts[i] {
+			if name != shown {
+				img.SetRenderable(false)
+				continue
+			}
+
+			a := s.data.Attachments[i][name]
+			m := s.world[slot.Bone].mul(newAffine(transform{
+				x:        a.X,
+				y:        a.Y,
+				rotation: a.Rotation,
+				sx:       a.ScaleX,
+				sy:       a.ScaleY,
+			}))
+
+			rotation, sx, sy := m.decompose()
+
+			img.Translate(m.x, m.y)
+			img.Rotate(rotation)
+			img.Scale(sx, sy)
+			img.SetZDepth(s.z + i)
+			img.Alpha(s.alpha)
+			img.SetRenderable(s.renderable)
+		}
+	}
+}
+
+// sampleClip sets the local bone transforms of a clip at a tick.
+func (s *Skeleton) sampleClip(local []transform, state string, tick float64) {
+	clip := s.data.Clips[state]
+
+	for i, b := range s.data.Bones {
+		t := transform{
+			x:        b.X,
+			y:        b.Y,
+			rotation: b.Rotation,
+			sx:       b.ScaleX,
+			sy:       b.ScaleY,
+		}
+
+		if clip != nil {
+			if tl, ok := clip.Bones[i]; ok {
+				if r, _, ok := sample(tl.Rotate, tick, true); ok {
+					t.rotation += r
+				}
+
+				if x, y, ok := sample(tl.Translate, tick, false); ok {
+					t.x, t.y = t.x+x, t.y+y
+				}
+
+				if x, y, ok := sample(tl.Scale, tick, false); ok {
+					t.sx, t.sy = t.sx*x, t.sy*y
+				}
+			}
+		}
+
+		local[i] = t
+	}
+}
+
+// Translate implements engine.Image.
+func (s *Skeleton) Translate(x, y float64) {
+	s.pos = engine.Vec2{X: x, Y: y}
+}
+
+// Offset implements engine.Image.
+func (s *Skeleton) Offset(x, y float64) {
+	s.offset = engine.Vec2{X: x, Y: y}
+}
+
+// Scale implements engine.Image.
+func (s *Skeleton) Scale(x, y float64) {
+	s.sx, s.sy = x, y
+}
+
+// Rotate implements engine.Image.
+func (s *Skeleton) Rotate(d float64) {
+	s.rotation = d
+}
+
+// Origin implements engine.Image.
+// It has no effect, as the Skeleton is positioned at its root.
+func (s *Skeleton) Origin(x, y float64) {}
+
+// SetZDepth implements engine.Image.
+func (s *Skeleton) SetZDepth(z int) {
+	s.z = z
+}
+
+// Tint implements engine.Image.
+func (s *Skeleton) Tint(r, g, b float64) {
+	for _, img := range s.images {
+		img.Tint(r, g, b)
+	}
+}
+
+// Alpha implements engine.Image.
+func (s *Skeleton) Alpha(alpha float64) {
+	s.alpha = alpha
+}
+
+// SetRenderable implements engine.Image.
+func (s *Skeleton) SetRenderable(r bool) {
+	s.renderable = r
+}
+
+// IsRenderable implements engine.Image.
+func (s *Skeleton) IsRenderable() bool {
+	return s.renderable
+}
+
+// RoundTranslations implements engine.Image.
+func (s *Skeleton) RoundTranslations(round bool) {
+	for _, img := range s.images {
+		img.RoundTranslations(round)
+	}
+}
+
+// TriggersTileOverlapEvent implements engine.Image.
+func (s *Skeleton) TriggersTileOverlapEvent(triggers bool) {
+	for _, img := range s.images {
+		img.TriggersTileOverlapEvent(triggers)
+	}
+}
+
+// Size implements engine.Image. It is the size of the
+// bounds of the attachment positions in the current pose.
+func (s *Skeleton) Size() (int, int) {
+	min := engine.Vec2{X: math.Inf(1), Y: math.Inf(1)}
+	max := engine.Vec2{X: math.Inf(-1), Y: math.Inf(-1)}
+
+	for _, img := range s.images {
+		if !img.IsRenderable() {
+			continue
+		}
+
+		p := img.Position()
+		min = engine.Vec2{X: math.Min(min.X, p.X), Y: math.Min(min.Y, p.Y)}
+		max = engine.Vec2{X: math.Max(max.X, p.X), Y: math.Max(max.Y, p.Y)}
+	}
+
+	if min.X > max.X {
+		return 0, 0
+	}
+
+	return int(max.X - min.X), int(max.Y - min.Y)
+}
+
+// Dispose implements engine.Image.
+func (s *Skeleton) Dispose() {
+	s.disposed = true
+
+	for _, img := range s.images {
+		img.Dispose()
+	}
+}
+
+// IsDisposed implements engine.Image.
+func (s *Skeleton) IsDisposed() bool {
+	return s.disposed
+}
+
+// Position implements engine.Image.
+func (s *Skeleton) Position() engine.Vec2 {
+	return s.pos
+}
+
+// Class implements engine.Image.
+func (s *Skeleton) Class() string {
+	return "skeleton"
+}
+
+// transform is a decomposed bone transform.
+type transform struct {
+	x, y     float64
+	rotation float64
+	sx, sy   float64
+}
+
+// lerp interpolates between two transforms.
+func (t transform) lerp(u transform, f float64) transform {
+	return transform{
+		x:        t.x + (u.x-t.x)*f,
+		y:        t.y + (u.y-t.y)*f,
+		rotation: t.rotation + math.Remainder(u.rotation-t.rotation, math.Pi*2)*f,
+		sx:       t.sx + (u.sx-t.sx)*f,
+		sy:       t.sy + (u.sy-t.sy)*f,
+	}
+}
+
+// affine is a 2D affine transform, mapping
+// (u, v) to (a*u + c*v + x, b*u + d*v + y).
+type affine struct {
+	a, b, c, d float64
+	x, y       float64
+}
+
+// newAffine returns the affine transform which scales,
+// then rotates, then translates.
+func newAffine(t transform) affine {
+	sin, cos := math.Sincos(t.rotation)
+
+	return affine{
+		a: cos * t.sx,
+		b: sin * t.sx,
+		c: -sin * t.sy,
+		d: cos * t.sy,
+		x: t.x,
+		y: t.y,
+	}
+}
+
+// mul returns the transform which applies n, then m.
+func (m affine) mul(n affine) affine {
+	return affine{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		x: m.a*n.x + m.c*n.y + m.x,
+		y: m.b*n.x + m.d*n.y + m.y,
+	}
+}
+
+// decompose returns the rotation and scale of the
+// transform, ignoring any shear from non-uniform scales.
+func (m affine) decompose() (rotation, sx, sy float64) {
+	sx = math.Hypot(m.a, m.b)
+	if sx == 0 {
+		return 0, 0, 0
+	}
+
+	return math.Atan2(m.b, m.a), sx, (m.a*m.d - m.b*m.c) / sx
+}
@@ -0,0 +1,180 @@
+package skeleton
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/split-cube-studios/ardent/engine"
+)
+
+const testSpine = `{
+	"bones": [
+		{"name": "root"},
+		{"name": "arm", "parent": "root", "x": 10}
+	],
+	"slots": [
+		{"name": "hand", "bone": "arm", "attachment": "open"}
+	],
+	"skins": [{
+		"name": "default",
+		"attachments": {
+			"hand": {
+				"open": {"x": 5, "width": 4, "height": 4},
+				"fist": {"x": 5, "path": "hand_fist", "width": 4, "height": 4}
+			}
+		}
+	}],
+	"animations": {
+		"wave": {
+			"bones": {
+				"arm": {"rotate": [{"time": 0, "angle": 0}, {"time": 1, "angle": 90}]}
+			},
+			"slots": {
+				"hand": {"attachment": [{"time": 0.5, "name": "fist"}]}
+			},
+			"events": [{"time": 0.5, "name": "clench"}]
+		}
+	}
+}`
+
+type testImage struct {
+	pos        engine.Vec2
+	rotation   float64
+	sx, sy     float64
+	renderable bool
+}
+
+func (i *testImage) Translate(x, y float64)         { i.pos = engine.Vec2{X: x, Y: y} }
+func (i *testImage) Offset(float64, float64)        {}
+func (i *testImage) Scale(x, y float64)             { i.sx, i.sy = x, y }
+func (i *testImage) Rotate(d float64)               { i.rotation = d }
+func (i *testImage) Origin(float64, float64)        {}
+func (i *testImage) SetZDepth(int)                  {}
+func (i *testImage) Tint(float64, float64, float64) {}
+func (i *testImage) Alpha(float64)                  {}
+func (i *testImage) SetRenderable(r bool)           { i.renderable = r }
+func (i *testImage) IsRenderable() bool             { return i.renderable }
+func (i *testImage) RoundTranslations(bool)         {}
+func (i *testImage) TriggersTileOverlapEvent(bool)  {}
+func (i *testImage) Size() (int, int)               { return 4, 4 }
+func (i *testImage) Dispose()                       {}
+func (i *testImage) IsDisposed() bool               { return false }
+func (i *testImage) Position() engine.Vec2          { return i.pos }
+func (i *testImage) Class() string                  { return "image" }
+
+type testAtlas map[string]*testImage
+
+func (a testAtlas) GetImage(name string) engine.Image {
+	img, ok := a[name]
+	if !ok {
+		return nil
+	}
+
+	return img
+}
+
+type testRenderer struct {
+	images []engine.Image
+}
+
+func (r *testRenderer) AddImage(images ...engine.Image)         { r.images = append(r.images, images...) }
+func (r *testRenderer) RemoveImage(...engine.Image)             {}
+func (r *testRenderer) SetCamera(*engine.Camera)                {}
+func (r *testRenderer) ScreenToWorld(v engine.Vec2) engine.Vec2 { return v }
+func (r *testRenderer) SetViewport(int, int)                    {}
+func (r *testRenderer) Viewport() image.Rectangle               { return image.Rect(0, 0, 200, 200) }
+func (r *testRenderer) Tick()                                   {}
+
+type testEntity struct {
+	engine.CoreEntity
+}
+
+func (e *testEntity) Class() string {
+	return "boss"
+}
+
+func near(a, b engine.Vec2) bool {
+	return a.Distance(b) < 1e-6
+}
+
+func TestSkeleton(t *testing.T) {
+	data, err := ParseSpine([]byte(testSpine))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	atlas := testAtlas{"open": new(testImage), "hand_fist": new(testImage)}
+	open, fist := atlas["open"], atlas["hand_fist"]
+
+	s, err := New(data, atlas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := new(testRenderer)
+	ctx := engine.NewContext(r, nil, nil)
+
+	e := &testEntity{engine.CoreEntity{Vec2: engine.Vec2{X: 100, Y: 100}}}
+	e.AddImage(s)
+	ctx.AddEntity(e)
+
+	var events []string
+	s.OnFrame(func(f engine.AnimationFrame) {
+		events = append(events, f.Events...)
+	})
+	s.SetState("wave")
+
+	ctx.Tick()
+
+	if len(r.images) != 2 {
+		t.Fatalf("Expected %v got %v", 2, len(r.images))
+	}
+
+	// the clip has advanced by a tick
+	want := engine.Vec2{X: 110, Y: 100}.Translate(-math.Pi/2/60, 5)
+	if !open.renderable || fist.renderable || !near(open.pos, want) {
+		t.Fatalf("Expected %v got %v", want, open.pos)
+	}
+
+	for i := 0; i < 29; i++ {
+		ctx.Tick()
+	}
+
+	// halfway, the arm has rotated 45 degrees counterclockwise
+	want = engine.Vec2{X: 110, Y: 100}.Translate(-math.Pi/4, 5)
+	if open.renderable || !fist.renderable || !near(fist.pos, want) {
+		t.Fatalf("Expected %v got %v", want, fist.pos)
+	}
+
+	if math.Abs(fist.rotation+math.Pi/4) > 1e-6 {
+		t.Fatalf("Expected %v got %v", -math.Pi/4, fist.rotation)
+	}
+
+	if len(events) != 1 || events[0] != "clench" {
+		t.Fatalf("Expected %v got %v", []string{"clench"}, events)
+	}
+
+	// mirrored
+	s.Scale(-1, 1)
+	ctx.Tick()
+
+	if fist.pos.X >= 90 || math.Abs(fist.sx-1) > 1e-6 || math.Abs(fist.sy+1) > 1e-6 {
+		t.Fatalf("Expected mirrored pose got %v with scale %v, %v", fist.pos, fist.sx, fist.sy)
+	}
+}
+
+func TestSpineErrors(t *testing.T) {
+	if _, err := ParseSpine([]byte(`{"bones": [{"name": "arm", "parent": "body"}]}`)); err != UnknownBone("body") {
+		t.Fatalf("Expected %v got %v", UnknownBone("body"), err)
+	}
+
+	data, err := ParseSpine([]byte(testSpine))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(data, testAtlas{"open": new(testImage)}); err != MissingImage("hand_fist") {
+		t.Fatalf("Expected %v got %v", MissingImage("hand_fist"), err)
+	}
+}
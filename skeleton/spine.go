@@ -0,0 +1,305 @@
+package skeleton
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+)
+
+// spineSkin is the name of the skin imported from Spine.
+const spineSkin = "default"
+
+type spineData struct {
+	Bones []struct {
+		Name     string   `json:"name"`
+		Parent   string   `json:"parent"`
+		X        float64  `json:"x"`
+		Y        float64  `json:"y"`
+		Rotation float64  `json:"rotation"`
+		ScaleX   *float64 `json:"scaleX"`
+		ScaleY   *float64 `json:"scaleY"`
+	} `json:"bones"`
+
+	Slots []struct {
+		Name       string `json:"name"`
+		Bone       string `json:"bone"`
+		Attachment string `json:"attachment"`
+	} `json:"slots"`
+
+	// Skins is an array of skins since Spine 3.8,
+	// and an object of skins by name before.
+	Skins json.RawMessage `json:"skins"`
+
+	Animations map[string]struct {
+		Bones map[string]struct {
+			Rotate    []spineKeyframe `json:"rotate"`
+			Translate []spineKeyframe `json:"translate"`
+			Scale     []spineKeyframe `json:"scale"`
+		} `json:"bones"`
+
+		Slots map[string]struct {
+			Attachment []struct {
+				Time float64 `json:"time"`
+				Name *string `json:"name"`
+			} `json:"attachment"`
+		} `json:"slots"`
+
+		Events []struct {
+			Time float64 `json:"time"`
+			Name string  `json:"name"`
+		} `json:"events"`
+	} `json:"animations"`
+}
+
+// spineSkinAttachments maps slot names to attachment names to attachments.
+type spineSkinAttachments map[string]map[string]struct {
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	X        float64  `json:"x"`
+	Y        float64  `json:"y"`
+	Rotation float64  `json:"rotation"`
+	ScaleX   *float64 `json:"scaleX"`
+	ScaleY   *float64 `json:"scaleY"`
+}
+
+type spineKeyframe struct {
+	Time float64 `json:"time"`
+	// Angle is the rotation before Spine 4.0, and Value after.
+	Angle *float64        `json:"angle"`
+	Value *float64        `json:"value"`
+	X     *float64        `json:"x"`
+	Y     *float64        `json:"y"`
+	Curve json.RawMessage `json:"curve"`
+}
+
+// LoadSpine loads Data from a Spine JSON file.
+func LoadSpine(path string) (*Data, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := ParseSpine(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return d, nil
+}
+
+// ParseSpine parses Data from Spine JSON. Bones, slots, the region
+// attachments of the default skin, and the rotate, translate, scale,
+// attachment and event timelines of animations are imported. Bezier
+// curves are interpolated linearly. Positions are flipped vertically
+// and rotations converted to clockwise radians. Every clip loops.
+func ParseSpine(data []byte) (*Data, error) {
+	var sd spineData
+	if err := json.Unmarshal(data, &sd); err != nil {
+		return nil, err
+	}
+
+	d := &Data{
+		Clips: make(map[string]*Clip),
+	}
+
+	for i, b := range sd.Bones {
+		parent := -1
+		if b.Parent != "" {
+			if parent = d.Bone(b.Parent); parent < 0 || parent >= i {
+				return nil, UnknownBone(b.Parent)
+			}
+		}
+
+		d.Bones = append(d.Bones, Bone{
+			Name:     b.Name,
+			Parent:   parent,
+			X:        b.X,
+			Y:        -b.Y,
+			Rotation: spineAngle(b.Rotation),
+			ScaleX:   valueOr(b.ScaleX, 1),
+			ScaleY:   valueOr(b.ScaleY, 1),
+		})
+	}
+
+	for _, s := range sd.Slots {
+		bone := d.Bone(s.Bone)
+		if bone < 0 {
+			return nil, UnknownBone(s.Bone)
+		}
+
+		d.Slots = append(d.Slots, Slot{
+			Name:       s.Name,
+			Bone:       bone,
+			Attachment: s.Attachment,
+		})
+	}
+
+	skin, err := parseSpineSkin(sd.Skins)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Attachments = make([]map[string]Attachment, len(d.Slots))
+	for i := range d.Attachments {
+		d.Attachments[i] = make(map[string]Attachment)
+	}
+
+	for slotName, attachments := range skin {
+		slot := d.Slot(slotName)
+		if slot < 0 {
+			return nil, UnknownSlot(slotName)
+		}
+
+		for name, a := range attachments {
+			if a.Type != "" && a.Type != "region" {
+				continue
+			}
+
+			image := name
+			switch {
+			case a.Path != "":
+				image = a.Path
+			case a.Name != "":
+				image = a.Name
+			}
+
+			d.Attachments[slot][name] = Attachment{
+				Image:    image,
+				X:        a.X,
+				Y:        -a.Y,
+				Rotation: spineAngle(a.Rotation),
+				ScaleX:   valueOr(a.ScaleX, 1),
+				ScaleY:   valueOr(a.ScaleY, 1),
+			}
+		}
+	}
+
+	for name, a := range sd.Animations {
+		clip := &Clip{
+			Loop:        true,
+			Bones:       make(map[int]*BoneTimeline),
+			Attachments: make(map[int][]AttachmentKeyframe),
+		}
+
+		for boneName, timelines := range a.Bones {
+			bone := d.Bone(boneName)
+			if bone < 0 {
+				return nil, fmt.Errorf("animation %s: %w", name, UnknownBone(boneName))
+			}
+
+			tl := &BoneTimeline{}
+			for _, k := range timelines.Rotate {
+				angle := valueOr(k.Value, valueOr(k.Angle, 0))
+				tl.Rotate = append(tl.Rotate, k.keyframe(spineAngle(angle), 0))
+			}
+
+			for _, k := range timelines.Translate {
+				tl.Translate = append(tl.Translate, k.keyframe(valueOr(k.X, 0), -valueOr(k.Y, 0)))
+			}
+
+			for _, k := range timelines.Scale {
+				tl.Scale = append(tl.Scale, k.keyframe(valueOr(k.X, 1), valueOr(k.Y, 1)))
+			}
+
+			for _, keys := range [][]Keyframe{tl.Rotate, tl.Translate, tl.Scale} {
+				if len(keys) > 0 {
+					clip.Duration = math.Max(clip.Duration, keys[len(keys)-1].Tick)
+				}
+			}
+
+			clip.Bones[bone] = tl
+		}
+
+		for slotName, timelines := range a.Slots {
+			slot := d.Slot(slotName)
+			if slot < 0 {
+				return nil, fmt.Errorf("animation %s: %w", name, UnknownSlot(slotName))
+			}
+
+			for _, k := range timelines.Attachment {
+				var attachment string
+				if k.Name != nil {
+					attachment = *k.Name
+				}
+
+				tick := k.Time * 60
+				clip.Attachments[slot] = append(clip.Attachments[slot], AttachmentKeyframe{
+					Tick:       tick,
+					Attachment: attachment,
+				})
+				clip.Duration = math.Max(clip.Duration, tick)
+			}
+		}
+
+		if len(a.Events) > 0 {
+			clip.Events = make(map[int][]string)
+		}
+
+		for _, e := range a.Events {
+			tick := int(math.Round(e.Time * 60))
+			clip.Events[tick] = append(clip.Events[tick], e.Name)
+			clip.Duration = math.Max(clip.Duration, float64(tick))
+		}
+
+		d.Clips[name] = clip
+	}
+
+	return d, nil
+}
+
+// parseSpineSkin returns the attachments of the default skin.
+func parseSpineSkin(data json.RawMessage) (spineSkinAttachments, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var skins []struct {
+		Name        string               `json:"name"`
+		Attachments spineSkinAttachments `json:"attachments"`
+	}
+
+	if err := json.Unmarshal(data, &skins); err == nil {
+		for _, s := range skins {
+			if s.Name == spineSkin {
+				return s.Attachments, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	var named map[string]spineSkinAttachments
+	if err := json.Unmarshal(data, &named); err != nil {
+		return nil, err
+	}
+
+	return named[spineSkin], nil
+}
+
+// keyframe returns the Keyframe of a Spine keyframe.
+func (k spineKeyframe) keyframe(x, y float64) Keyframe {
+	var curve string
+	_ = json.Unmarshal(k.Curve, &curve)
+
+	return Keyframe{
+		Tick:    k.Time * 60,
+		X:       x,
+		Y:       y,
+		Stepped: curve == "stepped",
+	}
+}
+
+// spineAngle converts counterclockwise degrees to clockwise radians.
+func spineAngle(degrees float64) float64 {
+	return -degrees * math.Pi / 180
+}
+
+func valueOr(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+
+	return *v
+}